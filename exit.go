@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/errors"
+	"peerless/pkg/types"
+
+	"github.com/urfave/cli/v3"
+)
+
+// validationError marks an error in the flags, configuration or directories
+// given to a command, which exits with constants.ExitValidation
+type validationError struct {
+	err error
+}
+
+func (e validationError) Error() string {
+	return e.err.Error()
+}
+
+func (e validationError) Unwrap() error {
+	return e.err
+}
+
+// invalid marks err as a validation error
+func invalid(err error) error {
+	return validationError{err: err}
+}
+
+// invalidf formats a validation error like fmt.Errorf
+func invalidf(format string, args ...any) error {
+	return invalid(fmt.Errorf(format, args...))
+}
+
+// exitCode returns the exit status for an error returned by a command
+func exitCode(err error) int {
+	var exitCoder cli.ExitCoder
+	var validation validationError
+	var configErrs types.ValidationErrors
+	switch {
+	case stderrors.As(err, &exitCoder):
+		return exitCoder.ExitCode()
+	case errors.IsConnectionError(err), errors.IsAuthenticationError(err):
+		return constants.ExitConnection
+	case stderrors.As(err, &validation), stderrors.As(err, &configErrs):
+		return constants.ExitValidation
+	default:
+		return constants.ExitError
+	}
+}
+
+// setOnUsageError makes errors parsing the arguments of cmd and all its
+// subcommands exit as validation errors, after the usual usage message
+func setOnUsageError(cmd *cli.Command) {
+	cmd.OnUsageError = func(ctx context.Context, cmd *cli.Command, err error, isSubcommand bool) error {
+		fmt.Fprintf(cmd.Root().ErrWriter, "Incorrect Usage: %s\n\n", err)
+		_ = cli.ShowSubcommandHelp(cmd)
+		return invalid(err)
+	}
+	for _, sub := range cmd.Commands {
+		setOnUsageError(sub)
+	}
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// CachingClient answers torrent list requests from an on-disk cache younger
+// than its TTL, so invocations in quick succession share one fetch. Actions
+// that change torrents drop the cache. The cache is best effort: a cache that
+// cannot be read or written only means the daemon is asked.
+type CachingClient struct {
+	Client
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// torrentCache is the content of a cache file
+type torrentCache struct {
+	Time     time.Time           `json:"time"`
+	Torrents []types.TorrentInfo `json:"torrents"`
+}
+
+// NewCachingClient wraps inner with a cache in dir, keyed by the host and port of config
+func NewCachingClient(inner Client, dir string, config types.Config, ttl time.Duration) *CachingClient {
+	name := strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, config.Host)
+	return &CachingClient{
+		Client: inner,
+		path:   filepath.Join(dir, fmt.Sprintf("torrents-%s-%d.json", name, config.Port)),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// GetTorrents returns the cached torrents when fresh, fetching and caching them otherwise
+func (c *CachingClient) GetTorrents(ctx context.Context) ([]types.TorrentInfo, error) {
+	if torrents, ok := c.load(); ok {
+		return torrents, nil
+	}
+	torrents, err := c.Client.GetTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.save(torrents)
+	return torrents, nil
+}
+
+// GetTorrentsWithOptions serves fields GetTorrents covers from the cache,
+// asking the daemon for others such as files
+func (c *CachingClient) GetTorrentsWithOptions(ctx context.Context, opts TorrentFetchOptions) ([]types.TorrentInfo, error) {
+	if !coveredFields(opts.Fields) {
+		return c.Client.GetTorrentsWithOptions(ctx, opts)
+	}
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil || len(opts.IDs) == 0 {
+		return torrents, err
+	}
+
+	wanted := make(map[int]bool, len(opts.IDs))
+	for _, id := range opts.IDs {
+		wanted[id] = true
+	}
+	selected := make([]types.TorrentInfo, 0, len(opts.IDs))
+	for _, t := range torrents {
+		if wanted[t.ID] {
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
+
+// GetAllTorrentPaths returns the sorted paths of the cached torrents
+func (c *CachingClient) GetAllTorrentPaths(ctx context.Context) ([]string, error) {
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return torrentPaths(torrents), nil
+}
+
+// GetDownloadDirectories returns the download directories of the cached torrents
+func (c *CachingClient) GetDownloadDirectories(ctx context.Context) ([]utils.DirectoryInfo, error) {
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return downloadDirectories(torrents), nil
+}
+
+// StartTorrents starts torrents and drops the cache
+func (c *CachingClient) StartTorrents(ctx context.Context, ids []int) error {
+	defer c.Invalidate()
+	return c.Client.StartTorrents(ctx, ids)
+}
+
+// StopTorrents stops torrents and drops the cache
+func (c *CachingClient) StopTorrents(ctx context.Context, ids []int) error {
+	defer c.Invalidate()
+	return c.Client.StopTorrents(ctx, ids)
+}
+
+// VerifyTorrents starts verification and drops the cache
+func (c *CachingClient) VerifyTorrents(ctx context.Context, ids []int) error {
+	defer c.Invalidate()
+	return c.Client.VerifyTorrents(ctx, ids)
+}
+
+// SetTorrentLocation moves torrents and drops the cache
+func (c *CachingClient) SetTorrentLocation(ctx context.Context, ids []int, location string, move bool) error {
+	defer c.Invalidate()
+	return c.Client.SetTorrentLocation(ctx, ids, location, move)
+}
+
+// SetTorrentLabels labels torrents and drops the cache
+func (c *CachingClient) SetTorrentLabels(ctx context.Context, ids []int, labels []string) error {
+	defer c.Invalidate()
+	return c.Client.SetTorrentLabels(ctx, ids, labels)
+}
+
+// RemoveTorrents removes torrents and drops the cache
+func (c *CachingClient) RemoveTorrents(ctx context.Context, ids []int, deleteData bool) error {
+	defer c.Invalidate()
+	return c.Client.RemoveTorrents(ctx, ids, deleteData)
+}
+
+// Invalidate drops the cache, so the next request asks the daemon
+func (c *CachingClient) Invalidate() {
+	_ = os.Remove(c.path)
+}
+
+// load returns the cached torrents when the cache is younger than the TTL
+func (c *CachingClient) load() ([]types.TorrentInfo, bool) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, false
+	}
+	var cache torrentCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Torrents == nil {
+		return nil, false
+	}
+	age := c.now().Sub(cache.Time)
+	if age < 0 || age >= c.ttl {
+		return nil, false
+	}
+	return cache.Torrents, true
+}
+
+// save writes the torrents to the cache, readable only by the user
+func (c *CachingClient) save(torrents []types.TorrentInfo) {
+	data, err := json.Marshal(torrentCache{Time: c.now(), Torrents: torrents})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// coveredFields reports whether GetTorrents returns all of fields
+func coveredFields(fields []string) bool {
+	for _, f := range fields {
+		covered := false
+		for _, have := range TorrentFields {
+			if f == have {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/types"
+)
+
+// countingClient answers GetTorrents with fixed torrents and counts the calls
+type countingClient struct {
+	Client
+	fetches  int
+	torrents []types.TorrentInfo
+}
+
+func (c *countingClient) GetTorrents(ctx context.Context) ([]types.TorrentInfo, error) {
+	c.fetches++
+	return c.torrents, nil
+}
+
+func (c *countingClient) GetTorrentsWithOptions(ctx context.Context, opts TorrentFetchOptions) ([]types.TorrentInfo, error) {
+	c.fetches++
+	return c.torrents, nil
+}
+
+func (c *countingClient) StopTorrents(ctx context.Context, ids []int) error {
+	return nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{torrents: []types.TorrentInfo{
+		{ID: 1, Name: "First", DownloadDir: "/downloads/movies", TotalSize: 100},
+		{ID: 2, Name: "Second", DownloadDir: "/downloads/tv", TotalSize: 200},
+	}}
+	dir := t.TempDir()
+	config := types.Config{Host: "seedbox.example", Port: 9091}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newClient := func() *CachingClient {
+		c := NewCachingClient(inner, dir, config, 5*time.Minute)
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	first, err := newClient().GetTorrents(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.fetches)
+
+	t.Run("a later run within the TTL reuses the list", func(t *testing.T) {
+		now = now.Add(4 * time.Minute)
+		c := newClient()
+
+		cached, err := c.GetTorrents(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, first, cached)
+
+		dirs, err := c.GetDownloadDirectories(ctx)
+		require.NoError(t, err)
+		assert.Len(t, dirs, 2)
+
+		selected, err := c.GetTorrentsWithOptions(ctx, TorrentFetchOptions{IDs: []int{2}, Fields: []string{"name"}})
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "Second", selected[0].Name)
+		assert.Equal(t, 1, inner.fetches)
+	})
+
+	t.Run("fields outside the list are fetched", func(t *testing.T) {
+		_, err := newClient().GetTorrentsWithOptions(ctx, TorrentFetchOptions{IDs: []int{1}, Fields: []string{"files"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, inner.fetches)
+	})
+
+	t.Run("an expired cache is refreshed", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		_, err := newClient().GetTorrents(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, inner.fetches)
+	})
+
+	t.Run("actions drop the cache", func(t *testing.T) {
+		c := newClient()
+		require.NoError(t, c.StopTorrents(ctx, []int{1}))
+		_, err := os.Stat(c.path)
+		assert.True(t, os.IsNotExist(err))
+
+		_, err = c.GetTorrents(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 4, inner.fetches)
+	})
+
+	t.Run("keyed by host and port", func(t *testing.T) {
+		other := NewCachingClient(inner, dir, types.Config{Host: "seedbox.example", Port: 9092}, time.Hour)
+		assert.NotEqual(t, newClient().path, other.path)
+	})
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// Client is a torrent client backend. Torrent IDs are those reported by
+// GetTorrents; backends without numeric IDs assign them per client instance.
+type Client interface {
+	GetTorrents(ctx context.Context) ([]types.TorrentInfo, error)
+	GetTorrentsWithOptions(ctx context.Context, opts TorrentFetchOptions) ([]types.TorrentInfo, error)
+	GetTorrentFiles(ctx context.Context, ids []int) (map[int][]types.TorrentFile, error)
+	GetAllTorrentPaths(ctx context.Context) ([]string, error)
+	GetDownloadDirectories(ctx context.Context) ([]utils.DirectoryInfo, error)
+	GetSessionInfo(ctx context.Context) (*types.SessionInfo, error)
+	GetSessionStats(ctx context.Context) (*types.SessionStats, *types.SessionStats, error)
+	GetFreeSpace(ctx context.Context, path string) (int64, error)
+
+	StartTorrents(ctx context.Context, ids []int) error
+	StopTorrents(ctx context.Context, ids []int) error
+	VerifyTorrents(ctx context.Context, ids []int) error
+	SetTorrentLocation(ctx context.Context, ids []int, location string, move bool) error
+	SetTorrentLabels(ctx context.Context, ids []int, labels []string) error
+	RemoveTorrents(ctx context.Context, ids []int, deleteData bool) error
+}
+
+// TorrentFetchOptions narrows what GetTorrentsWithOptions retrieves
+type TorrentFetchOptions struct {
+	// IDs restricts the result to these torrents; all torrents when empty
+	IDs []int
+
+	// Fields are the torrent-get fields to request, TorrentFields when empty.
+	// The id field is always included. qBittorrent always returns every field.
+	Fields []string
+}
+
+// TorrentFields are the torrent-get fields GetTorrents requests
+var TorrentFields = []string{
+	"id", "name", "downloadDir", "hashString",
+	"totalSize", "sizeWhenDone", "leftUntilDone",
+	"rateDownload", "rateUpload", "percentDone",
+	"status", "addedDate", "doneDate",
+	"uploadedEver", "downloadedEver", "uploadRatio",
+	"labels", "recheckProgress", "trackers", "secondsSeeding",
+	"isPrivate",
+}
+
+// fields returns the fields to request, always including the ID
+func (o TorrentFetchOptions) fields() []string {
+	if len(o.Fields) == 0 {
+		return TorrentFields
+	}
+	for _, f := range o.Fields {
+		if f == "id" {
+			return o.Fields
+		}
+	}
+	return append([]string{"id"}, o.Fields...)
+}
+
+// chunks splits ids into slices of at most size IDs
+func chunks(ids []int, size int) [][]int {
+	var out [][]int
+	for len(ids) > size {
+		out = append(out, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		out = append(out, ids)
+	}
+	return out
+}
+
+var (
+	_ Client = (*TransmissionClient)(nil)
+	_ Client = (*QBittorrentClient)(nil)
+	_ Client = (*CachingClient)(nil)
+)
+
+// New creates the client for the backend selected by config.Client
+func New(config types.Config, httpClient HTTPClient) (Client, error) {
+	switch config.Client {
+	case "", constants.ClientTransmission:
+		return NewTransmissionClientWithHTTPClient(config, httpClient), nil
+	case constants.ClientQBittorrent:
+		return NewQBittorrentClient(config, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported client %q", config.Client)
+	}
+}
+
+// torrentPaths returns the sorted absolute paths of torrents
+func torrentPaths(torrents []types.TorrentInfo) []string {
+	paths := make([]string, 0, len(torrents))
+	for _, torrent := range torrents {
+		absPath := filepath.Join(torrent.DownloadDir, torrent.Name)
+		cleanPath := utils.SanitizeString(absPath)
+		paths = append(paths, cleanPath)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// downloadDirectories groups torrents by download directory, sorted by path
+func downloadDirectories(torrents []types.TorrentInfo) []utils.DirectoryInfo {
+	dirMap := make(map[string]*utils.DirectoryInfo)
+	for _, t := range torrents {
+		info, ok := dirMap[t.DownloadDir]
+		if !ok {
+			info = &utils.DirectoryInfo{Path: utils.SanitizeString(t.DownloadDir)}
+			dirMap[t.DownloadDir] = info
+		}
+		info.Count++
+		info.TotalSize += t.TotalSize
+	}
+
+	dirs := make([]utils.DirectoryInfo, 0, len(dirMap))
+	for _, info := range dirMap {
+		dirs = append(dirs, *info)
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].Path < dirs[j].Path
+	})
+
+	return dirs
+}
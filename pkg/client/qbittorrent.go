@@ -0,0 +1,548 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"peerless/pkg/errors"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// QBittorrentClient talks to the qBittorrent Web API (v2). qBittorrent
+// identifies torrents by hash only, so GetTorrents numbers them and later
+// calls translate those IDs back to hashes.
+type QBittorrentClient struct {
+	config     types.Config
+	httpClient HTTPClient
+
+	mu     sync.Mutex
+	cookie *http.Cookie
+	hashes map[int]string
+}
+
+// NewQBittorrentClient creates a client for the qBittorrent Web UI described by config
+func NewQBittorrentClient(config types.Config, httpClient HTTPClient) *QBittorrentClient {
+	utils.RegisterSecret(config.Password)
+	utils.RegisterBasicAuth(config.ProxyUser, config.ProxyPassword)
+	return &QBittorrentClient{
+		config:     config,
+		httpClient: httpClient,
+		hashes:     make(map[int]string),
+	}
+}
+
+// qbTorrent is a torrent as returned by /api/v2/torrents/info
+type qbTorrent struct {
+	Hash         string  `json:"hash"`
+	Name         string  `json:"name"`
+	SavePath     string  `json:"save_path"`
+	TotalSize    int64   `json:"total_size"`
+	Size         int64   `json:"size"`
+	AmountLeft   int64   `json:"amount_left"`
+	DLSpeed      int     `json:"dlspeed"`
+	UPSpeed      int     `json:"upspeed"`
+	Progress     float64 `json:"progress"`
+	State        string  `json:"state"`
+	AddedOn      int64   `json:"added_on"`
+	CompletionOn int64   `json:"completion_on"`
+	SeedingTime  int64   `json:"seeding_time"`
+	Uploaded     int64   `json:"uploaded"`
+	Downloaded   int64   `json:"downloaded"`
+	Ratio        float64 `json:"ratio"`
+	Tags         string  `json:"tags"`
+	Tracker      string  `json:"tracker"`
+
+	// Private is only reported by qBittorrent 5.0 and later
+	Private bool `json:"private"`
+
+	// Availability is the number of distributed copies in the swarm, -1 when unknown
+	Availability float64 `json:"availability"`
+	NumSeeds     int     `json:"num_seeds"`
+	NumLeechs    int     `json:"num_leechs"`
+}
+
+// qbFile is a file as returned by /api/v2/torrents/files
+type qbFile struct {
+	Name     string  `json:"name"`
+	Size     int64   `json:"size"`
+	Progress float64 `json:"progress"`
+}
+
+// qbPreferences holds the /api/v2/app/preferences fields peerless reports
+type qbPreferences struct {
+	SavePath           string  `json:"save_path"`
+	TempPath           string  `json:"temp_path"`
+	TempPathEnabled    bool    `json:"temp_path_enabled"`
+	ListenPort         int     `json:"listen_port"`
+	MaxRatio           float64 `json:"max_ratio"`
+	MaxRatioEnabled    bool    `json:"max_ratio_enabled"`
+	AltDLLimit         int     `json:"alt_dl_limit"`
+	AltUPLimit         int     `json:"alt_up_limit"`
+	IncompleteFilesExt bool    `json:"incomplete_files_ext"`
+}
+
+// qbServerState is the server_state part of /api/v2/sync/maindata
+type qbServerState struct {
+	AllTimeDL         int64 `json:"alltime_dl"`
+	AllTimeUL         int64 `json:"alltime_ul"`
+	DLInfoData        int64 `json:"dl_info_data"`
+	UPInfoData        int64 `json:"up_info_data"`
+	DLInfoSpeed       int64 `json:"dl_info_speed"`
+	UPInfoSpeed       int64 `json:"up_info_speed"`
+	FreeSpaceOnDisk   int64 `json:"free_space_on_disk"`
+	UseAltSpeedLimits bool  `json:"use_alt_speed_limits"`
+}
+
+// baseURL returns the Web UI root, including any path prefix from a server URL
+func (c *QBittorrentClient) baseURL() string {
+	scheme := "http"
+	if c.config.UseTLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port)), strings.TrimRight(c.config.RPCPath, "/"))
+}
+
+// login authenticates and stores the session cookie
+func (c *QBittorrentClient) login(ctx context.Context) (*http.Cookie, error) {
+	form := url.Values{"username": {c.config.User}, "password": {c.config.Password}}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL()+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// The Web UI rejects requests whose Referer does not match its own origin
+	req.Header.Set("Referer", c.baseURL())
+	setProxyAuth(req, c.config)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			return cookie, nil
+		}
+	}
+	switch strings.TrimSpace(string(body)) {
+	case "Fails.":
+		return nil, errors.NewTransmissionError(http.StatusUnauthorized, c.config.Host, c.config.Port, nil)
+	case "Ok.":
+		// Authentication is bypassed for this client, so no session is needed
+		return &http.Cookie{Name: "SID"}, nil
+	}
+	return nil, errors.NewProtocolError(c.config.Host, c.config.Port, "no session cookie received from qBittorrent", nil)
+}
+
+// session returns the current session cookie, logging in when there is none
+func (c *QBittorrentClient) session(ctx context.Context) (*http.Cookie, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cookie != nil {
+		return c.cookie, nil
+	}
+	cookie, err := c.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.cookie = cookie
+	return cookie, nil
+}
+
+// call sends a request to an API method such as "torrents/info" and returns
+// the response body. GET is used without form values, POST with them. An
+// expired session (403) is renewed once.
+func (c *QBittorrentClient) call(ctx context.Context, method string, form url.Values) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		cookie, err := c.session(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var req *http.Request
+		endpoint := c.baseURL() + "/api/v2/" + method
+		if form == nil {
+			req, err = http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		} else {
+			req, err = http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+			if req != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+		req.Header.Set("Referer", c.baseURL())
+		req.AddCookie(cookie)
+		setProxyAuth(req, c.config)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
+		}
+
+		if resp.StatusCode == http.StatusForbidden && attempt == 0 {
+			resp.Body.Close()
+			c.mu.Lock()
+			if c.cookie == cookie {
+				c.cookie = nil
+			}
+			c.mu.Unlock()
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			resp.Body.Close()
+			return nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return body, nil
+	}
+}
+
+// getJSON calls a read-only API method and decodes its JSON response into v
+func (c *QBittorrentClient) getJSON(ctx context.Context, method string, v any) error {
+	body, err := c.call(ctx, method, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.NewProtocolError(c.config.Host, c.config.Port, "failed to parse JSON response", err)
+	}
+	return nil
+}
+
+// GetTorrents retrieves all torrents, numbered by the order they were added
+func (c *QBittorrentClient) GetTorrents(ctx context.Context) ([]types.TorrentInfo, error) {
+	var raw []qbTorrent
+	if err := c.getJSON(ctx, "torrents/info", &raw); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(raw, func(i, j int) bool {
+		if raw[i].AddedOn != raw[j].AddedOn {
+			return raw[i].AddedOn < raw[j].AddedOn
+		}
+		return raw[i].Hash < raw[j].Hash
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hashes = make(map[int]string, len(raw))
+	torrents := make([]types.TorrentInfo, 0, len(raw))
+	for i, t := range raw {
+		id := i + 1
+		c.hashes[id] = t.Hash
+		torrents = append(torrents, t.info(id))
+	}
+	return torrents, nil
+}
+
+// info converts t into a TorrentInfo with the given ID
+func (t qbTorrent) info(id int) types.TorrentInfo {
+	info := types.TorrentInfo{
+		ID:             id,
+		Name:           t.Name,
+		DownloadDir:    t.SavePath,
+		HashString:     t.Hash,
+		TotalSize:      t.TotalSize,
+		SizeWhenDone:   t.Size,
+		LeftUntilDone:  t.AmountLeft,
+		RateDownload:   t.DLSpeed,
+		RateUpload:     t.UPSpeed,
+		PercentDone:    t.Progress,
+		Status:         qbStatus(t.State),
+		AddedDate:      t.AddedOn,
+		DoneDate:       max(t.CompletionOn, 0),
+		SecondsSeeding: t.SeedingTime,
+		UploadedEver:   t.Uploaded,
+		DownloadedEver: t.Downloaded,
+		Ratio:          t.Ratio,
+		IsPrivate:      t.Private,
+		Labels:         []string{},
+	}
+
+	for _, tag := range strings.Split(t.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			info.Labels = append(info.Labels, tag)
+		}
+	}
+	if t.Tracker != "" {
+		info.Trackers = []types.Tracker{{Announce: t.Tracker}}
+	}
+	if info.Status == types.StatusVerifying {
+		info.RecheckProgress = t.Progress
+	}
+
+	// qBittorrent reports distributed copies rather than bytes: a full copy
+	// makes all remaining data available, and a fraction of one is taken as
+	// that share of it
+	info.PeersConnected = t.NumSeeds + t.NumLeechs
+	info.PeersSendingToUs = t.NumSeeds
+	if t.Availability >= 1 {
+		info.DesiredAvailable = t.AmountLeft
+	} else if t.Availability > 0 {
+		info.DesiredAvailable = int64(float64(t.AmountLeft) * t.Availability)
+	}
+	return info
+}
+
+// qbStatus maps a qBittorrent torrent state onto the Transmission status codes
+func qbStatus(state string) types.TorrentStatus {
+	switch state {
+	case "downloading", "forcedDL", "stalledDL", "metaDL", "forcedMetaDL", "allocating":
+		return types.StatusDownloading
+	case "uploading", "forcedUP", "stalledUP":
+		return types.StatusSeeding
+	case "queuedDL":
+		return types.StatusQueuedDownload
+	case "queuedUP":
+		return types.StatusQueuedSeed
+	case "checkingDL", "checkingUP", "checkingResumeData":
+		return types.StatusVerifying
+	default:
+		// pausedDL/UP, stoppedDL/UP, error, missingFiles, moving, unknown
+		return types.StatusStopped
+	}
+}
+
+// GetTorrentsWithOptions retrieves the torrents with the given IDs, or all.
+// Torrents are numbered by their position in the full list, so it is always
+// fetched; the Web API has no field selection, so opts.Fields is ignored.
+func (c *QBittorrentClient) GetTorrentsWithOptions(ctx context.Context, opts TorrentFetchOptions) ([]types.TorrentInfo, error) {
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil || len(opts.IDs) == 0 {
+		return torrents, err
+	}
+
+	wanted := make(map[int]bool, len(opts.IDs))
+	for _, id := range opts.IDs {
+		wanted[id] = true
+	}
+	selected := make([]types.TorrentInfo, 0, len(opts.IDs))
+	for _, t := range torrents {
+		if wanted[t.ID] {
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
+
+// hashList returns the hashes of ids joined for the Web API, failing on IDs
+// that GetTorrents has not reported
+func (c *QBittorrentClient) hashList(ids []int) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hashes := make([]string, 0, len(ids))
+	for _, id := range ids {
+		hash, ok := c.hashes[id]
+		if !ok {
+			return "", fmt.Errorf("unknown torrent ID %d", id)
+		}
+		hashes = append(hashes, hash)
+	}
+	return strings.Join(hashes, "|"), nil
+}
+
+// GetTorrentFiles retrieves the file lists of the given torrents, keyed by torrent ID
+func (c *QBittorrentClient) GetTorrentFiles(ctx context.Context, ids []int) (map[int][]types.TorrentFile, error) {
+	files := make(map[int][]types.TorrentFile, len(ids))
+	for _, id := range ids {
+		hash, err := c.hashList([]int{id})
+		if err != nil {
+			return nil, err
+		}
+
+		var raw []qbFile
+		if err := c.getJSON(ctx, "torrents/files?hash="+url.QueryEscape(hash), &raw); err != nil {
+			return nil, err
+		}
+		for _, f := range raw {
+			files[id] = append(files[id], types.TorrentFile{
+				Name:           f.Name,
+				Length:         f.Size,
+				BytesCompleted: int64(f.Progress * float64(f.Size)),
+			})
+		}
+	}
+	return files, nil
+}
+
+// torrentAction posts the given torrents to an action method. qBittorrent 5
+// renamed pause/resume to stop/start, so legacy is tried when method is missing.
+func (c *QBittorrentClient) torrentAction(ctx context.Context, method, legacy string, ids []int, extra url.Values) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	hashes, err := c.hashList(ids)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"hashes": {hashes}}
+	for k, v := range extra {
+		form[k] = v
+	}
+
+	_, err = c.call(ctx, method, form)
+	if te, ok := err.(*errors.TransmissionError); ok && legacy != "" && te.StatusCode == http.StatusNotFound {
+		_, err = c.call(ctx, legacy, form)
+	}
+	return err
+}
+
+// StartTorrents starts the given torrents
+func (c *QBittorrentClient) StartTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrents/start", "torrents/resume", ids, nil)
+}
+
+// StopTorrents stops the given torrents
+func (c *QBittorrentClient) StopTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrents/stop", "torrents/pause", ids, nil)
+}
+
+// VerifyTorrents asks qBittorrent to recheck local data for the given torrents
+func (c *QBittorrentClient) VerifyTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrents/recheck", "", ids, nil)
+}
+
+// SetTorrentLocation moves the given torrents' data to location. qBittorrent
+// always moves data, so move must be set.
+func (c *QBittorrentClient) SetTorrentLocation(ctx context.Context, ids []int, location string, move bool) error {
+	if !move {
+		return fmt.Errorf("qBittorrent cannot change a torrent's location without moving its data")
+	}
+	return c.torrentAction(ctx, "torrents/setLocation", "", ids, url.Values{"location": {location}})
+}
+
+// RemoveTorrents deletes the given torrents, and their data when deleteData is set
+func (c *QBittorrentClient) RemoveTorrents(ctx context.Context, ids []int, deleteData bool) error {
+	return c.torrentAction(ctx, "torrents/delete", "", ids, url.Values{"deleteFiles": {strconv.FormatBool(deleteData)}})
+}
+
+// SetTorrentLabels replaces the tags of the given torrents with labels
+func (c *QBittorrentClient) SetTorrentLabels(ctx context.Context, ids []int, labels []string) error {
+	// An empty tag list removes all tags
+	if err := c.torrentAction(ctx, "torrents/removeTags", "", ids, url.Values{"tags": {""}}); err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return c.torrentAction(ctx, "torrents/addTags", "", ids, url.Values{"tags": {strings.Join(labels, ",")}})
+}
+
+// GetAllTorrentPaths returns sorted list of all torrent paths
+func (c *QBittorrentClient) GetAllTorrentPaths(ctx context.Context) ([]string, error) {
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return torrentPaths(torrents), nil
+}
+
+// GetDownloadDirectories returns download directories with torrent counts
+func (c *QBittorrentClient) GetDownloadDirectories(ctx context.Context) ([]utils.DirectoryInfo, error) {
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return downloadDirectories(torrents), nil
+}
+
+// serverState retrieves the global transfer state
+func (c *QBittorrentClient) serverState(ctx context.Context) (*qbServerState, error) {
+	var maindata struct {
+		ServerState qbServerState `json:"server_state"`
+	}
+	if err := c.getJSON(ctx, "sync/maindata", &maindata); err != nil {
+		return nil, err
+	}
+	return &maindata.ServerState, nil
+}
+
+// GetSessionInfo retrieves the application preferences as session information
+func (c *QBittorrentClient) GetSessionInfo(ctx context.Context) (*types.SessionInfo, error) {
+	var prefs qbPreferences
+	if err := c.getJSON(ctx, "app/preferences", &prefs); err != nil {
+		return nil, err
+	}
+	state, err := c.serverState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &types.SessionInfo{
+		DownloadDir:          prefs.SavePath,
+		DownloadDirFree:      state.FreeSpaceOnDisk,
+		PeerPort:             prefs.ListenPort,
+		SeedRatioLimit:       prefs.MaxRatio,
+		SeedRatioLimited:     prefs.MaxRatioEnabled,
+		UploadSpeed:          state.UPInfoSpeed,
+		DownloadSpeed:        state.DLInfoSpeed,
+		AltSpeedEnabled:      state.UseAltSpeedLimits,
+		AltSpeedUp:           prefs.AltUPLimit / 1024,
+		AltSpeedDown:         prefs.AltDLLimit / 1024,
+		IncompleteDir:        prefs.TempPath,
+		IncompleteDirEnabled: prefs.TempPathEnabled,
+		RenamePartialFiles:   prefs.IncompleteFilesExt,
+	}
+	// The version is informational, so versions without the endpoint still work
+	if version, err := c.call(ctx, "app/version", nil); err == nil {
+		info.Version = strings.TrimSpace(string(version))
+	}
+	return info, nil
+}
+
+// GetFreeSpace returns the free space of the default save path, the only one
+// qBittorrent reports; other paths are an error
+func (c *QBittorrentClient) GetFreeSpace(ctx context.Context, path string) (int64, error) {
+	var prefs qbPreferences
+	if err := c.getJSON(ctx, "app/preferences", &prefs); err != nil {
+		return 0, err
+	}
+	if strings.TrimRight(path, "/") != strings.TrimRight(prefs.SavePath, "/") {
+		return 0, fmt.Errorf("qBittorrent reports free space only for its save path %s", prefs.SavePath)
+	}
+	state, err := c.serverState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return state.FreeSpaceOnDisk, nil
+}
+
+// GetSessionStats retrieves the transfer totals of this session and of all time
+func (c *QBittorrentClient) GetSessionStats(ctx context.Context) (*types.SessionStats, *types.SessionStats, error) {
+	state, err := c.serverState(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := &types.SessionStats{DownloadedBytes: state.DLInfoData, UploadedBytes: state.UPInfoData}
+	cumulative := &types.SessionStats{DownloadedBytes: state.AllTimeDL, UploadedBytes: state.AllTimeUL}
+	return current, cumulative, nil
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/errors"
+	"peerless/pkg/types"
+)
+
+const qbTorrentsJSON = `[
+	{"hash": "bbb", "name": "Second", "save_path": "/downloads/tv", "total_size": 200, "size": 200,
+	 "amount_left": 100, "progress": 0.5, "state": "stalledDL", "added_on": 200, "completion_on": -1, "tags": "",
+	 "availability": 0.4, "num_seeds": 0, "num_leechs": 3},
+	{"hash": "aaa", "name": "First", "save_path": "/downloads/movies", "total_size": 100, "size": 100,
+	 "progress": 1, "state": "uploading", "added_on": 100, "completion_on": 150, "ratio": 1.5,
+	 "tags": "movies, keep", "tracker": "https://tracker.example/announce", "private": true}
+]`
+
+// qbServer answers qBittorrent Web API requests from handlers keyed by method
+// (e.g. "torrents/info"), handling login itself
+func qbServer(t *testing.T, handlers map[string]func(form url.Values) *http.Response) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			method := strings.TrimPrefix(req.URL.Path, "/api/v2/")
+			if method == "auth/login" {
+				return NewMockResponse(200, "Ok.", map[string]string{"Set-Cookie": "SID=abc; path=/"}), nil
+			}
+
+			cookie, err := req.Cookie("SID")
+			require.NoError(t, err, "request without session cookie")
+			assert.Equal(t, "abc", cookie.Value)
+
+			form := req.URL.Query()
+			if req.Body != nil {
+				body, _ := io.ReadAll(req.Body)
+				form, _ = url.ParseQuery(string(body))
+			}
+
+			handler, ok := handlers[method]
+			if !ok {
+				return NewMockResponse(404, "Not Found", nil), nil
+			}
+			return handler(form), nil
+		},
+	}
+}
+
+func newTestQBittorrentClient(mockHTTP HTTPClient) *QBittorrentClient {
+	return NewQBittorrentClient(types.Config{Host: "localhost", Port: 8080, User: "admin", Password: "secret"}, mockHTTP)
+}
+
+func TestQBittorrentClient_GetTorrents(t *testing.T) {
+	mockHTTP := qbServer(t, map[string]func(url.Values) *http.Response{
+		"torrents/info": func(url.Values) *http.Response { return NewMockResponse(200, qbTorrentsJSON, nil) },
+	})
+
+	torrents, err := newTestQBittorrentClient(mockHTTP).GetTorrents(context.Background())
+	require.NoError(t, err)
+	require.Len(t, torrents, 2)
+
+	// Numbered in the order they were added
+	first := torrents[0]
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, "aaa", first.HashString)
+	assert.Equal(t, "/downloads/movies", first.DownloadDir)
+	assert.Equal(t, types.StatusSeeding, first.Status)
+	assert.Equal(t, int64(150), first.DoneDate)
+	assert.Equal(t, []string{"movies", "keep"}, first.Labels)
+	assert.Equal(t, []types.Tracker{{Announce: "https://tracker.example/announce"}}, first.Trackers)
+	assert.True(t, first.IsComplete())
+	assert.True(t, first.IsPrivate)
+
+	second := torrents[1]
+	assert.Equal(t, 2, second.ID)
+	assert.Equal(t, types.StatusDownloading, second.Status)
+	assert.Equal(t, int64(0), second.DoneDate)
+	assert.Empty(t, second.Labels)
+	assert.False(t, second.IsPrivate)
+	assert.Equal(t, 3, second.PeersConnected)
+	assert.Equal(t, int64(40), second.DesiredAvailable, "estimated from distributed copies")
+}
+
+func TestQBittorrentClient_Login(t *testing.T) {
+	t.Run("rejected credentials", func(t *testing.T) {
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return NewMockResponse(200, "Fails.", nil), nil
+			},
+		}
+
+		_, err := newTestQBittorrentClient(mockHTTP).GetTorrents(context.Background())
+		require.Error(t, err)
+		assert.True(t, errors.IsAuthenticationError(err))
+	})
+
+	t.Run("expired session logs in again", func(t *testing.T) {
+		logins, calls := 0, 0
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/api/v2/auth/login" {
+					logins++
+					return NewMockResponse(200, "Ok.", map[string]string{"Set-Cookie": "SID=abc"}), nil
+				}
+				calls++
+				if calls == 2 {
+					return NewMockResponse(403, "Forbidden", nil), nil
+				}
+				return NewMockResponse(200, "[]", nil), nil
+			},
+		}
+
+		client := newTestQBittorrentClient(mockHTTP)
+		_, err := client.GetTorrents(context.Background())
+		require.NoError(t, err)
+		_, err = client.GetTorrents(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, logins)
+		assert.Equal(t, 3, calls)
+	})
+}
+
+func TestQBittorrentClient_Actions(t *testing.T) {
+	var posted []string
+	var hashes, deleteFiles string
+	record := func(method string) func(url.Values) *http.Response {
+		return func(form url.Values) *http.Response {
+			posted = append(posted, method)
+			hashes = form.Get("hashes")
+			return NewMockResponse(200, "", nil)
+		}
+	}
+
+	mockHTTP := qbServer(t, map[string]func(url.Values) *http.Response{
+		"torrents/info":       func(url.Values) *http.Response { return NewMockResponse(200, qbTorrentsJSON, nil) },
+		"torrents/start":      record("torrents/start"),
+		"torrents/pause":      record("torrents/pause"),
+		"torrents/recheck":    record("torrents/recheck"),
+		"torrents/removeTags": record("torrents/removeTags"),
+		"torrents/addTags":    record("torrents/addTags"),
+		"torrents/delete": func(form url.Values) *http.Response {
+			deleteFiles = form.Get("deleteFiles")
+			return record("torrents/delete")(form)
+		},
+	})
+
+	client := newTestQBittorrentClient(mockHTTP)
+	ctx := context.Background()
+
+	t.Run("IDs must come from GetTorrents", func(t *testing.T) {
+		err := client.StartTorrents(ctx, []int{1})
+		assert.ErrorContains(t, err, "unknown torrent ID 1")
+	})
+
+	_, err := client.GetTorrents(ctx)
+	require.NoError(t, err)
+
+	t.Run("IDs are sent as hashes", func(t *testing.T) {
+		posted = nil
+		require.NoError(t, client.StartTorrents(ctx, []int{1, 2}))
+		assert.Equal(t, []string{"torrents/start"}, posted)
+		assert.Equal(t, "aaa|bbb", hashes)
+	})
+
+	t.Run("stop falls back to the pre-5.0 method", func(t *testing.T) {
+		posted = nil
+		require.NoError(t, client.StopTorrents(ctx, []int{2}))
+		assert.Equal(t, []string{"torrents/pause"}, posted)
+		assert.Equal(t, "bbb", hashes)
+	})
+
+	t.Run("labels replace all tags", func(t *testing.T) {
+		posted = nil
+		require.NoError(t, client.SetTorrentLabels(ctx, []int{1}, []string{"a", "b"}))
+		assert.Equal(t, []string{"torrents/removeTags", "torrents/addTags"}, posted)
+	})
+
+	t.Run("remove with data", func(t *testing.T) {
+		posted = nil
+		require.NoError(t, client.RemoveTorrents(ctx, []int{2}, true))
+		assert.Equal(t, []string{"torrents/delete"}, posted)
+		assert.Equal(t, "bbb", hashes)
+		assert.Equal(t, "true", deleteFiles)
+	})
+
+	t.Run("location cannot change without moving", func(t *testing.T) {
+		err := client.SetTorrentLocation(ctx, []int{1}, "/library", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestQBittorrentClient_Session(t *testing.T) {
+	mockHTTP := qbServer(t, map[string]func(url.Values) *http.Response{
+		"app/preferences": func(url.Values) *http.Response {
+			return NewMockResponse(200, `{"save_path": "/downloads", "listen_port": 6881, "temp_path": "/incomplete", "temp_path_enabled": true}`, nil)
+		},
+		"sync/maindata": func(url.Values) *http.Response {
+			return NewMockResponse(200, `{"server_state": {"alltime_dl": 1000, "alltime_ul": 2000, "dl_info_data": 10, "up_info_data": 20, "free_space_on_disk": 5000}}`, nil)
+		},
+		"app/version": func(url.Values) *http.Response {
+			return NewMockResponse(200, "v4.6.2", nil)
+		},
+	})
+	client := newTestQBittorrentClient(mockHTTP)
+
+	info, err := client.GetSessionInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "/downloads", info.DownloadDir)
+	assert.Equal(t, int64(5000), info.DownloadDirFree)
+	assert.Equal(t, 6881, info.PeerPort)
+	assert.Equal(t, "/incomplete", info.IncompleteDir)
+	assert.True(t, info.IncompleteDirEnabled)
+	assert.Equal(t, "v4.6.2", info.Version)
+
+	current, cumulative, err := client.GetSessionStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), current.DownloadedBytes)
+	assert.Equal(t, int64(20), current.UploadedBytes)
+	assert.Equal(t, int64(1000), cumulative.DownloadedBytes)
+	assert.Equal(t, int64(2000), cumulative.UploadedBytes)
+
+	free, err := client.GetFreeSpace(context.Background(), "/downloads/")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), free)
+
+	_, err = client.GetFreeSpace(context.Background(), "/other")
+	assert.ErrorContains(t, err, "only for its save path")
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(types.Config{Host: "localhost"}, &MockHTTPClient{})
+	require.NoError(t, err)
+	assert.IsType(t, &TransmissionClient{}, c)
+
+	c, err = New(types.Config{Host: "localhost", Client: constants.ClientQBittorrent}, &MockHTTPClient{})
+	require.NoError(t, err)
+	assert.IsType(t, &QBittorrentClient{}, c)
+
+	_, err = New(types.Config{Host: "localhost", Client: "deluge"}, &MockHTTPClient{})
+	assert.ErrorContains(t, err, "unsupported client")
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"peerless/pkg/types"
+)
+
+// replaySessionID is handed out by ReplayHTTPClient during the session handshake
+const replaySessionID = "replay"
+
+// Exchange is one recorded RPC request and its response
+type Exchange struct {
+	Method     string          `json:"method"`
+	Request    json.RawMessage `json:"request"`
+	StatusCode int             `json:"status_code"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// Recording is a sequence of RPC exchanges saved with --record
+type Recording struct {
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// LoadRecording reads a recording written by RecordingHTTPClient
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	recording := &Recording{}
+	if err := json.Unmarshal(data, recording); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+	}
+	return recording, nil
+}
+
+// NewOfflineRecording builds a recording that answers torrent-get with
+// torrents and session queries with empty results, so commands can run
+// against an exported torrent list without a daemon
+func NewOfflineRecording(torrents []types.TorrentInfo) (*Recording, error) {
+	response := types.TransmissionResponse{Result: "success"}
+	response.Arguments.Torrents = torrents
+	torrentGet, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode torrents: %w", err)
+	}
+
+	empty := json.RawMessage(`{"arguments": {}, "result": "success"}`)
+	return &Recording{Exchanges: []Exchange{
+		{Method: "torrent-get", StatusCode: http.StatusOK, Response: torrentGet},
+		{Method: "session-get", StatusCode: http.StatusOK, Response: empty},
+		{Method: "session-stats", StatusCode: http.StatusOK, Response: empty},
+	}}, nil
+}
+
+// RecordingHTTPClient passes requests through to another HTTPClient and saves
+// every RPC exchange to a file. Only bodies are stored, so credentials sent in
+// headers never reach the recording.
+type RecordingHTTPClient struct {
+	next      HTTPClient
+	path      string
+	mu        sync.Mutex
+	recording Recording
+}
+
+// NewRecordingHTTPClient creates a RecordingHTTPClient writing to path
+func NewRecordingHTTPClient(next HTTPClient, path string) *RecordingHTTPClient {
+	return &RecordingHTTPClient{next: next, path: path}
+}
+
+// Do performs the request and records it when it carries an RPC method
+func (r *RecordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	method := rpcMethod(requestBody)
+	if method == "" || resp.StatusCode == http.StatusConflict {
+		return resp, nil
+	}
+
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording.Exchanges = append(r.recording.Exchanges, Exchange{
+		Method:     method,
+		Request:    json.RawMessage(requestBody),
+		StatusCode: resp.StatusCode,
+		Response:   rawJSON(responseBody),
+	})
+
+	// Rewrite the whole file each time so a failing command still leaves a usable recording
+	data, err := json.MarshalIndent(r.recording, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ReplayHTTPClient answers RPC requests from a Recording instead of a live daemon
+type ReplayHTTPClient struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+// NewReplayHTTPClient creates a ReplayHTTPClient serving recording
+func NewReplayHTTPClient(recording *Recording) *ReplayHTTPClient {
+	return &ReplayHTTPClient{
+		exchanges: recording.Exchanges,
+		used:      make([]bool, len(recording.Exchanges)),
+	}
+}
+
+// Do returns the recorded response for the request. Exchanges with an identical
+// request body are preferred, then unused exchanges of the same method in
+// recorded order; once those run out the last one for the method is repeated.
+func (r *ReplayHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	method := rpcMethod(body)
+	if method == "" {
+		// Session handshake
+		return replayResponse(http.StatusConflict, []byte("{}"), replaySessionID), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, ex := range r.exchanges {
+		if !r.used[i] && ex.Method == method && jsonEqual(ex.Request, body) {
+			match = i
+			break
+		}
+	}
+	if match < 0 {
+		for i, ex := range r.exchanges {
+			if !r.used[i] && ex.Method == method {
+				match = i
+				break
+			}
+		}
+	}
+	if match < 0 {
+		for i := len(r.exchanges) - 1; i >= 0; i-- {
+			if r.exchanges[i].Method == method {
+				match = i
+				break
+			}
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded response for RPC method %q", method)
+	}
+
+	r.used[match] = true
+	ex := r.exchanges[match]
+	return replayResponse(ex.StatusCode, ex.Response, replaySessionID), nil
+}
+
+// readBody reads and restores a request or response body
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// rpcMethod extracts the RPC method name from a request body
+func rpcMethod(body []byte) string {
+	var request struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return ""
+	}
+	return request.Method
+}
+
+// rawJSON keeps valid JSON as is and stores anything else as a JSON string
+func rawJSON(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return json.RawMessage(quoted)
+}
+
+// jsonEqual compares two JSON documents ignoring formatting
+func jsonEqual(a, b []byte) bool {
+	var bufA, bufB bytes.Buffer
+	if json.Compact(&bufA, a) != nil || json.Compact(&bufB, b) != nil {
+		return false
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
+
+func replayResponse(statusCode int, body []byte, sessionID string) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Transmission-Session-Id", sessionID)
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"peerless/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	config := types.Config{Host: "localhost", Port: 9091, User: "admin", Password: "s3cret-pass"}
+
+	calls := 0
+	live := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "live-session",
+				}), nil
+			}
+			// Each GetTorrents lists the IDs, then fetches their fields
+			calls++
+			if calls <= 2 {
+				return NewMockResponse(200, `{"arguments": {"torrents": [{"id": 1, "name": "First"}]}, "result": "success"}`, nil), nil
+			}
+			return NewMockResponse(200, `{"arguments": {"torrents": [{"id": 2, "name": "Second"}]}, "result": "success"}`, nil), nil
+		},
+	}
+
+	recorder := NewTransmissionClientWithHTTPClient(config, NewRecordingHTTPClient(live, path))
+	first, err := recorder.GetTorrents(context.Background())
+	require.NoError(t, err)
+	second, err := recorder.GetTorrents(context.Background())
+	require.NoError(t, err)
+
+	recording, err := LoadRecording(path)
+	require.NoError(t, err)
+	require.Len(t, recording.Exchanges, 4)
+	assert.Equal(t, "torrent-get", recording.Exchanges[0].Method)
+	assert.NotContains(t, string(recording.Exchanges[0].Request), "s3cret-pass")
+
+	replayer := NewTransmissionClientWithHTTPClient(types.Config{Host: "replay", Port: 9091}, NewReplayHTTPClient(recording))
+
+	t.Run("responses replay in recorded order", func(t *testing.T) {
+		got, err := replayer.GetTorrents(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, first, got)
+
+		got, err = replayer.GetTorrents(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, second, got)
+	})
+
+	t.Run("last response repeats when exhausted", func(t *testing.T) {
+		got, err := replayer.GetTorrents(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, second, got)
+	})
+
+	t.Run("unrecorded method fails", func(t *testing.T) {
+		_, err := replayer.GetSessionInfo(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestOfflineRecording(t *testing.T) {
+	torrents := []types.TorrentInfo{{ID: 1, Name: "Exported", DownloadDir: "/downloads"}}
+	recording, err := NewOfflineRecording(torrents)
+	require.NoError(t, err)
+
+	offline := NewTransmissionClientWithHTTPClient(types.Config{Host: "offline", Port: 9091}, NewReplayHTTPClient(recording))
+
+	got, err := offline.GetTorrents(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Exported", got[0].Name)
+
+	session, err := offline.GetSessionInfo(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, session.IncompleteDir)
+
+	err = offline.StartTorrents(context.Background(), []int{1})
+	assert.Error(t, err)
+}
@@ -3,13 +3,15 @@ package client
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
-	"path/filepath"
-	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"peerless/pkg/constants"
 	"peerless/pkg/errors"
@@ -31,16 +33,62 @@ type TransmissionClient struct {
 }
 
 func NewTransmissionClient(config types.Config) *TransmissionClient {
+	utils.RegisterBasicAuth(config.User, config.Password)
+	utils.RegisterBasicAuth(config.ProxyUser, config.ProxyPassword)
 	return &TransmissionClient{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: constants.HTTPTimeout,
-		},
+		config:     config,
+		httpClient: NewHTTPClientWithOptions(HTTPOptions{Socket: config.Socket}),
+	}
+}
+
+// NewHTTPClient returns the HTTP client used for live connections, with the default timeouts
+func NewHTTPClient() *http.Client {
+	return NewHTTPClientWithOptions(HTTPOptions{})
+}
+
+// HTTPOptions configures the HTTP client used for live connections
+type HTTPOptions struct {
+	// Timeout bounds each request including reading its response
+	Timeout time.Duration
+	// ConnectTimeout bounds establishing a connection, including the TLS handshake
+	ConnectTimeout time.Duration
+	// Socket, when set, is a Unix domain socket every connection is made to
+	// instead of the host and port in the request URL
+	Socket string
+}
+
+// NewHTTPClientWithOptions returns the HTTP client used for live connections.
+// Zero timeouts use the defaults.
+func NewHTTPClientWithOptions(opts HTTPOptions) *http.Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = constants.HTTPTimeout
+	}
+	if opts.ConnectTimeout == 0 {
+		opts.ConnectTimeout = constants.ConnectTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: opts.ConnectTimeout, KeepAlive: constants.HTTPKeepAlive}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	if opts.Socket != "" {
+		// A local socket is never reached through a proxy
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", opts.Socket)
+		}
+	}
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
 	}
 }
 
-// NewTransmissionClientWithHTTPClient for testing with mock HTTP client
+// NewTransmissionClientWithHTTPClient uses a custom HTTP client, such as a mock
+// in tests or a recording or replaying client
 func NewTransmissionClientWithHTTPClient(config types.Config, httpClient HTTPClient) *TransmissionClient {
+	utils.RegisterBasicAuth(config.User, config.Password)
+	utils.RegisterBasicAuth(config.ProxyUser, config.ProxyPassword)
 	return &TransmissionClient{
 		config:     config,
 		httpClient: httpClient,
@@ -49,7 +97,17 @@ func NewTransmissionClientWithHTTPClient(config types.Config, httpClient HTTPCli
 
 // baseURL returns the Transmission RPC endpoint URL
 func (c *TransmissionClient) baseURL() string {
-	return fmt.Sprintf("http://%s:%d/transmission/rpc", c.config.Host, c.config.Port)
+	scheme := "http"
+	if c.config.UseTLS {
+		scheme = "https"
+	}
+
+	path := c.config.RPCPath
+	if path == "" {
+		path = constants.DefaultRPCPath
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port)), path)
 }
 
 // getSessionID retrieves the current session ID, or fetches a new one
@@ -79,6 +137,27 @@ func (c *TransmissionClient) getSessionID(ctx context.Context) (string, error) {
 	return sessionID, nil
 }
 
+// setAuth adds the daemon and reverse proxy credentials to req
+func (c *TransmissionClient) setAuth(req *http.Request) {
+	if c.config.User != "" {
+		req.SetBasicAuth(c.config.User, c.config.Password)
+	}
+	setProxyAuth(req, c.config)
+}
+
+// setProxyAuth adds the reverse proxy credentials of config to req
+func setProxyAuth(req *http.Request, config types.Config) {
+	if config.ProxyUser == "" {
+		return
+	}
+	header := config.ProxyAuthHeader
+	if header == "" {
+		header = constants.DefaultProxyAuthHeader
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(config.ProxyUser + ":" + config.ProxyPassword))
+	req.Header.Set(header, "Basic "+credentials)
+}
+
 // fetchSessionID fetches a new session ID from Transmission
 func (c *TransmissionClient) fetchSessionID(ctx context.Context) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL(), bytes.NewBuffer([]byte("{}")))
@@ -86,9 +165,7 @@ func (c *TransmissionClient) fetchSessionID(ctx context.Context) (string, error)
 		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	if c.config.User != "" {
-		req.SetBasicAuth(c.config.User, c.config.Password)
-	}
+	c.setAuth(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -102,67 +179,92 @@ func (c *TransmissionClient) fetchSessionID(ctx context.Context) (string, error)
 
 	sessionID := resp.Header.Get("X-Transmission-Session-Id")
 	if sessionID == "" {
-		return "", fmt.Errorf("no session ID received from Transmission at %s:%d", c.config.Host, c.config.Port)
+		return "", errors.NewProtocolError(c.config.Host, c.config.Port, "no session ID received from Transmission", nil)
 	}
 
 	return sessionID, nil
 }
 
-// doRequest performs an authenticated request to Transmission
-func (c *TransmissionClient) doRequest(ctx context.Context, reqBody types.TransmissionRequest) (*types.TransmissionResponse, error) {
-	sessionID, err := c.getSessionID(ctx)
-	if err != nil {
-		return nil, err
-	}
-
+// post sends reqBody to the RPC endpoint and returns the response body. When
+// the session expires mid-run Transmission answers 409 with a fresh session ID;
+// the request is then retried with it, up to constants.MaxSessionRetries times.
+func (c *TransmissionClient) post(ctx context.Context, reqBody types.TransmissionRequest) ([]byte, error) {
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request to JSON: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL(), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Transmission-Session-Id", sessionID)
-
-	if c.config.User != "" {
-		req.SetBasicAuth(c.config.User, c.config.Password)
+	for attempt := 0; ; attempt++ {
+		sessionID, err := c.getSessionID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL(), bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Transmission-Session-Id", sessionID)
+
+		c.setAuth(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
+		}
+
+		if resp.StatusCode == http.StatusConflict {
+			resp.Body.Close()
+			if attempt >= constants.MaxSessionRetries {
+				return nil, errors.NewProtocolError(c.config.Host, c.config.Port,
+					fmt.Sprintf("session ID rejected %d times in a row", attempt+1), nil)
+			}
+			c.renewSession(sessionID, resp.Header.Get("X-Transmission-Session-Id"))
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			resp.Body.Close()
+			return nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return body, nil
 	}
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
-	}
-	defer resp.Body.Close()
-
-	// Handle session conflict - invalidate and retry once
-	if resp.StatusCode == 409 {
-		c.sessionLock.Lock()
-		c.sessionID = ""
-		c.sessionLock.Unlock()
-
-		return c.doRequest(ctx, reqBody)
-	}
+// renewSession replaces the expired session ID stale with fresh, or clears it
+// so the next request performs a new handshake. A session already renewed by a
+// concurrent request is left alone.
+func (c *TransmissionClient) renewSession(stale, fresh string) {
+	c.sessionLock.Lock()
+	defer c.sessionLock.Unlock()
 
-	if resp.StatusCode >= 400 {
-		return nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
+	if c.sessionID == stale {
+		c.sessionID = fresh
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
+// doRequest performs an authenticated request to Transmission
+func (c *TransmissionClient) doRequest(ctx context.Context, reqBody types.TransmissionRequest) (*types.TransmissionResponse, error) {
+	body, err := c.post(ctx, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	var result types.TransmissionResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, errors.NewProtocolError(c.config.Host, c.config.Port, "failed to parse JSON response", err)
 	}
 
 	if result.Result != "success" {
-		return nil, fmt.Errorf("transmission returned: %s", result.Result)
+		return nil, errors.NewProtocolError(c.config.Host, c.config.Port, "transmission returned: "+result.Result, nil)
 	}
 
 	return &result, nil
@@ -170,16 +272,48 @@ func (c *TransmissionClient) doRequest(ctx context.Context, reqBody types.Transm
 
 // GetTorrents retrieves all torrents from Transmission
 func (c *TransmissionClient) GetTorrents(ctx context.Context) ([]types.TorrentInfo, error) {
+	return c.GetTorrentsWithOptions(ctx, TorrentFetchOptions{})
+}
+
+// GetTorrentsWithOptions retrieves the torrents and fields selected by opts.
+// Torrents are requested constants.TorrentFetchChunkSize at a time, so
+// instances with tens of thousands of torrents are not sent in one response;
+// without IDs their IDs are listed first.
+func (c *TransmissionClient) GetTorrentsWithOptions(ctx context.Context, opts TorrentFetchOptions) ([]types.TorrentInfo, error) {
+	ids := opts.IDs
+	if len(ids) == 0 {
+		var err error
+		if ids, err = c.torrentIDs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := opts.fields()
+	torrents := make([]types.TorrentInfo, 0, len(ids))
+	for _, chunk := range chunks(ids, constants.TorrentFetchChunkSize) {
+		reqBody := types.TransmissionRequest{
+			Method: "torrent-get",
+			Arguments: map[string]interface{}{
+				"ids":    chunk,
+				"fields": fields,
+			},
+		}
+
+		resp, err := c.doRequest(ctx, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		torrents = append(torrents, resp.Arguments.Torrents...)
+	}
+	return torrents, nil
+}
+
+// torrentIDs lists the IDs of all torrents
+func (c *TransmissionClient) torrentIDs(ctx context.Context) ([]int, error) {
 	reqBody := types.TransmissionRequest{
 		Method: "torrent-get",
 		Arguments: map[string]interface{}{
-			"fields": []string{
-				"id", "name", "downloadDir", "hashString",
-				"totalSize", "sizeWhenDone", "leftUntilDone",
-				"rateDownload", "rateUpload", "percentDone",
-				"status", "addedDate", "doneDate",
-				"uploadedEver", "downloadedEver", "uploadRatio",
-			},
+			"fields": []string{"id"},
 		},
 	}
 
@@ -188,50 +322,135 @@ func (c *TransmissionClient) GetTorrents(ctx context.Context) ([]types.TorrentIn
 		return nil, err
 	}
 
-	return resp.Arguments.Torrents, nil
+	ids := make([]int, 0, len(resp.Arguments.Torrents))
+	for _, t := range resp.Arguments.Torrents {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
 }
 
-// GetAllTorrentPaths returns sorted list of all torrent paths
-func (c *TransmissionClient) GetAllTorrentPaths(ctx context.Context) ([]string, error) {
-	torrents, err := c.GetTorrents(ctx)
+// GetTorrentFiles retrieves the file lists of the given torrents, keyed by
+// torrent ID, constants.TorrentFetchChunkSize torrents at a time
+func (c *TransmissionClient) GetTorrentFiles(ctx context.Context, ids []int) (map[int][]types.TorrentFile, error) {
+	files := make(map[int][]types.TorrentFile, len(ids))
+	if len(ids) == 0 {
+		return files, nil
+	}
+
+	torrents, err := c.GetTorrentsWithOptions(ctx, TorrentFetchOptions{IDs: ids, Fields: []string{"id", "files"}})
 	if err != nil {
 		return nil, err
 	}
+	for _, t := range torrents {
+		files[t.ID] = t.Files
+	}
+	return files, nil
+}
 
-	paths := make([]string, 0, len(torrents))
-	for _, torrent := range torrents {
-		absPath := filepath.Join(torrent.DownloadDir, torrent.Name)
-		cleanPath := utils.SanitizeString(absPath)
-		paths = append(paths, cleanPath)
+// torrentAction invokes an RPC action method (torrent-start, torrent-verify, ...) on the given torrent IDs
+func (c *TransmissionClient) torrentAction(ctx context.Context, method string, ids []int) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	sort.Strings(paths)
-	return paths, nil
+	reqBody := types.TransmissionRequest{
+		Method: method,
+		Arguments: map[string]interface{}{
+			"ids": ids,
+		},
+	}
+
+	_, err := c.doRequest(ctx, reqBody)
+	return err
 }
 
-// GetDownloadDirectories returns download directories with torrent counts
-func (c *TransmissionClient) GetDownloadDirectories(ctx context.Context) ([]utils.DirectoryInfo, error) {
-	torrents, err := c.GetTorrents(ctx)
-	if err != nil {
-		return nil, err
+// VerifyTorrents asks Transmission to re-verify local data for the given torrents
+func (c *TransmissionClient) VerifyTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrent-verify", ids)
+}
+
+// StartTorrents starts the given torrents
+func (c *TransmissionClient) StartTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrent-start", ids)
+}
+
+// StopTorrents stops the given torrents
+func (c *TransmissionClient) StopTorrents(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrent-stop", ids)
+}
+
+// SetTorrentLocation points the given torrents at location, moving their data there when move is set
+func (c *TransmissionClient) SetTorrentLocation(ctx context.Context, ids []int, location string, move bool) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	dirMap := make(map[string]int)
-	for _, t := range torrents {
-		dirMap[t.DownloadDir]++
+	reqBody := types.TransmissionRequest{
+		Method: "torrent-set-location",
+		Arguments: map[string]interface{}{
+			"ids":      ids,
+			"location": location,
+			"move":     move,
+		},
 	}
 
-	dirs := make([]utils.DirectoryInfo, 0, len(dirMap))
-	for path, count := range dirMap {
-		cleanPath := utils.SanitizeString(path)
-		dirs = append(dirs, utils.DirectoryInfo{Path: cleanPath, Count: count})
+	_, err := c.doRequest(ctx, reqBody)
+	return err
+}
+
+// RemoveTorrents removes the given torrents from Transmission, deleting their
+// local data as well when deleteData is set
+func (c *TransmissionClient) RemoveTorrents(ctx context.Context, ids []int, deleteData bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	reqBody := types.TransmissionRequest{
+		Method: "torrent-remove",
+		Arguments: map[string]interface{}{
+			"ids":               ids,
+			"delete-local-data": deleteData,
+		},
+	}
+
+	_, err := c.doRequest(ctx, reqBody)
+	return err
+}
+
+// SetTorrentLabels replaces the labels of the given torrents
+func (c *TransmissionClient) SetTorrentLabels(ctx context.Context, ids []int, labels []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	reqBody := types.TransmissionRequest{
+		Method: "torrent-set",
+		Arguments: map[string]interface{}{
+			"ids":    ids,
+			"labels": labels,
+		},
 	}
 
-	sort.Slice(dirs, func(i, j int) bool {
-		return dirs[i].Path < dirs[j].Path
-	})
+	_, err := c.doRequest(ctx, reqBody)
+	return err
+}
 
-	return dirs, nil
+// GetAllTorrentPaths returns sorted list of all torrent paths
+func (c *TransmissionClient) GetAllTorrentPaths(ctx context.Context) ([]string, error) {
+	torrents, err := c.GetTorrentsWithOptions(ctx, TorrentFetchOptions{Fields: []string{"name", "downloadDir"}})
+	if err != nil {
+		return nil, err
+	}
+	return torrentPaths(torrents), nil
+}
+
+// GetDownloadDirectories returns download directories with torrent counts
+func (c *TransmissionClient) GetDownloadDirectories(ctx context.Context) ([]utils.DirectoryInfo, error) {
+	torrents, err := c.GetTorrentsWithOptions(ctx, TorrentFetchOptions{Fields: []string{"downloadDir", "totalSize"}})
+	if err != nil {
+		return nil, err
+	}
+	return downloadDirectories(torrents), nil
 }
 
 // Legacy methods for backward compatibility (deprecated)
@@ -261,62 +480,24 @@ func (c *TransmissionClient) GetSessionInfo(ctx context.Context) (*types.Session
 				"seedRatioLimit", "seedRatioLimited",
 				"uploadSpeed", "downloadSpeed",
 				"alt-speed-enabled", "alt-speed-up", "alt-speed-down",
+				"incomplete-dir", "incomplete-dir-enabled", "rename-partial-files",
+				"version", "rpc-version", "rpc-version-minimum",
 			},
 		},
 	}
 
-	sessionID, err := c.getSessionID(ctx)
+	body, err := c.post(ctx, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request to JSON: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL(), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Transmission-Session-Id", sessionID)
-
-	if c.config.User != "" {
-		req.SetBasicAuth(c.config.User, c.config.Password)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
-	}
-	defer resp.Body.Close()
-
-	// Handle session conflict - invalidate and retry once
-	if resp.StatusCode == 409 {
-		c.sessionLock.Lock()
-		c.sessionID = ""
-		c.sessionLock.Unlock()
-		return c.GetSessionInfo(ctx)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
 	var result types.TransmissionSessionResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, errors.NewProtocolError(c.config.Host, c.config.Port, "failed to parse JSON response", err)
 	}
 
 	if result.Result != "success" {
-		return nil, fmt.Errorf("transmission returned: %s", result.Result)
+		return nil, errors.NewProtocolError(c.config.Host, c.config.Port, "transmission returned: "+result.Result, nil)
 	}
 
 	return &result.Arguments, nil
@@ -328,59 +509,46 @@ func (c *TransmissionClient) GetSessionStats(ctx context.Context) (*types.Sessio
 		Method: "session-stats",
 	}
 
-	sessionID, err := c.getSessionID(ctx)
+	body, err := c.post(ctx, reqBody)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal request to JSON: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL(), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Transmission-Session-Id", sessionID)
-
-	if c.config.User != "" {
-		req.SetBasicAuth(c.config.User, c.config.Password)
+	var result types.TransmissionStatsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, nil, errors.NewProtocolError(c.config.Host, c.config.Port, "failed to parse JSON response", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, nil, errors.NewTransmissionError(0, c.config.Host, c.config.Port, err)
+	if result.Result != "success" {
+		return nil, nil, errors.NewProtocolError(c.config.Host, c.config.Port, "transmission returned: "+result.Result, nil)
 	}
-	defer resp.Body.Close()
 
-	// Handle session conflict - invalidate and retry once
-	if resp.StatusCode == 409 {
-		c.sessionLock.Lock()
-		c.sessionID = ""
-		c.sessionLock.Unlock()
-		return c.GetSessionStats(ctx)
-	}
+	return &result.Arguments.CurrentStats, &result.Arguments.CumulativeStats, nil
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, nil, errors.NewTransmissionError(resp.StatusCode, c.config.Host, c.config.Port, nil)
+// GetFreeSpace returns the free space, in bytes, of the filesystem holding
+// path on the daemon's host
+func (c *TransmissionClient) GetFreeSpace(ctx context.Context, path string) (int64, error) {
+	reqBody := types.TransmissionRequest{
+		Method: "free-space",
+		Arguments: map[string]interface{}{
+			"path": path,
+		},
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, reqBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		return 0, err
 	}
 
-	var result types.TransmissionStatsResponse
+	var result types.TransmissionFreeSpaceResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse JSON response: %w", err)
+		return 0, errors.NewProtocolError(c.config.Host, c.config.Port, "failed to parse JSON response", err)
 	}
 
 	if result.Result != "success" {
-		return nil, nil, fmt.Errorf("transmission returned: %s", result.Result)
+		return 0, errors.NewProtocolError(c.config.Host, c.config.Port, "transmission returned: "+result.Result, nil)
 	}
 
-	return &result.Arguments.CurrentStats, &result.Arguments.CumulativeStats, nil
+	return result.Arguments.SizeBytes, nil
 }
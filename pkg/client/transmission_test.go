@@ -2,11 +2,22 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"peerless/pkg/constants"
+	"peerless/pkg/errors"
 	"peerless/pkg/types"
 )
 
@@ -23,6 +34,53 @@ func TestNewTransmissionClient(t *testing.T) {
 	assert.NotNil(t, client.httpClient)
 }
 
+func TestNewHTTPClientWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	t.Run("times out slow requests", func(t *testing.T) {
+		httpClient := NewHTTPClientWithOptions(HTTPOptions{Timeout: 50 * time.Millisecond, ConnectTimeout: time.Second})
+		assert.Equal(t, 50*time.Millisecond, httpClient.Timeout)
+
+		client := NewTransmissionClientWithHTTPClient(types.Config{Host: u.Hostname(), Port: port}, httpClient)
+		_, err = client.GetSessionInfo(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, errors.KindTimeout, errors.KindOf(err))
+	})
+
+	t.Run("zero timeouts use the defaults", func(t *testing.T) {
+		assert.Equal(t, constants.HTTPTimeout, NewHTTPClientWithOptions(HTTPOptions{}).Timeout)
+	})
+
+	t.Run("connects through a unix socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "rpc.sock")
+		listener, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Transmission-Session-Id") == "" {
+				w.Header().Set("X-Transmission-Session-Id", "test-session")
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			fmt.Fprint(w, `{"arguments": {"version": "4.0.5", "rpc-version": 17}, "result": "success"}`)
+		})}
+		go server.Serve(listener)
+		defer server.Close()
+
+		client := NewTransmissionClient(types.Config{Host: constants.SocketHost, Port: constants.DefaultPort, Socket: socket})
+		info, err := client.GetSessionInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "4.0.5", info.Version)
+	})
+}
+
 func TestNewTransmissionClientWithHTTPClient(t *testing.T) {
 	config := types.Config{
 		Host:     "localhost",
@@ -223,6 +281,74 @@ func TestGetTorrents(t *testing.T) {
 	})
 }
 
+func TestGetTorrentsWithOptions(t *testing.T) {
+	total := constants.TorrentFetchChunkSize*2 + 5
+
+	type torrentGet struct {
+		IDs    []int    `json:"ids"`
+		Fields []string `json:"fields"`
+	}
+	var requests []torrentGet
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session-id",
+				}), nil
+			}
+
+			var rpc struct {
+				Arguments torrentGet `json:"arguments"`
+			}
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &rpc))
+			requests = append(requests, rpc.Arguments)
+
+			ids := rpc.Arguments.IDs
+			if ids == nil {
+				for id := 1; id <= total; id++ {
+					ids = append(ids, id)
+				}
+			}
+			torrents := make([]types.TorrentInfo, 0, len(ids))
+			for _, id := range ids {
+				torrents = append(torrents, types.TorrentInfo{ID: id, Name: fmt.Sprintf("t%d", id)})
+			}
+			resp, err := json.Marshal(map[string]any{"arguments": map[string]any{"torrents": torrents}, "result": "success"})
+			require.NoError(t, err)
+			return NewMockResponse(200, string(resp), nil), nil
+		},
+	}
+	client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+	t.Run("all torrents in chunks", func(t *testing.T) {
+		requests = nil
+		torrents, err := client.GetTorrents(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, torrents, total)
+
+		require.Len(t, requests, 4)
+		assert.Equal(t, []string{"id"}, requests[0].Fields)
+		assert.Nil(t, requests[0].IDs)
+		assert.Len(t, requests[1].IDs, constants.TorrentFetchChunkSize)
+		assert.Len(t, requests[3].IDs, 5)
+		assert.Equal(t, TorrentFields, requests[1].Fields)
+	})
+
+	t.Run("selected ids and fields", func(t *testing.T) {
+		requests = nil
+		torrents, err := client.GetTorrentsWithOptions(context.Background(), TorrentFetchOptions{IDs: []int{4, 9}, Fields: []string{"name"}})
+		require.NoError(t, err)
+		require.Len(t, torrents, 2)
+		assert.Equal(t, "t9", torrents[1].Name)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, []int{4, 9}, requests[0].IDs)
+		assert.Equal(t, []string{"id", "name"}, requests[0].Fields)
+	})
+}
+
 func TestGetAllTorrentPaths(t *testing.T) {
 	t.Run("successful path retrieval with sorting", func(t *testing.T) {
 		sessionID := "test-session-id"
@@ -301,13 +427,15 @@ func TestGetDownloadDirectories(t *testing.T) {
 						"id": 1,
 						"name": "Torrent 1",
 						"downloadDir": "/downloads/movies",
-						"hashString": "abc123"
+						"hashString": "abc123",
+						"totalSize": 100
 					},
 					{
 						"id": 2,
 						"name": "Torrent 2",
 						"downloadDir": "/downloads/movies",
-						"hashString": "def456"
+						"hashString": "def456",
+						"totalSize": 50
 					},
 					{
 						"id": 3,
@@ -348,6 +476,7 @@ func TestGetDownloadDirectories(t *testing.T) {
 		assert.Len(t, dirs, 2)
 		assert.Equal(t, "/downloads/movies", dirs[0].Path)
 		assert.Equal(t, 2, dirs[0].Count)
+		assert.Equal(t, int64(150), dirs[0].TotalSize)
 		assert.Equal(t, "/downloads/tv", dirs[1].Path)
 		assert.Equal(t, 1, dirs[1].Count)
 	})
@@ -362,4 +491,253 @@ func TestBaseURL(t *testing.T) {
 
 	expected := "http://localhost:9091/transmission/rpc"
 	assert.Equal(t, expected, client.baseURL())
+
+	t.Run("tls and custom path", func(t *testing.T) {
+		client := NewTransmissionClient(types.Config{Host: "::1", Port: 443, UseTLS: true, RPCPath: "/rpc"})
+		assert.Equal(t, "https://[::1]:443/rpc", client.baseURL())
+	})
+}
+
+func TestVerifyTorrents(t *testing.T) {
+	t.Run("sends torrent-verify with ids", func(t *testing.T) {
+		var captured map[string]interface{}
+
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{
+						"X-Transmission-Session-Id": "test-session-id",
+					}), nil
+				}
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(body, &captured))
+
+				return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+			},
+		}
+
+		client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+		err := client.VerifyTorrents(context.Background(), []int{3, 7})
+		require.NoError(t, err)
+
+		assert.Equal(t, "torrent-verify", captured["method"])
+		args := captured["arguments"].(map[string]interface{})
+		assert.Equal(t, []interface{}{float64(3), float64(7)}, args["ids"])
+	})
+
+	t.Run("no ids is a no-op", func(t *testing.T) {
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				t.Fatal("unexpected request")
+				return nil, nil
+			},
+		}
+
+		client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+		assert.NoError(t, client.VerifyTorrents(context.Background(), nil))
+	})
+}
+
+func TestSetTorrentLocation(t *testing.T) {
+	var captured map[string]interface{}
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session-id",
+				}), nil
+			}
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &captured))
+
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+	err := client.SetTorrentLocation(context.Background(), []int{4}, "/media/library/movies", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "torrent-set-location", captured["method"])
+	args := captured["arguments"].(map[string]interface{})
+	assert.Equal(t, []interface{}{float64(4)}, args["ids"])
+	assert.Equal(t, "/media/library/movies", args["location"])
+	assert.Equal(t, true, args["move"])
+}
+
+func TestGetFreeSpace(t *testing.T) {
+	var captured map[string]interface{}
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session-id",
+				}), nil
+			}
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &captured))
+
+			if captured["arguments"].(map[string]interface{})["path"] == "/gone" {
+				return NewMockResponse(200, `{"arguments": {}, "result": "No such file or directory"}`, nil), nil
+			}
+			return NewMockResponse(200, `{"arguments": {"path": "/downloads", "size-bytes": 123456789}, "result": "success"}`, nil), nil
+		},
+	}
+
+	client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+	free, err := client.GetFreeSpace(context.Background(), "/downloads")
+	require.NoError(t, err)
+	assert.Equal(t, int64(123456789), free)
+	assert.Equal(t, "free-space", captured["method"])
+
+	_, err = client.GetFreeSpace(context.Background(), "/gone")
+	assert.ErrorContains(t, err, "No such file or directory")
+}
+
+func TestRemoveTorrents(t *testing.T) {
+	var captured map[string]interface{}
+	requests := 0
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session-id",
+				}), nil
+			}
+
+			requests++
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &captured))
+
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+	t.Run("sends torrent-remove", func(t *testing.T) {
+		err := client.RemoveTorrents(context.Background(), []int{3, 5}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, "torrent-remove", captured["method"])
+		args := captured["arguments"].(map[string]interface{})
+		assert.Equal(t, []interface{}{float64(3), float64(5)}, args["ids"])
+		assert.Equal(t, true, args["delete-local-data"])
+	})
+
+	t.Run("no IDs sends nothing", func(t *testing.T) {
+		requests = 0
+		require.NoError(t, client.RemoveTorrents(context.Background(), nil, false))
+		assert.Equal(t, 0, requests)
+	})
+}
+
+func TestProxyAuth(t *testing.T) {
+	t.Run("sends proxy and daemon credentials in separate headers", func(t *testing.T) {
+		var requests []*http.Request
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req)
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "s"}), nil
+				}
+				return NewMockResponse(200, `{"arguments": {"torrents": []}, "result": "success"}`, nil), nil
+			},
+		}
+
+		config := types.Config{
+			Host: "localhost", Port: 9091,
+			User: "admin", Password: "daemonpw",
+			ProxyUser: "seedbox", ProxyPassword: "proxypw",
+		}
+		client := NewTransmissionClientWithHTTPClient(config, mockHTTP)
+
+		_, err := client.GetTorrents(context.Background())
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+
+		for _, req := range requests {
+			user, password, ok := req.BasicAuth()
+			require.True(t, ok)
+			assert.Equal(t, "admin", user)
+			assert.Equal(t, "daemonpw", password)
+			assert.Equal(t, "Basic c2VlZGJveDpwcm94eXB3", req.Header.Get("Proxy-Authorization"))
+		}
+	})
+
+	t.Run("custom header", func(t *testing.T) {
+		var header string
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				header = req.Header.Get("X-Proxy-Auth")
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "s"}), nil
+			},
+		}
+
+		config := types.Config{Host: "localhost", Port: 9091, ProxyUser: "seedbox", ProxyPassword: "proxypw", ProxyAuthHeader: "X-Proxy-Auth"}
+		client := NewTransmissionClientWithHTTPClient(config, mockHTTP)
+
+		_, err := client.getSessionID(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Basic c2VlZGJveDpwcm94eXB3", header)
+	})
+}
+
+func TestSessionExpiry(t *testing.T) {
+	t.Run("retries with the session ID from the 409 response", func(t *testing.T) {
+		current := "first"
+		var seen []string
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				sent := req.Header.Get("X-Transmission-Session-Id")
+				seen = append(seen, sent)
+				if sent != current {
+					return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": current}), nil
+				}
+				return NewMockResponse(200, `{"arguments": {"download-dir": "/downloads"}, "result": "success"}`, nil), nil
+			},
+		}
+
+		client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+		_, err := client.GetSessionInfo(context.Background())
+		require.NoError(t, err)
+
+		// The daemon restarts between calls and hands out a new session
+		current = "second"
+		_, _, err = client.GetSessionStats(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"", "first", "first", "second"}, seen)
+	})
+
+	t.Run("gives up when every session ID is rejected", func(t *testing.T) {
+		calls := 0
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				calls++
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": fmt.Sprintf("id-%d", calls)}), nil
+			},
+		}
+
+		client := NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP)
+
+		_, err := client.GetTorrents(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "session ID rejected")
+		assert.Equal(t, 1+constants.MaxSessionRetries+1, calls)
+	})
 }
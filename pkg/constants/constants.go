@@ -7,9 +7,49 @@ const (
 	// Default transmission port
 	DefaultPort = 9091
 
-	// HTTP timeout duration
+	// Default transmission RPC endpoint path
+	DefaultRPCPath = "/transmission/rpc"
+
+	// Default qBittorrent Web UI port
+	DefaultQBittorrentPort = 8080
+
+	// Torrent client backends selectable with --client
+	ClientTransmission = "transmission"
+	ClientQBittorrent  = "qbittorrent"
+
+	// State and history storage backends selectable in the config file
+	StorageFile   = "file"
+	StorageBolt   = "bolt"
+	StorageSQLite = "sqlite"
+
+	// Database files of the bolt and sqlite backends in the user state directory
+	BoltFileName   = "state.db"
+	SQLiteFileName = "state.sqlite"
+
+	// Time to wait for another process holding the state database
+	StoreLockTimeout = 5 * time.Second
+
+	// Header carrying reverse proxy credentials unless configured otherwise
+	DefaultProxyAuthHeader = "Proxy-Authorization"
+
+	// Time allowed for one HTTP request unless --timeout gives another
 	HTTPTimeout = 30 * time.Second
 
+	// Time allowed to connect to the RPC endpoint unless --connect-timeout gives another
+	ConnectTimeout = 30 * time.Second
+
+	// Interval of TCP keep-alive probes on RPC connections
+	HTTPKeepAlive = 30 * time.Second
+
+	// Host named in RPC requests sent through a Unix domain socket
+	SocketHost = "localhost"
+
+	// Retries of an RPC call whose session ID expired mid-run
+	MaxSessionRetries = 3
+
+	// Sampling interval used to detect stalled verification
+	DefaultStuckVerifyInterval = 30 * time.Second
+
 	// Port range limits
 	MinPort = 1
 	MaxPort = 65535
@@ -23,13 +63,165 @@ const (
 	BytesPerGB = 1024 * 1024 * 1024
 	BytesPerTB = 1024 * 1024 * 1024 * 1024
 	BytesPerPB = 1024 * 1024 * 1024 * 1024 * 1024
+
+	// Directory entries read per batch when scanning, bounding memory use
+	DirScanBatchSize = 1000
+
+	// Torrents requested per torrent-get call, bounding response size and time
+	TorrentFetchChunkSize = 1000
+
+	// Scanned entries between progress log messages for large directories
+	ScanLogInterval = 10000
+
+	// Upper bounds of the age buckets shown by status
+	AgeBucketWeek     = 7 * 24 * time.Hour
+	AgeBucketMonth    = 30 * 24 * time.Hour
+	AgeBucketHalfYear = 180 * 24 * time.Hour
+
+	// Deletions at least this large require typing a confirmation phrase
+	LargeDeletionThreshold = 100 * BytesPerGB
+
+	// Paths with fewer components than this are never deleted, e.g. /mnt or /media
+	DefaultMinDeleteDepth = 2
+
+	// Staged deletion batches older than this are purged by the next staged
+	// deletion unless --purge-after says otherwise
+	DefaultPurgeAfter = "7d"
+
+	// Directory at the root of a filesystem holding the staged items of that
+	// filesystem when the staging directory is elsewhere; check never reports it
+	StagingDirName = ".peerless-staging"
+
+	// Volume trashes holding items of the XDG trash, one per line, in the
+	// state directory
+	TrashVolumesFileName = "trash-volumes"
+
+	// Directory levels relink searches below each data root by default
+	DefaultRelinkDepth = 4
+
+	// Nice value applied by --background, the lowest scheduling priority
+	BackgroundNice = 19
+
+	// SMTP submission port used when notify.email sets none
+	DefaultSMTPPort = 587
+
+	// Missing paths listed in a notification email before the rest are elided
+	NotifyMaxPaths = 200
+
+	// Longest message Discord webhooks accept, in characters
+	DiscordMessageLimit = 2000
+
+	// Time layout inserted into output file names by --output-mode timestamped
+	OutputTimestampFormat = "20060102-150405"
+
+	// File name prefix of crash reports written by --report-crash
+	CrashReportPrefix = "crash-"
+
+	// Transfer counter samples kept by the quota command, next to the config file
+	QuotaHistoryFileName = "quota-history.json"
+
+	// Age after which quota samples are dropped, covering at least one full billing period
+	QuotaHistoryRetention = 62 * 24 * time.Hour
+
+	// Check results kept in the archive unless archive.keep sets otherwise
+	DefaultArchiveKeep = 30
+
+	// Share of the data cap at which quota warns, in percent
+	DefaultQuotaWarnPercent = 90
+
+	// Interval between quota checks in watch mode unless --watch gives one
+	DefaultQuotaWatchInterval = 5 * time.Minute
+
+	// Interval between checks of the watch command unless --interval gives one
+	DefaultWatchInterval = time.Hour
+
+	// Interval between polls of the wait command unless --interval gives one
+	DefaultWaitInterval = 30 * time.Second
+
+	// Quiet time after the last change to a directory before watch --on-change
+	// re-checks it, so a download being moved in triggers one check
+	WatchDebounce = 5 * time.Second
+
+	// Config file location, relative to the user config directory
+	ConfigDirName  = "peerless"
+	ConfigFileName = "config.yaml"
+)
+
+// Environment variables overriding the config file's connection settings
+const (
+	EnvServer        = "PEERLESS_SERVER"
+	EnvHost          = "PEERLESS_HOST"
+	EnvPort          = "PEERLESS_PORT"
+	EnvUser          = "PEERLESS_USER"
+	EnvPassword      = "PEERLESS_PASSWORD"
+	EnvProxyUser     = "PEERLESS_PROXY_USER"
+	EnvProxyPassword = "PEERLESS_PROXY_PASSWORD"
+	EnvSocket        = "PEERLESS_SOCKET"
+
+	// EnvDirs lists default directories separated by the OS path list separator
+	EnvDirs = "PEERLESS_DIRS"
+)
+
+// Exit statuses, documented in the README for scripts and cron jobs
+const (
+	// check found missing items and --fail-on-missing was given
+	ExitMissing = 1
+
+	// The torrent client could not be reached or rejected the credentials
+	ExitConnection = 2
+
+	// Invalid flags, configuration or directories
+	ExitValidation = 3
+
+	// check could not check some directories; the others were handled
+	ExitPartialFailure = 4
+
+	// Any other error
+	ExitError = 5
+
+	// Crash saved by --report-crash
+	ExitCrash = 6
 )
 
 // Display constants
 const (
+	// Environment variable turning off colors when set to any non-empty value
+	// (https://no-color.org)
+	EnvNoColor = "NO_COLOR"
+
 	// Separator width for terminal output
 	SeparatorWidth = 80
 
+	// Minimum interval between machine-readable progress events
+	ProgressInterval = 250 * time.Millisecond
+
+	// Minimum interval between redraws of the live scan progress bar
+	ProgressBarInterval = 100 * time.Millisecond
+
+	// Width of the live scan progress bar, including its percentage
+	ProgressBarWidth = 30
+
+	// Maximum width of the live scan progress line; longer paths are cut off
+	ProgressLineWidth = 120
+
+	// Default number of rows shown by the top command
+	DefaultTopLimit = 20
+
+	// Seed-time goal of stats seedtime when neither --goal nor the config file sets one
+	DefaultSeedGoal = 14 * 24 * time.Hour
+
+	// Default number of runs for the bench command
+	DefaultBenchIterations = 5
+
+	// Maximum bar width for textual histograms
+	HistogramWidth = 50
+
+	// Label used for torrents without any Transmission label
+	UnlabeledName = "(unlabeled)"
+
+	// Tracker name used for torrents without any announce URL
+	NoTrackerName = "(no tracker)"
+
 	// File size unit names
 	SizeUnits = "KBMBGBTBPB"
 )
@@ -0,0 +1,157 @@
+// Package crash writes the bundle that --report-crash saves when peerless
+// panics: the panic and stack, versions, command line and config file, with
+// credentials removed so it can be attached to a public bug report.
+package crash
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle is everything a crash report holds
+type Bundle struct {
+	Time    time.Time
+	Panic   any
+	Stack   []byte
+	Version string
+	Commit  string
+	Date    string
+
+	// Args is the command line; values of secret flags are redacted by Write
+	Args []string
+
+	// ConfigPath and Config are the config file and its contents; Config is
+	// nil when there is none. Secret settings are redacted by Write.
+	ConfigPath string
+	Config     []byte
+}
+
+// secretFlags take a credential or token as value
+var secretFlags = map[string]bool{
+	"password":          true,
+	"p":                 true,
+	"proxy-password":    true,
+	"proxy-auth-header": true,
+	"webhook-url":       true,
+}
+
+// secretKeys are config file settings holding a credential or token
+var secretKeys = map[string]bool{
+	"password":       true,
+	"proxy-password": true,
+	"url":            true,
+}
+
+// Write saves b as a new crash-<timestamp>.txt file in dir, readable only by
+// the user, and returns its path
+func Write(dir string, b Bundle) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s%s.txt", constants.CrashReportPrefix, b.Time.Format(constants.OutputTimestampFormat)))
+	if err := os.WriteFile(path, []byte(b.Render()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// Render formats b as the text of a crash report with secrets redacted
+func (b Bundle) Render() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "peerless crash report, %s\n\n", b.Time.Format(time.RFC3339))
+	fmt.Fprintf(&s, "version: %s\ncommit:  %s\nbuilt:   %s\n", b.Version, b.Commit, b.Date)
+	fmt.Fprintf(&s, "go:      %s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&s, "command: %s\n\n", strings.Join(RedactArgs(b.Args), " "))
+	fmt.Fprintf(&s, "panic: %v\n\n%s\n", b.Panic, b.Stack)
+
+	switch config, err := RedactConfig(b.Config); {
+	case b.Config == nil:
+		s.WriteString("config: none\n")
+	case err != nil:
+		fmt.Fprintf(&s, "config: %s (unreadable, omitted: %v)\n", b.ConfigPath, err)
+	default:
+		fmt.Fprintf(&s, "config: %s\n\n%s", b.ConfigPath, config)
+	}
+	return utils.Redact(s.String())
+}
+
+// RedactArgs returns args with the values of secret flags replaced, given as
+// --flag value or --flag=value
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !secretFlags[name] {
+			continue
+		}
+		if hasValue {
+			redacted[i] = arg[:strings.Index(arg, "=")+1] + utils.RedactedPlaceholder
+		} else if i+1 < len(redacted) {
+			i++
+			redacted[i] = utils.RedactedPlaceholder
+		}
+	}
+	return redacted
+}
+
+// RedactConfig returns the YAML config file data with secret settings
+// replaced. URLs keep their scheme and host, which often tells what went wrong.
+func RedactConfig(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	redactNode(&doc)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// redactNode replaces the values of secretKeys in every mapping below n
+func redactNode(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if secretKeys[key.Value] && value.Kind == yaml.ScalarNode && value.Value != "" {
+				value.Value = redactValue(key.Value, value.Value)
+				value.Style = 0
+			}
+		}
+	}
+	for _, child := range n.Content {
+		redactNode(child)
+	}
+}
+
+// redactValue returns the replacement of a secret setting
+func redactValue(key, value string) string {
+	if key != "url" {
+		return utils.RedactedPlaceholder
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return utils.RedactedPlaceholder
+	}
+	return u.Scheme + "://" + u.Host + "/" + utils.RedactedPlaceholder
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	stderrors "errors"
+	"net"
+	"strings"
+)
+
+// classifyNetworkError determines the kind of a transport-level failure and a message for it
+func classifyNetworkError(err error) (Kind, string) {
+	if err == nil {
+		return KindConnection, "unknown error"
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var unknownAuthErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+
+	switch {
+	case stderrors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		return KindProxy, "proxy connection failed"
+	case stderrors.As(err, &dnsErr):
+		return KindDNS, "DNS lookup failed"
+	case stderrors.As(err, &certErr),
+		stderrors.As(err, &recordErr),
+		stderrors.As(err, &alertErr),
+		stderrors.As(err, &unknownAuthErr),
+		stderrors.As(err, &hostnameErr),
+		stderrors.As(err, &invalidCertErr):
+		return KindTLS, "TLS handshake failed"
+	case stderrors.Is(err, context.DeadlineExceeded),
+		stderrors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout, "request timed out"
+	case strings.Contains(err.Error(), "proxyconnect"):
+		return KindProxy, "proxy connection failed"
+	default:
+		return KindConnection, "connection failed"
+	}
+}
@@ -1,10 +1,82 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
 )
 
+// Kind classifies a TransmissionError
+type Kind int
+
+// Known error kinds
+const (
+	KindUnknown Kind = iota
+	KindHTTP
+	KindAuthentication
+	KindConnection
+	KindTimeout
+	KindDNS
+	KindTLS
+	KindProxy
+	KindProtocol
+)
+
+// Sentinel errors matched by TransmissionError through errors.Is
+var (
+	ErrAuthentication = stderrors.New("authentication failed")
+	ErrConnection     = stderrors.New("connection failed")
+	ErrTimeout        = stderrors.New("request timed out")
+	ErrDNS            = stderrors.New("DNS lookup failed")
+	ErrTLS            = stderrors.New("TLS handshake failed")
+	ErrProxy          = stderrors.New("proxy connection failed")
+	ErrProtocol       = stderrors.New("RPC protocol error")
+)
+
+var kindSentinels = map[Kind]error{
+	KindAuthentication: ErrAuthentication,
+	KindConnection:     ErrConnection,
+	KindTimeout:        ErrTimeout,
+	KindDNS:            ErrDNS,
+	KindTLS:            ErrTLS,
+	KindProxy:          ErrProxy,
+	KindProtocol:       ErrProtocol,
+}
+
+// String returns the kind name
+func (k Kind) String() string {
+	switch k {
+	case KindHTTP:
+		return "http"
+	case KindAuthentication:
+		return "authentication"
+	case KindConnection:
+		return "connection"
+	case KindTimeout:
+		return "timeout"
+	case KindDNS:
+		return "dns"
+	case KindTLS:
+		return "tls"
+	case KindProxy:
+		return "proxy"
+	case KindProtocol:
+		return "protocol"
+	default:
+		return "unknown"
+	}
+}
+
+// IsNetwork reports whether the kind means the RPC endpoint could not be reached
+func (k Kind) IsNetwork() bool {
+	switch k {
+	case KindConnection, KindTimeout, KindDNS, KindTLS, KindProxy:
+		return true
+	default:
+		return false
+	}
+}
+
 // TransmissionError represents an error from the Transmission RPC API
 type TransmissionError struct {
 	StatusCode int
@@ -12,6 +84,7 @@ type TransmissionError struct {
 	Port       int
 	Message    string
 	Err        error
+	Kind       Kind
 }
 
 func (e *TransmissionError) Error() string {
@@ -25,12 +98,31 @@ func (e *TransmissionError) Unwrap() error {
 	return e.Err
 }
 
+// Is matches the sentinel error for the error's kind, e.g. errors.Is(err, ErrTimeout)
+func (e *TransmissionError) Is(target error) bool {
+	sentinel, ok := kindSentinels[e.Kind]
+	return ok && sentinel == target
+}
+
 // NewTransmissionError creates a new TransmissionError from HTTP response
 func NewTransmissionError(statusCode int, host string, port int, err error) *TransmissionError {
+	if statusCode == 0 {
+		kind, message := classifyNetworkError(err)
+		return &TransmissionError{
+			Host:    host,
+			Port:    port,
+			Message: message,
+			Err:     err,
+			Kind:    kind,
+		}
+	}
+
 	var message string
+	kind := KindHTTP
 
 	switch statusCode {
 	case http.StatusUnauthorized:
+		kind = KindAuthentication
 		message = "authentication failed: invalid username or password"
 	case http.StatusForbidden:
 		message = "access forbidden: insufficient permissions"
@@ -54,21 +146,60 @@ func NewTransmissionError(statusCode int, host string, port int, err error) *Tra
 		Port:       port,
 		Message:    message,
 		Err:        err,
+		Kind:       kind,
+	}
+}
+
+// NewProtocolError creates a TransmissionError for a malformed or unsuccessful RPC exchange
+func NewProtocolError(host string, port int, message string, err error) *TransmissionError {
+	return &TransmissionError{
+		Host:    host,
+		Port:    port,
+		Message: message,
+		Err:     err,
+		Kind:    KindProtocol,
 	}
 }
 
 // IsAuthenticationError checks if the error is authentication failure
 func IsAuthenticationError(err error) bool {
-	if te, ok := err.(*TransmissionError); ok {
-		return te.StatusCode == http.StatusUnauthorized
-	}
-	return false
+	return stderrors.Is(err, ErrAuthentication)
 }
 
-// IsConnectionError checks if the error is connection failure
+// IsConnectionError checks if the error is a failure to reach Transmission,
+// including timeouts, DNS, TLS and proxy failures
 func IsConnectionError(err error) bool {
-	if te, ok := err.(*TransmissionError); ok {
-		return te.StatusCode == 0
+	var te *TransmissionError
+	return stderrors.As(err, &te) && te.Kind.IsNetwork()
+}
+
+// KindOf returns the kind of a TransmissionError anywhere in err's chain
+func KindOf(err error) Kind {
+	var te *TransmissionError
+	if stderrors.As(err, &te) {
+		return te.Kind
+	}
+	return KindUnknown
+}
+
+// Hint returns a remediation suggestion for err, or "" when none applies
+func Hint(err error) string {
+	switch KindOf(err) {
+	case KindAuthentication:
+		return "check the username and password configured for the Transmission RPC interface"
+	case KindConnection:
+		return "ensure Transmission is running, its RPC interface is enabled, and the host and port are correct"
+	case KindTimeout:
+		return "Transmission did not respond in time; check network latency or whether the daemon is overloaded, or raise --timeout"
+	case KindDNS:
+		return "the host name could not be resolved; check for typos or use an IP address"
+	case KindTLS:
+		return "the TLS handshake failed; check the certificate, or use http:// if the endpoint does not serve TLS"
+	case KindProxy:
+		return "the HTTP proxy could not be reached; check HTTP_PROXY/HTTPS_PROXY or set NO_PROXY for this host"
+	case KindProtocol:
+		return "the endpoint did not behave like Transmission RPC; check the RPC path and any reverse proxy in between"
+	default:
+		return ""
 	}
-	return false
 }
@@ -1,7 +1,13 @@
 package errors
 
 import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -165,3 +171,79 @@ func TestIsConnectionError(t *testing.T) {
 		assert.False(t, IsConnectionError(err))
 	})
 }
+
+func TestNetworkErrorClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		kind     Kind
+		sentinel error
+	}{
+		{
+			name:     "dns",
+			err:      &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x"}}},
+			kind:     KindDNS,
+			sentinel: ErrDNS,
+		},
+		{
+			name:     "timeout",
+			err:      &url.Error{Op: "Post", URL: "http://x", Err: context.DeadlineExceeded},
+			kind:     KindTimeout,
+			sentinel: ErrTimeout,
+		},
+		{
+			name:     "tls",
+			err:      &url.Error{Op: "Post", URL: "https://x", Err: x509.UnknownAuthorityError{}},
+			kind:     KindTLS,
+			sentinel: ErrTLS,
+		},
+		{
+			name:     "proxy",
+			err:      &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "proxyconnect", Net: "tcp", Err: syscall.ECONNREFUSED}},
+			kind:     KindProxy,
+			sentinel: ErrProxy,
+		},
+		{
+			name:     "refused",
+			err:      &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+			kind:     KindConnection,
+			sentinel: ErrConnection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", NewTransmissionError(0, "localhost", 9091, tt.err))
+
+			assert.Equal(t, tt.kind, KindOf(err))
+			assert.ErrorIs(t, err, tt.sentinel)
+			assert.True(t, IsConnectionError(err))
+			assert.False(t, IsAuthenticationError(err))
+			assert.NotEmpty(t, Hint(err))
+
+			var te *TransmissionError
+			assert.ErrorAs(t, err, &te)
+		})
+	}
+}
+
+func TestProtocolError(t *testing.T) {
+	err := NewProtocolError("localhost", 9091, "failed to parse JSON response", assert.AnError)
+
+	assert.ErrorIs(t, err, ErrProtocol)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.False(t, IsConnectionError(err))
+	assert.Equal(t, KindProtocol, KindOf(err))
+	assert.Contains(t, err.Error(), "failed to parse JSON response at localhost:9091")
+}
+
+func TestHTTPErrorKinds(t *testing.T) {
+	auth := NewTransmissionError(http.StatusUnauthorized, "localhost", 9091, nil)
+	assert.ErrorIs(t, auth, ErrAuthentication)
+	assert.Equal(t, KindAuthentication, KindOf(auth))
+
+	notFound := NewTransmissionError(http.StatusNotFound, "localhost", 9091, nil)
+	assert.Equal(t, KindHTTP, KindOf(notFound))
+	assert.NotErrorIs(t, notFound, ErrAuthentication)
+	assert.Empty(t, Hint(notFound))
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is used when no supported language is requested
+const DefaultLanguage = "en"
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLanguage
+)
+
+// Supported returns the language codes that have a message catalog
+func Supported() []string {
+	return []string{"en", "de", "fr", "es"}
+}
+
+// Normalize reduces a locale such as "de_DE.UTF-8" to a supported language
+// code, returning DefaultLanguage for unknown or empty locales
+func Normalize(locale string) string {
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Detect picks the language from the standard locale environment variables
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" && value != "C" && value != "POSIX" {
+			return Normalize(value)
+		}
+	}
+	return DefaultLanguage
+}
+
+// SetLanguage selects the language used by T and returns the normalized code
+func SetLanguage(locale string) string {
+	lang := Normalize(locale)
+	mu.Lock()
+	current = lang
+	mu.Unlock()
+	return lang
+}
+
+// Language returns the currently selected language code
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T returns the message for key in the current language, formatted with args.
+// Missing translations fall back to English, then to the key itself.
+func T(key string, args ...any) string {
+	format, ok := catalogs[Language()][key]
+	if !ok {
+		format, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		format = key
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	english := catalogs[DefaultLanguage]
+	for _, lang := range Supported() {
+		catalog, ok := catalogs[lang]
+		if !assert.True(t, ok, "missing catalog for %s", lang) {
+			continue
+		}
+
+		for key, format := range english {
+			translated, ok := catalog[key]
+			if assert.True(t, ok, "%s: missing key %s", lang, key) {
+				assert.Equal(t, verbPattern.FindAllString(format, -1), verbPattern.FindAllString(translated, -1),
+					"%s: format verbs differ for %s", lang, key)
+			}
+		}
+		for key := range catalog {
+			_, ok := english[key]
+			assert.True(t, ok, "%s: key %s not in English catalog", lang, key)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"de_DE.UTF-8": "de",
+		"fr":          "fr",
+		"es-MX":       "es",
+		"EN_us":       "en",
+		"ja_JP.UTF-8": "en",
+		"":            "en",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, Normalize(input), "input %q", input)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	assert.Equal(t, "fr", Detect())
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	assert.Equal(t, "de", Detect())
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, "fr", Detect())
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("de")
+	assert.Equal(t, "3 Torrents in Transmission gefunden", T("check.found_total", 3))
+
+	SetLanguage("en")
+	assert.Equal(t, "Found 3 torrents in Transmission", T("check.found_total", 3))
+	assert.Equal(t, "no.such.key", T("no.such.key"))
+}
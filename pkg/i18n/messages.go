@@ -0,0 +1,221 @@
+package i18n
+
+// catalogs maps language codes to message formats keyed by message ID
+var catalogs = map[string]map[string]string{
+	"en": {
+		"status.torrents":            "Torrents: %d",
+		"status.downloading":         "%s downloading",
+		"status.seeding":             "%s seeding",
+		"status.paused":              "%s paused",
+		"status.completed":           "%s completed",
+		"status.queued":              "%s queued",
+		"status.verifying":           "%s verifying",
+		"status.completed_breakdown": "Completed: %d • %d stopped • %d still seeding",
+		"status.progress":            "Progress: %.1f%% • %s / %s",
+		"status.remaining":           "%s remaining",
+		"status.speed":               "Speed: ",
+		"status.free_space":          "Free Space: %s",
+		"status.disk_quota":          "Quota: %s of %s used (%.1f%%) • %s free",
+		"status.compact_torrents":    "%d torrents",
+		"status.compact_free":        "%s free",
+		"status.compact_quota":       "%s free of %s quota",
+		"status.directories":         "Directories: ",
+		"status.more":                " + %d more",
+		"status.age":                 "Added: %d <7d • %d 7–30d • %d 30–180d • %d >180d",
+		"status.privacy":             "Private: %d (%s) • Public: %d (%s)",
+		"status.space_shortage":      "⚠️  %s: %d downloads still need %s but only %s is free",
+
+		"dryrun.start":    "🔍 DRY RUN MODE - No changes will be made",
+		"dryrun.complete": "🔍 DRY RUN COMPLETED - No changes were made",
+
+		"check.found_total":         "Found %d torrents in Transmission",
+		"check.dir_summary":         "Directory Summary: %d/%d items found in Transmission",
+		"check.still_downloading":   " (%d still downloading)",
+		"check.excluded":            " (%d missing items excluded by age/size)",
+		"check.kept":                " (%d missing items retained by the keep file)",
+		"check.missing_size":        "Missing items total size: ",
+		"check.free_space":          "Free space: %s now • %s after deleting missing items",
+		"check.free_space_staged":   "Free space: %s now • %s once the staged missing items are purged after %s",
+		"check.free_space_trashed":  "Free space: %s now • %s once the trash is emptied",
+		"check.quota_space":         "Quota: %s free now • %s after deleting missing items",
+		"check.quota_space_staged":  "Quota: %s free now • %s once the staged missing items are purged after %s",
+		"check.quota_space_trashed": "Quota: %s free now • %s once the trash is emptied",
+		"check.overall_summary":     "Overall Summary: %d/%d items found in Transmission across %d directories",
+		"check.total_missing_size":  "Total missing items size: ",
+		"check.breakdown":           "Per-Directory Breakdown:",
+		"check.to_delete":           "Files and directories to be deleted:",
+		"check.would_delete":        "Files and directories that WOULD be deleted:",
+		"check.confirm_delete":      "❓ Are you sure you want to delete these files? This action cannot be undone!",
+		"check.deleted":             "✅ Successfully deleted %d items (%s)",
+		"check.trashed":             "✅ Moved %d items to the trash (%s)",
+		"check.staged":              "✅ Staged %d items for deletion (%s)",
+		"check.pruned":              "🧹 Removed %d empty directories",
+		"check.all_deleted":         "🎉 All missing files deleted successfully!",
+		"check.cancelled":           "❌ Deletion cancelled by user",
+		"check.nothing_missing":     "✅ No missing files found - nothing to delete!",
+		"check.failed_dirs":         "%d of %d directories could not be checked",
+		"check.missing_found":       "%d missing items found",
+	},
+	"de": {
+		"status.torrents":            "Torrents: %d",
+		"status.downloading":         "%s im Download",
+		"status.seeding":             "%s im Seeding",
+		"status.paused":              "%s pausiert",
+		"status.completed":           "%s abgeschlossen",
+		"status.queued":              "%s in Warteschlange",
+		"status.verifying":           "%s in Prüfung",
+		"status.completed_breakdown": "Abgeschlossen: %d • %d gestoppt • %d seeden noch",
+		"status.progress":            "Fortschritt: %.1f%% • %s / %s",
+		"status.remaining":           "%s verbleibend",
+		"status.speed":               "Geschwindigkeit: ",
+		"status.free_space":          "Freier Speicher: %s",
+		"status.disk_quota":          "Kontingent: %s von %s belegt (%.1f%%) • %s frei",
+		"status.compact_torrents":    "%d Torrents",
+		"status.compact_free":        "%s frei",
+		"status.compact_quota":       "%s frei von %s Kontingent",
+		"status.directories":         "Verzeichnisse: ",
+		"status.more":                " + %d weitere",
+		"status.age":                 "Hinzugefügt: %d <7 T • %d 7–30 T • %d 30–180 T • %d >180 T",
+		"status.privacy":             "Privat: %d (%s) • Öffentlich: %d (%s)",
+		"status.space_shortage":      "⚠️  %s: %d Downloads benötigen noch %s, frei sind nur %s",
+
+		"dryrun.start":    "🔍 TESTLAUF - Es werden keine Änderungen vorgenommen",
+		"dryrun.complete": "🔍 TESTLAUF ABGESCHLOSSEN - Es wurden keine Änderungen vorgenommen",
+
+		"check.found_total":         "%d Torrents in Transmission gefunden",
+		"check.dir_summary":         "Verzeichnis-Zusammenfassung: %d/%d Einträge in Transmission gefunden",
+		"check.still_downloading":   " (%d werden noch heruntergeladen)",
+		"check.excluded":            " (%d fehlende Einträge nach Alter/Größe ausgeschlossen)",
+		"check.kept":                " (%d fehlende Einträge durch die Keep-Datei behalten)",
+		"check.missing_size":        "Gesamtgröße fehlender Einträge: ",
+		"check.free_space":          "Freier Speicher: %s jetzt • %s nach dem Löschen fehlender Einträge",
+		"check.free_space_staged":   "Freier Speicher: %s jetzt • %s sobald die vorgemerkten fehlenden Einträge nach %s gelöscht sind",
+		"check.free_space_trashed":  "Freier Speicher: %s jetzt • %s nach dem Leeren des Papierkorbs",
+		"check.quota_space":         "Kontingent: %s jetzt frei • %s nach dem Löschen fehlender Einträge",
+		"check.quota_space_staged":  "Kontingent: %s jetzt frei • %s sobald die vorgemerkten fehlenden Einträge nach %s gelöscht sind",
+		"check.quota_space_trashed": "Kontingent: %s jetzt frei • %s nach dem Leeren des Papierkorbs",
+		"check.overall_summary":     "Gesamtübersicht: %d/%d Einträge in Transmission gefunden, %d Verzeichnisse",
+		"check.total_missing_size":  "Gesamtgröße aller fehlenden Einträge: ",
+		"check.breakdown":           "Aufschlüsselung nach Verzeichnis:",
+		"check.to_delete":           "Zu löschende Dateien und Verzeichnisse:",
+		"check.would_delete":        "Dateien und Verzeichnisse, die gelöscht WÜRDEN:",
+		"check.confirm_delete":      "❓ Sollen diese Dateien wirklich gelöscht werden? Dies kann nicht rückgängig gemacht werden!",
+		"check.deleted":             "✅ %d Einträge erfolgreich gelöscht (%s)",
+		"check.trashed":             "✅ %d Einträge in den Papierkorb verschoben (%s)",
+		"check.staged":              "✅ %d Einträge zum Löschen vorgemerkt (%s)",
+		"check.pruned":              "🧹 %d leere Verzeichnisse entfernt",
+		"check.all_deleted":         "🎉 Alle fehlenden Dateien erfolgreich gelöscht!",
+		"check.cancelled":           "❌ Löschen vom Benutzer abgebrochen",
+		"check.nothing_missing":     "✅ Keine fehlenden Dateien gefunden - nichts zu löschen!",
+		"check.failed_dirs":         "%d von %d Verzeichnissen konnten nicht geprüft werden",
+		"check.missing_found":       "%d fehlende Einträge gefunden",
+	},
+	"fr": {
+		"status.torrents":            "Torrents : %d",
+		"status.downloading":         "%s en téléchargement",
+		"status.seeding":             "%s en partage",
+		"status.paused":              "%s en pause",
+		"status.completed":           "%s terminés",
+		"status.queued":              "%s en file d'attente",
+		"status.verifying":           "%s en vérification",
+		"status.completed_breakdown": "Terminés : %d • %d arrêtés • %d encore en partage",
+		"status.progress":            "Progression : %.1f%% • %s / %s",
+		"status.remaining":           "%s restants",
+		"status.speed":               "Vitesse : ",
+		"status.free_space":          "Espace libre : %s",
+		"status.disk_quota":          "Quota : %s sur %s utilisés (%.1f%%) • %s libres",
+		"status.compact_torrents":    "%d torrents",
+		"status.compact_free":        "%s libres",
+		"status.compact_quota":       "%s libres sur un quota de %s",
+		"status.directories":         "Répertoires : ",
+		"status.more":                " + %d autres",
+		"status.age":                 "Ajoutés : %d <7 j • %d 7–30 j • %d 30–180 j • %d >180 j",
+		"status.privacy":             "Privés : %d (%s) • Publics : %d (%s)",
+		"status.space_shortage":      "⚠️  %s : %d téléchargements ont encore besoin de %s mais seuls %s sont libres",
+
+		"dryrun.start":    "🔍 SIMULATION - Aucune modification ne sera effectuée",
+		"dryrun.complete": "🔍 SIMULATION TERMINÉE - Aucune modification n'a été effectuée",
+
+		"check.found_total":         "%d torrents trouvés dans Transmission",
+		"check.dir_summary":         "Résumé du répertoire : %d/%d éléments trouvés dans Transmission",
+		"check.still_downloading":   " (%d encore en téléchargement)",
+		"check.excluded":            " (%d éléments manquants exclus par âge/taille)",
+		"check.kept":                " (%d éléments manquants conservés par le fichier keep)",
+		"check.missing_size":        "Taille totale des éléments manquants : ",
+		"check.free_space":          "Espace libre : %s maintenant • %s après suppression des éléments manquants",
+		"check.free_space_staged":   "Espace libre : %s maintenant • %s une fois les éléments manquants en attente purgés au bout de %s",
+		"check.free_space_trashed":  "Espace libre : %s maintenant • %s une fois la corbeille vidée",
+		"check.quota_space":         "Quota : %s libres maintenant • %s après suppression des éléments manquants",
+		"check.quota_space_staged":  "Quota : %s libres maintenant • %s une fois les éléments manquants en attente purgés au bout de %s",
+		"check.quota_space_trashed": "Quota : %s libres maintenant • %s une fois la corbeille vidée",
+		"check.overall_summary":     "Résumé global : %d/%d éléments trouvés dans Transmission sur %d répertoires",
+		"check.total_missing_size":  "Taille totale de tous les éléments manquants : ",
+		"check.breakdown":           "Détail par répertoire :",
+		"check.to_delete":           "Fichiers et répertoires à supprimer :",
+		"check.would_delete":        "Fichiers et répertoires qui SERAIENT supprimés :",
+		"check.confirm_delete":      "❓ Voulez-vous vraiment supprimer ces fichiers ? Cette action est irréversible !",
+		"check.deleted":             "✅ %d éléments supprimés avec succès (%s)",
+		"check.trashed":             "✅ %d éléments déplacés vers la corbeille (%s)",
+		"check.staged":              "✅ %d éléments mis en attente de suppression (%s)",
+		"check.pruned":              "🧹 %d répertoires vides supprimés",
+		"check.all_deleted":         "🎉 Tous les fichiers manquants ont été supprimés !",
+		"check.cancelled":           "❌ Suppression annulée par l'utilisateur",
+		"check.nothing_missing":     "✅ Aucun fichier manquant - rien à supprimer !",
+		"check.failed_dirs":         "%d répertoires sur %d n'ont pas pu être vérifiés",
+		"check.missing_found":       "%d éléments manquants trouvés",
+	},
+	"es": {
+		"status.torrents":            "Torrents: %d",
+		"status.downloading":         "%s descargando",
+		"status.seeding":             "%s compartiendo",
+		"status.paused":              "%s en pausa",
+		"status.completed":           "%s completados",
+		"status.queued":              "%s en cola",
+		"status.verifying":           "%s verificando",
+		"status.completed_breakdown": "Completados: %d • %d detenidos • %d aún compartiendo",
+		"status.progress":            "Progreso: %.1f%% • %s / %s",
+		"status.remaining":           "%s restantes",
+		"status.speed":               "Velocidad: ",
+		"status.free_space":          "Espacio libre: %s",
+		"status.disk_quota":          "Cuota: %s de %s usados (%.1f%%) • %s libres",
+		"status.compact_torrents":    "%d torrents",
+		"status.compact_free":        "%s libres",
+		"status.compact_quota":       "%s libres de una cuota de %s",
+		"status.directories":         "Directorios: ",
+		"status.more":                " + %d más",
+		"status.age":                 "Añadidos: %d <7 d • %d 7–30 d • %d 30–180 d • %d >180 d",
+		"status.privacy":             "Privados: %d (%s) • Públicos: %d (%s)",
+		"status.space_shortage":      "⚠️  %s: %d descargas aún necesitan %s pero solo hay %s libres",
+
+		"dryrun.start":    "🔍 MODO SIMULACIÓN - No se realizarán cambios",
+		"dryrun.complete": "🔍 SIMULACIÓN COMPLETADA - No se realizaron cambios",
+
+		"check.found_total":         "Se encontraron %d torrents en Transmission",
+		"check.dir_summary":         "Resumen del directorio: %d/%d elementos encontrados en Transmission",
+		"check.still_downloading":   " (%d aún descargando)",
+		"check.excluded":            " (%d elementos faltantes excluidos por antigüedad/tamaño)",
+		"check.kept":                " (%d elementos faltantes conservados por el archivo keep)",
+		"check.missing_size":        "Tamaño total de los elementos faltantes: ",
+		"check.free_space":          "Espacio libre: %s ahora • %s tras borrar los elementos que faltan",
+		"check.free_space_staged":   "Espacio libre: %s ahora • %s cuando se purguen los elementos preparados tras %s",
+		"check.free_space_trashed":  "Espacio libre: %s ahora • %s al vaciar la papelera",
+		"check.quota_space":         "Cuota: %s libres ahora • %s tras borrar los elementos que faltan",
+		"check.quota_space_staged":  "Cuota: %s libres ahora • %s cuando se purguen los elementos preparados tras %s",
+		"check.quota_space_trashed": "Cuota: %s libres ahora • %s al vaciar la papelera",
+		"check.overall_summary":     "Resumen general: %d/%d elementos encontrados en Transmission en %d directorios",
+		"check.total_missing_size":  "Tamaño total de todos los elementos faltantes: ",
+		"check.breakdown":           "Desglose por directorio:",
+		"check.to_delete":           "Archivos y directorios a eliminar:",
+		"check.would_delete":        "Archivos y directorios que SE ELIMINARÍAN:",
+		"check.confirm_delete":      "❓ ¿Seguro que desea eliminar estos archivos? ¡Esta acción no se puede deshacer!",
+		"check.deleted":             "✅ %d elementos eliminados correctamente (%s)",
+		"check.trashed":             "✅ %d elementos movidos a la papelera (%s)",
+		"check.staged":              "✅ %d elementos preparados para su eliminación (%s)",
+		"check.pruned":              "🧹 %d directorios vacíos eliminados",
+		"check.all_deleted":         "🎉 ¡Todos los archivos faltantes se eliminaron correctamente!",
+		"check.cancelled":           "❌ Eliminación cancelada por el usuario",
+		"check.nothing_missing":     "✅ No se encontraron archivos faltantes - ¡nada que eliminar!",
+		"check.failed_dirs":         "No se pudieron comprobar %d de %d directorios",
+		"check.missing_found":       "Se encontraron %d elementos que faltan",
+	},
+}
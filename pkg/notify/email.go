@@ -0,0 +1,56 @@
+// Package notify delivers check results and finished downloads to the user after a run
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+)
+
+// sendMail delivers a message; replaced in tests
+var sendMail = smtp.SendMail
+
+// SendEmail sends a plain text message to the recipients in cfg. The
+// connection is upgraded with STARTTLS when the server offers it, and
+// credentials are only sent over an encrypted connection or to localhost.
+func SendEmail(cfg types.EmailConfig, subject, body string) error {
+	port := cfg.Port
+	if port == 0 {
+		port = constants.DefaultSMTPPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if cfg.User != "" {
+		auth = smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
+	}
+
+	msg := buildMessage(cfg.From, cfg.To, subject, body, time.Now())
+	if err := sendMail(addr, auth, cfg.From, cfg.To, msg); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// buildMessage formats an RFC 5322 message with CRLF line endings
+func buildMessage(from string, to []string, subject, body string, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/types"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC)
+	msg := string(buildMessage("peerless@nas", []string{"a@example.com", "b@example.com"},
+		"peerless check on nas: 2 missing", "line one\nline two\n", date))
+
+	headers, body, found := strings.Cut(msg, "\r\n\r\n")
+	require.True(t, found)
+	assert.Contains(t, headers, "From: peerless@nas\r\n")
+	assert.Contains(t, headers, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, headers, "Subject: peerless check on nas: 2 missing\r\n")
+	assert.Contains(t, headers, "Date: Sun, 01 Mar 2026 04:30:00 +0000")
+	assert.Equal(t, "line one\r\nline two\r\n", body)
+}
+
+func TestSendEmail(t *testing.T) {
+	original := sendMail
+	t.Cleanup(func() { sendMail = original })
+
+	t.Run("uses the default port and authenticates when a user is set", func(t *testing.T) {
+		var gotAddr, gotFrom string
+		var gotTo []string
+		var gotAuth smtp.Auth
+		sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAddr, gotAuth, gotFrom, gotTo = addr, a, from, to
+			return nil
+		}
+
+		cfg := types.EmailConfig{Host: "smtp.example.com", User: "me", Password: "secret", From: "peerless@nas", To: []string{"me@example.com"}}
+		require.NoError(t, SendEmail(cfg, "subject", "body"))
+		assert.Equal(t, "smtp.example.com:587", gotAddr)
+		assert.NotNil(t, gotAuth)
+		assert.Equal(t, "peerless@nas", gotFrom)
+		assert.Equal(t, []string{"me@example.com"}, gotTo)
+	})
+
+	t.Run("no auth without a user", func(t *testing.T) {
+		var gotAuth smtp.Auth
+		sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAuth = a
+			return nil
+		}
+
+		cfg := types.EmailConfig{Host: "localhost", Port: 25, From: "peerless@nas", To: []string{"root"}}
+		require.NoError(t, SendEmail(cfg, "subject", "body"))
+		assert.Nil(t, gotAuth)
+	})
+
+	t.Run("delivery errors are wrapped", func(t *testing.T) {
+		sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			return errors.New("connection refused")
+		}
+
+		cfg := types.EmailConfig{Host: "localhost", Port: 25, From: "peerless@nas", To: []string{"root"}}
+		err := SendEmail(cfg, "subject", "body")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "localhost:25")
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+}
@@ -0,0 +1,234 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// Message is a notification a webhook delivers: chat webhooks post its Text,
+// generic JSON webhooks the message itself. Templates receive it as their data.
+type Message interface {
+	Text() string
+}
+
+// Summary is what a webhook reports about a check or watch run. Templates
+// receive it as their data, e.g. {{.Host}}, {{.Missing}} or {{.MissingSizeText}}.
+type Summary struct {
+	Time        time.Time `json:"time"`
+	Host        string    `json:"host"`
+	Dirs        []string  `json:"dirs"`
+	Missing     int       `json:"missing"`
+	MissingSize int64     `json:"missing_size"`
+
+	// MissingPaths lists at most constants.NotifyMaxPaths of the missing paths
+	MissingPaths []string `json:"missing_paths"`
+
+	Deleted    int   `json:"deleted"`
+	FreedBytes int64 `json:"freed_bytes"`
+
+	// Action describes what was done with the missing items
+	Action string `json:"action"`
+}
+
+// MissingSizeText returns the missing size formatted for display
+func (s Summary) MissingSizeText() string {
+	return utils.FormatSize(s.MissingSize)
+}
+
+// FreedText returns the space freed by deletions formatted for display
+func (s Summary) FreedText() string {
+	return utils.FormatSize(s.FreedBytes)
+}
+
+// Text is the default message of chat webhooks
+func (s Summary) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "peerless check on %s: %d missing (%s), %s", s.Host, s.Missing, s.MissingSizeText(), s.Action)
+	for _, path := range s.MissingPaths {
+		b.WriteString("\n• " + path)
+	}
+	if extra := s.Missing - len(s.MissingPaths); extra > 0 && len(s.MissingPaths) > 0 {
+		fmt.Fprintf(&b, "\n... and %d more", extra)
+	}
+	return b.String()
+}
+
+// Completion is what a webhook reports when torrents awaited by the wait
+// command have finished, e.g. {{.Host}} or {{.Torrents}} in templates
+type Completion struct {
+	Time     time.Time `json:"time"`
+	Host     string    `json:"host"`
+	Torrents []string  `json:"torrents"`
+	Size     int64     `json:"size"`
+}
+
+// SizeText returns the total size of the torrents formatted for display
+func (c Completion) SizeText() string {
+	return utils.FormatSize(c.Size)
+}
+
+// Text is the default message of chat webhooks
+func (c Completion) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "peerless on %s: %d torrents finished downloading (%s)", c.Host, len(c.Torrents), c.SizeText())
+	for _, name := range c.Torrents {
+		b.WriteString("\n• " + name)
+	}
+	return b.String()
+}
+
+// SpaceAlert is what a webhook reports when watched directories run low on
+// free space, e.g. {{.Host}} or {{range .Dirs}}{{.Path}}{{end}} in templates
+type SpaceAlert struct {
+	Time    time.Time  `json:"time"`
+	Host    string     `json:"host"`
+	MinFree int64      `json:"min_free"`
+	Dirs    []LowSpace `json:"dirs"`
+}
+
+// LowSpace is a directory of a SpaceAlert and its free space
+type LowSpace struct {
+	Path string `json:"path"`
+	Free int64  `json:"free"`
+}
+
+// FreeText returns the free space formatted for display
+func (l LowSpace) FreeText() string {
+	return utils.FormatSize(l.Free)
+}
+
+// MinFreeText returns the threshold formatted for display
+func (a SpaceAlert) MinFreeText() string {
+	return utils.FormatSize(a.MinFree)
+}
+
+// Text is the default message of chat webhooks
+func (a SpaceAlert) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "peerless on %s: %d directories below %s free", a.Host, len(a.Dirs), a.MinFreeText())
+	for _, dir := range a.Dirs {
+		fmt.Fprintf(&b, "\n• %s (%s free)", dir.Path, dir.FreeText())
+	}
+	return b.String()
+}
+
+// webhookClient posts webhook payloads; replaced in tests
+var webhookClient = &http.Client{Timeout: constants.HTTPTimeout}
+
+// SendWebhook POSTs the message to the webhook in cfg, formatted for Discord,
+// Slack or as generic JSON
+func SendWebhook(ctx context.Context, cfg types.WebhookConfig, message Message) error {
+	body, err := webhookPayload(cfg, message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		// The error repeats the URL, token included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send webhook to %s: %w", redactURL(cfg.URL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s returned %s: %s", redactURL(cfg.URL), resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// WebhookFormat returns the payload format of cfg, detecting Discord and
+// Slack webhooks from their URL when no format is set
+func WebhookFormat(cfg types.WebhookConfig) string {
+	if cfg.Format != "" {
+		return cfg.Format
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return types.WebhookJSON
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return types.WebhookDiscord
+	case host == "hooks.slack.com":
+		return types.WebhookSlack
+	default:
+		return types.WebhookJSON
+	}
+}
+
+// webhookPayload renders the request body for the format of cfg
+func webhookPayload(cfg types.WebhookConfig, message Message) ([]byte, error) {
+	format := WebhookFormat(cfg)
+
+	var rendered string
+	if cfg.Template != "" {
+		tmpl, err := template.New("webhook").Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, message); err != nil {
+			return nil, fmt.Errorf("failed to render webhook template: %w", err)
+		}
+		rendered = b.String()
+	}
+
+	switch format {
+	case types.WebhookDiscord, types.WebhookSlack:
+		text := rendered
+		if text == "" {
+			text = message.Text()
+		}
+		key := "text"
+		if format == types.WebhookDiscord {
+			key = "content"
+			// Discord rejects messages longer than 2000 characters
+			if runes := []rune(text); len(runes) > constants.DiscordMessageLimit {
+				text = string(runes[:constants.DiscordMessageLimit-1]) + "…"
+			}
+		}
+		return json.Marshal(map[string]string{key: text})
+	default:
+		if rendered != "" {
+			return []byte(rendered), nil
+		}
+		if summary, ok := message.(Summary); ok && summary.MissingPaths == nil {
+			summary.MissingPaths = []string{}
+			message = summary
+		}
+		return json.Marshal(message)
+	}
+}
+
+// redactURL drops the path and query of a webhook URL, which often hold its token
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "webhook"
+	}
+	return u.Scheme + "://" + u.Host
+}
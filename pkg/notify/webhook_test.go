@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/types"
+)
+
+func TestWebhookFormat(t *testing.T) {
+	tests := []struct {
+		url    string
+		format string
+		want   string
+	}{
+		{"https://discord.com/api/webhooks/1/token", "", types.WebhookDiscord},
+		{"https://hooks.slack.com/services/T/B/x", "", types.WebhookSlack},
+		{"https://example.com/hook", "", types.WebhookJSON},
+		{"https://discord.com/api/webhooks/1/token", types.WebhookJSON, types.WebhookJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url+" "+tt.format, func(t *testing.T) {
+			assert.Equal(t, tt.want, WebhookFormat(types.WebhookConfig{URL: tt.url, Format: tt.format}))
+		})
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	summary := Summary{
+		Host:         "nas",
+		Dirs:         []string{"/downloads"},
+		Missing:      3,
+		MissingSize:  2048,
+		MissingPaths: []string{"/downloads/a", "/downloads/b"},
+		Action:       "report",
+	}
+
+	t.Run("slack", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://hooks.slack.com/x"}, summary)
+		require.NoError(t, err)
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, "peerless check on nas: 3 missing (2.00 KB), report\n• /downloads/a\n• /downloads/b\n... and 1 more", got["text"])
+	})
+
+	t.Run("discord template", func(t *testing.T) {
+		cfg := types.WebhookConfig{URL: "https://example.com", Format: types.WebhookDiscord, Template: "{{.Missing}} missing ({{.MissingSizeText}}) on {{.Host}}"}
+		body, err := webhookPayload(cfg, summary)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"content": "3 missing (2.00 KB) on nas"}`, string(body))
+	})
+
+	t.Run("discord messages are truncated", func(t *testing.T) {
+		long := summary
+		long.MissingPaths = []string{strings.Repeat("x", 3000)}
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://example.com", Format: types.WebhookDiscord}, long)
+		require.NoError(t, err)
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Len(t, []rune(got["content"]), 2000)
+	})
+
+	t.Run("generic json", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://example.com"}, summary)
+		require.NoError(t, err)
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, "nas", got["host"])
+		assert.Equal(t, float64(2048), got["missing_size"])
+		assert.Len(t, got["missing_paths"], 2)
+	})
+
+	t.Run("json template is the body", func(t *testing.T) {
+		cfg := types.WebhookConfig{URL: "https://example.com", Template: `{"msg": "{{.Action}}"}`}
+		body, err := webhookPayload(cfg, summary)
+		require.NoError(t, err)
+		assert.Equal(t, `{"msg": "report"}`, string(body))
+	})
+}
+
+func TestWebhookPayload_Completion(t *testing.T) {
+	done := Completion{Host: "nas", Torrents: []string{"Ubuntu 24.04", "Ubuntu 24.10"}, Size: 4096}
+
+	t.Run("slack", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://hooks.slack.com/x"}, done)
+		require.NoError(t, err)
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, "peerless on nas: 2 torrents finished downloading (4.00 KB)\n• Ubuntu 24.04\n• Ubuntu 24.10", got["text"])
+	})
+
+	t.Run("generic json", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://example.com"}, done)
+		require.NoError(t, err)
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, []any{"Ubuntu 24.04", "Ubuntu 24.10"}, got["torrents"])
+		assert.Equal(t, float64(4096), got["size"])
+	})
+}
+
+func TestWebhookPayload_SpaceAlert(t *testing.T) {
+	alert := SpaceAlert{Host: "nas", MinFree: 1024, Dirs: []LowSpace{{Path: "/downloads", Free: 512}}}
+
+	t.Run("discord", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://discord.com/api/webhooks/1/x"}, alert)
+		require.NoError(t, err)
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, "peerless on nas: 1 directories below 1.00 KB free\n• /downloads (512 B free)", got["content"])
+	})
+
+	t.Run("generic json", func(t *testing.T) {
+		body, err := webhookPayload(types.WebhookConfig{URL: "https://example.com"}, alert)
+		require.NoError(t, err)
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, float64(1024), got["min_free"])
+		assert.Equal(t, []any{map[string]any{"path": "/downloads", "free": float64(512)}}, got["dirs"])
+	})
+}
+
+func TestSendWebhook(t *testing.T) {
+	t.Run("posts json", func(t *testing.T) {
+		var gotType string
+		var gotBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotType = r.Header.Get("Content-Type")
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := SendWebhook(context.Background(), types.WebhookConfig{URL: server.URL + "/hook", Format: types.WebhookSlack}, Summary{Host: "nas", Action: "report"})
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", gotType)
+		assert.Contains(t, string(gotBody), "peerless check on nas")
+	})
+
+	t.Run("error statuses hide the token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unknown webhook", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		err := SendWebhook(context.Background(), types.WebhookConfig{URL: server.URL + "/api/webhooks/1/secret-token"}, Summary{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404 Not Found: unknown webhook")
+		assert.NotContains(t, err.Error(), "secret-token")
+	})
+
+	t.Run("connection errors hide the token", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		err := SendWebhook(context.Background(), types.WebhookConfig{URL: url + "/secret-token"}, Summary{})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret-token")
+	})
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ItemDecision is the answer given for one item in per-item confirmation
+type ItemDecision int
+
+const (
+	// ItemSkip leaves the item alone
+	ItemSkip ItemDecision = iota
+	// ItemAccept includes the item
+	ItemAccept
+	// ItemAcceptAll includes this and every remaining item
+	ItemAcceptAll
+	// ItemQuit stops asking and leaves the remaining items alone
+	ItemQuit
+)
+
+// Confirmer asks the user to approve destructive actions
+type Confirmer struct {
+	in        *bufio.Reader
+	out       io.Writer
+	assumeYes bool
+}
+
+// NewConfirmer creates a Confirmer reading from stdin. With assumeYes every
+// prompt is answered automatically, as requested by --yes. In quiet mode
+// prompts go to stderr, keeping stdout for data.
+func NewConfirmer(assumeYes bool) *Confirmer {
+	out := os.Stdout
+	if quiet {
+		out = os.Stderr
+	}
+	return NewConfirmerWithIO(os.Stdin, out, assumeYes)
+}
+
+// NewConfirmerWithIO creates a Confirmer using the given input and output
+func NewConfirmerWithIO(in io.Reader, out io.Writer, assumeYes bool) *Confirmer {
+	return &Confirmer{in: bufio.NewReader(in), out: out, assumeYes: assumeYes}
+}
+
+// Confirm asks a yes/no question and reports whether the answer was yes
+func (c *Confirmer) Confirm(prompt string) bool {
+	fmt.Fprintf(c.out, "%s (yes/No): ", prompt)
+	if c.assumeYes {
+		fmt.Fprintln(c.out, "yes (--yes)")
+		return true
+	}
+
+	response, ok := c.readLine()
+	if !ok {
+		return false
+	}
+
+	response = strings.ToLower(response)
+	return response == "yes" || response == "y"
+}
+
+// ConfirmPhrase requires the user to type phrase exactly, for actions too
+// large to approve with a single keystroke
+func (c *Confirmer) ConfirmPhrase(prompt, phrase string) bool {
+	fmt.Fprintf(c.out, "%s\nType %q to continue: ", prompt, phrase)
+	if c.assumeYes {
+		fmt.Fprintln(c.out, "(--yes)")
+		return true
+	}
+
+	response, ok := c.readLine()
+	return ok && response == phrase
+}
+
+// ConfirmItem asks about a single item in per-item mode
+func (c *Confirmer) ConfirmItem(prompt string) ItemDecision {
+	fmt.Fprintf(c.out, "%s [y]es/[N]o/[a]ll/[q]uit: ", prompt)
+	if c.assumeYes {
+		fmt.Fprintln(c.out, "all (--yes)")
+		return ItemAcceptAll
+	}
+
+	response, ok := c.readLine()
+	if !ok {
+		return ItemQuit
+	}
+
+	switch strings.ToLower(response) {
+	case "y", "yes":
+		return ItemAccept
+	case "a", "all":
+		return ItemAcceptAll
+	case "q", "quit":
+		return ItemQuit
+	default:
+		return ItemSkip
+	}
+}
+
+// SelectItems runs per-item confirmation over items and returns the accepted ones
+func (c *Confirmer) SelectItems(items []string, prompt func(item string) string) []string {
+	selected := make([]string, 0, len(items))
+	for i, item := range items {
+		switch c.ConfirmItem(prompt(item)) {
+		case ItemAccept:
+			selected = append(selected, item)
+		case ItemAcceptAll:
+			return append(selected, items[i:]...)
+		case ItemQuit:
+			return selected
+		}
+	}
+	return selected
+}
+
+// readLine reads one trimmed line of input, treating read failures as no answer
+func (c *Confirmer) readLine() (string, bool) {
+	line, err := c.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		Logger.Warn("Failed to read input, treating as no", "error", err)
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmer_Confirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"yes\n", true},
+		{"Y\n", true},
+		{"no\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		c := NewConfirmerWithIO(strings.NewReader(tt.input), &bytes.Buffer{}, false)
+		assert.Equal(t, tt.expected, c.Confirm("Delete?"), "input %q", tt.input)
+	}
+
+	t.Run("assume yes", func(t *testing.T) {
+		var out bytes.Buffer
+		c := NewConfirmerWithIO(strings.NewReader(""), &out, true)
+		assert.True(t, c.Confirm("Delete?"))
+		assert.Contains(t, out.String(), "--yes")
+	})
+}
+
+func TestConfirmer_ConfirmPhrase(t *testing.T) {
+	c := NewConfirmerWithIO(strings.NewReader("DELETE 512GB\n"), &bytes.Buffer{}, false)
+	assert.True(t, c.ConfirmPhrase("Large deletion", "DELETE 512GB"))
+
+	c = NewConfirmerWithIO(strings.NewReader("yes\n"), &bytes.Buffer{}, false)
+	assert.False(t, c.ConfirmPhrase("Large deletion", "DELETE 512GB"))
+
+	c = NewConfirmerWithIO(strings.NewReader("delete 512gb\n"), &bytes.Buffer{}, false)
+	assert.False(t, c.ConfirmPhrase("Large deletion", "DELETE 512GB"))
+}
+
+func TestConfirmer_SelectItems(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	prompt := func(item string) string { return "Delete " + item + "?" }
+
+	t.Run("yes, no, all", func(t *testing.T) {
+		c := NewConfirmerWithIO(strings.NewReader("y\nn\na\n"), &bytes.Buffer{}, false)
+		assert.Equal(t, []string{"a", "c", "d"}, c.SelectItems(items, prompt))
+	})
+
+	t.Run("quit keeps earlier answers", func(t *testing.T) {
+		c := NewConfirmerWithIO(strings.NewReader("y\nq\n"), &bytes.Buffer{}, false)
+		assert.Equal(t, []string{"a"}, c.SelectItems(items, prompt))
+	})
+
+	t.Run("end of input stops", func(t *testing.T) {
+		c := NewConfirmerWithIO(strings.NewReader("n\n"), &bytes.Buffer{}, false)
+		assert.Empty(t, c.SelectItems(items, prompt))
+	})
+
+	t.Run("assume yes selects everything", func(t *testing.T) {
+		c := NewConfirmerWithIO(strings.NewReader(""), &bytes.Buffer{}, true)
+		assert.Equal(t, items, c.SelectItems(items, prompt))
+	})
+}
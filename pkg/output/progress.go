@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressJSON selects JSON progress events on stderr
+const ProgressJSON = "json"
+
+// ValidateProgress checks that mode is a supported progress mode
+func ValidateProgress(mode string) error {
+	switch mode {
+	case "", ProgressJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported progress mode %q (use %s)", mode, ProgressJSON)
+	}
+}
+
+// ProgressEvent is a single machine-readable progress update
+type ProgressEvent struct {
+	Phase   string `json:"phase"`
+	Current int    `json:"current"`
+	Total   int    `json:"total"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// ProgressReporter writes progress events as JSON lines, at most one per
+// interval except for phase changes and completed phases. A total of zero
+// means the total is not known yet. A nil ProgressReporter discards all updates.
+type ProgressReporter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	interval  time.Duration
+	now       func() time.Time
+	last      time.Time
+	lastPhase string
+}
+
+// NewProgressReporter creates a ProgressReporter writing to w
+func NewProgressReporter(w io.Writer, interval time.Duration) *ProgressReporter {
+	return &ProgressReporter{w: w, interval: interval, now: time.Now}
+}
+
+// Update reports progress for phase, emitting an event when one is due
+func (p *ProgressReporter) Update(phase string, current, total int, bytes int64) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	finished := total > 0 && current >= total
+	due := phase != p.lastPhase || finished || now.Sub(p.last) >= p.interval
+	if !due {
+		return
+	}
+
+	p.last = now
+	p.lastPhase = phase
+	event := ProgressEvent{Phase: phase, Current: current, Total: total, Bytes: bytes}
+	if err := json.NewEncoder(p.w).Encode(event); err != nil {
+		Logger.Debug("Failed to write progress event", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/utils"
+)
+
+// ProgressBarEnabled reports whether a live progress bar can be drawn, which
+// requires stdout to be a terminal and quiet mode to be off
+func ProgressBarEnabled() bool {
+	return isTerminal() && !quiet
+}
+
+// ScanProgressBar draws a single, redrawn line with a progress bar, the items
+// processed, the bytes summed and the current path. The bar is only filled once
+// the total is known; until then the line shows the counts alone. Redraws
+// happen at most once per interval except for completed scans. A nil
+// ScanProgressBar discards all updates.
+type ScanProgressBar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	bar      progress.Model
+	interval time.Duration
+	now      func() time.Time
+	last     time.Time
+	drawn    bool
+}
+
+// NewScanProgressBar creates a ScanProgressBar drawing on w
+func NewScanProgressBar(w io.Writer, interval time.Duration) *ScanProgressBar {
+	bar := progress.New(
+		progress.WithDefaultGradient(),
+		progress.WithWidth(constants.ProgressBarWidth),
+		// Follows DisableColor, so NO_COLOR and --no-color apply to the bar
+		progress.WithColorProfile(lipgloss.ColorProfile()),
+	)
+	return &ScanProgressBar{w: w, bar: bar, interval: interval, now: time.Now}
+}
+
+// Update redraws the line for path when a redraw is due
+func (b *ScanProgressBar) Update(path string, current, total int, bytes int64) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	finished := total > 0 && current >= total
+	if b.drawn && !finished && now.Sub(b.last) < b.interval {
+		return
+	}
+	b.last = now
+
+	var line strings.Builder
+	if total > 0 {
+		line.WriteString(b.bar.ViewAs(float64(current) / float64(total)))
+		fmt.Fprintf(&line, "  %d/%d", current, total)
+	} else {
+		fmt.Fprintf(&line, "%d", current)
+	}
+	fmt.Fprintf(&line, " items  %s  %s", utils.FormatSize(bytes), path)
+
+	// Carriage return and erase the previous line, keeping the text on one row
+	fmt.Fprint(b.w, "\r"+ansi.EraseEntireLine+ansi.Truncate(line.String(), constants.ProgressLineWidth, "…"))
+	b.drawn = true
+}
+
+// Clear erases the line so regular output can continue where it was
+func (b *ScanProgressBar) Clear() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.drawn {
+		fmt.Fprint(b.w, "\r"+ansi.EraseEntireLine)
+		b.drawn = false
+	}
+}
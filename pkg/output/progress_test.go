@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var buf bytes.Buffer
+	clock := time.Unix(0, 0)
+	p := NewProgressReporter(&buf, time.Second)
+	p.now = func() time.Time { return clock }
+
+	p.Update("scan", 1, 10, 100) // first event of a phase
+	p.Update("scan", 2, 10, 200) // throttled
+	clock = clock.Add(time.Second)
+	p.Update("scan", 3, 10, 300)  // interval elapsed
+	p.Update("scan", 10, 10, 900) // phase complete
+	p.Update("delete", 1, 5, 50)  // new phase
+
+	var events []ProgressEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event ProgressEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+
+	assert.Equal(t, []ProgressEvent{
+		{Phase: "scan", Current: 1, Total: 10, Bytes: 100},
+		{Phase: "scan", Current: 3, Total: 10, Bytes: 300},
+		{Phase: "scan", Current: 10, Total: 10, Bytes: 900},
+		{Phase: "delete", Current: 1, Total: 5, Bytes: 50},
+	}, events)
+
+	t.Run("unknown total is throttled", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewProgressReporter(&buf, time.Second)
+		p.now = func() time.Time { return clock }
+
+		p.Update("scan", 1, 0, 0)
+		p.Update("scan", 2, 0, 0)
+		p.Update("scan", 3, 0, 0)
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("nil reporter discards updates", func(t *testing.T) {
+		var p *ProgressReporter
+		assert.NotPanics(t, func() { p.Update("scan", 1, 1, 0) })
+	})
+}
+
+func TestValidateProgress(t *testing.T) {
+	assert.NoError(t, ValidateProgress(""))
+	assert.NoError(t, ValidateProgress(ProgressJSON))
+	assert.Error(t, ValidateProgress("bar"))
+}
+
+func TestScanProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	clock := time.Unix(0, 0)
+	b := NewScanProgressBar(&buf, time.Second)
+	b.now = func() time.Time { return clock }
+
+	b.Update("/data/movies", 1, 0, 2048) // first draw, total unknown
+	assert.Contains(t, buf.String(), "1 items")
+	assert.Contains(t, buf.String(), "/data/movies")
+
+	buf.Reset()
+	b.Update("/data/movies", 2, 0, 4096) // throttled
+	assert.Empty(t, buf.String())
+
+	b.Update("/data/movies", 4, 4, 8192) // complete scans are always drawn
+	assert.Contains(t, buf.String(), "4/4 items")
+	assert.Contains(t, buf.String(), "100%")
+	assert.NotContains(t, buf.String(), "\n")
+
+	buf.Reset()
+	b.Clear()
+	assert.Equal(t, "\r\x1b[2K", buf.String())
+
+	buf.Reset()
+	b.Clear() // nothing left to erase
+	assert.Empty(t, buf.String())
+
+	t.Run("long paths are cut off", func(t *testing.T) {
+		var buf bytes.Buffer
+		b := NewScanProgressBar(&buf, time.Second)
+		b.Update("/"+strings.Repeat("x", 500), 1, 2, 0)
+		assert.Less(t, len(buf.String()), 500)
+	})
+
+	t.Run("nil bar discards updates", func(t *testing.T) {
+		var b *ScanProgressBar
+		assert.NotPanics(t, func() {
+			b.Update("/data", 1, 1, 0)
+			b.Clear()
+		})
+	})
+}
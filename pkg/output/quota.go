@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"peerless/pkg/service"
+	"peerless/pkg/utils"
+)
+
+// Quota levels reported by QuotaLevel, in increasing severity
+const (
+	QuotaOK = iota
+	QuotaWarn
+	QuotaExceeded
+)
+
+// QuotaLevel classifies total against limit; a limit of zero is never reached
+func QuotaLevel(total, limit int64, warnPercent int) int {
+	switch {
+	case limit <= 0:
+		return QuotaOK
+	case total >= limit:
+		return QuotaExceeded
+	case total*100 >= limit*int64(warnPercent):
+		return QuotaWarn
+	default:
+		return QuotaOK
+	}
+}
+
+// PrintQuotaUsage prints the transfers of the billing period and, when a cap
+// is set, how much of it is used
+func PrintQuotaUsage(u service.QuotaUsage, limit int64) {
+	fmt.Printf("%s %s – %s\n", StatusLabelStyle.Render("Period:"),
+		u.PeriodStart.Format(time.DateOnly), u.PeriodEnd.Format(time.DateOnly))
+	fmt.Printf("%s %s • %s %s • %s %s\n",
+		StatusLabelStyle.Render("Downloaded:"), StatusValueStyle.Render(utils.FormatSize(u.Downloaded)),
+		StatusLabelStyle.Render("Uploaded:"), StatusValueStyle.Render(utils.FormatSize(u.Uploaded)),
+		StatusLabelStyle.Render("Total:"), StatusValueStyle.Render(utils.FormatSize(u.Total())))
+	if limit > 0 {
+		fmt.Printf("%s %s • %.1f%% used • %s left\n", StatusLabelStyle.Render("Cap:"),
+			utils.FormatSize(limit), quotaPercent(u.Total(), limit), utils.FormatSize(max(limit-u.Total(), 0)))
+	}
+	if !u.Complete() {
+		PrintInfo(fmt.Sprintf("(history starts %s - earlier transfers this period are not counted)",
+			u.Since.Format(time.DateTime)))
+	}
+}
+
+// PrintQuotaLine prints a one-line reading for watch mode
+func PrintQuotaLine(u service.QuotaUsage, limit int64, now time.Time) {
+	line := fmt.Sprintf("[%s] total=%s down=%s up=%s", now.Format(time.TimeOnly),
+		utils.FormatSize(u.Total()), utils.FormatSize(u.Downloaded), utils.FormatSize(u.Uploaded))
+	if limit > 0 {
+		line += fmt.Sprintf(" (%.1f%% of %s)", quotaPercent(u.Total(), limit), utils.FormatSize(limit))
+	}
+	if !u.Complete() {
+		line += " (partial history)"
+	}
+	fmt.Println(line)
+}
+
+// PrintQuotaAlert warns that the usage reached level
+func PrintQuotaAlert(level int, total, limit int64) {
+	switch level {
+	case QuotaWarn:
+		PrintWarning(fmt.Sprintf("⚠️  Data cap nearly reached: %.1f%% of %s used", quotaPercent(total, limit), utils.FormatSize(limit)))
+	case QuotaExceeded:
+		PrintError(fmt.Sprintf("❌ Data cap exceeded: %s of %s used", utils.FormatSize(total), utils.FormatSize(limit)))
+	}
+}
+
+// quotaPercent returns total as a percentage of limit
+func quotaPercent(total, limit int64) float64 {
+	return float64(total) / float64(limit) * 100
+}
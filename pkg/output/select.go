@@ -0,0 +1,173 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checklistModel is a bubbletea list of items toggled with checkboxes
+type checklistModel struct {
+	title   string
+	items   []string
+	details []string
+	checked []bool
+
+	cursor int
+	offset int
+	height int
+
+	done      bool
+	cancelled bool
+}
+
+// newChecklistModel creates a checklist with nothing selected
+func newChecklistModel(title string, items, details []string) checklistModel {
+	return checklistModel{
+		title:   title,
+		items:   items,
+		details: details,
+		checked: make([]bool, len(items)),
+		height:  len(items),
+	}
+}
+
+// Init implements tea.Model
+func (m checklistModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m checklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the title and the help line; zero means unknown
+		if msg.Height > 0 {
+			m.height = max(msg.Height-3, 1)
+			m.scroll()
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = len(m.items) - 1
+		case " ", "x":
+			m.checked[m.cursor] = !m.checked[m.cursor]
+		case "a":
+			// Select everything, or clear the selection when all are selected
+			all := m.count() < len(m.items)
+			for i := range m.checked {
+				m.checked[i] = all
+			}
+		case "enter":
+			m.done = true
+			return m, tea.Quit
+		case "q", "esc", "ctrl+c":
+			m.cancelled = true
+			return m, tea.Quit
+		}
+		m.scroll()
+	}
+	return m, nil
+}
+
+// scroll keeps the cursor inside the visible window
+func (m *checklistModel) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// count returns how many items are selected
+func (m checklistModel) count() int {
+	n := 0
+	for _, c := range m.checked {
+		if c {
+			n++
+		}
+	}
+	return n
+}
+
+// selected returns the checked items in list order
+func (m checklistModel) selected() []string {
+	selected := make([]string, 0, m.count())
+	for i, item := range m.items {
+		if m.checked[i] {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
+
+// View implements tea.Model
+func (m checklistModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(HeaderStyle.Render(m.title) + "\n")
+
+	end := min(m.offset+m.height, len(m.items))
+	for i := m.offset; i < end; i++ {
+		cursor, box := "  ", "[ ]"
+		if i == m.cursor {
+			cursor = "> "
+		}
+		if m.checked[i] {
+			box = MissingStyle.Render("[x]")
+		}
+		line := PathStyle.Render(m.items[i])
+		if i == m.cursor {
+			line = WarningStyle.Render(m.items[i])
+		}
+		if m.details[i] != "" {
+			line += " " + SizeStyle.Render(m.details[i])
+		}
+		b.WriteString(cursor + box + " " + line + "\n")
+	}
+
+	b.WriteString(InfoStyle.Render(fmt.Sprintf("%d of %d selected • space: toggle • a: all/none • enter: delete selected • q: cancel",
+		m.count(), len(m.items))) + "\n")
+	return b.String()
+}
+
+// SelectChecklist shows items in a checkbox list and returns those the user
+// selected, or nil when the selection was cancelled. With --yes every item is
+// selected without asking.
+func (c *Confirmer) SelectChecklist(title string, items []string, detail func(item string) string) ([]string, error) {
+	if c.assumeYes {
+		fmt.Fprintf(c.out, "%s: all %d items (--yes)\n", title, len(items))
+		return items, nil
+	}
+
+	details := make([]string, len(items))
+	for i, item := range items {
+		details[i] = detail(item)
+	}
+
+	final, err := tea.NewProgram(newChecklistModel(title, items, details), tea.WithOutput(c.out)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run selection list: %w", err)
+	}
+	m := final.(checklistModel)
+	if m.cancelled {
+		return nil, nil
+	}
+	return m.selected(), nil
+}
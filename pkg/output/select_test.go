@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// press sends keys to a checklist model and returns the resulting model
+func press(t *testing.T, m checklistModel, keys ...string) checklistModel {
+	t.Helper()
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(checklistModel)
+	}
+	return m
+}
+
+func TestChecklistModel(t *testing.T) {
+	items := []string{"/data/a", "/data/b", "/data/c"}
+	newModel := func() checklistModel {
+		return newChecklistModel("Select", items, []string{"(1 B, file)", "", ""})
+	}
+
+	t.Run("toggle items", func(t *testing.T) {
+		m := press(t, newModel(), " ", "down", "down", " ", "up", "up", " ", "down", " ", "enter")
+		assert.True(t, m.done)
+		assert.Equal(t, []string{"/data/b", "/data/c"}, m.selected())
+	})
+
+	t.Run("cursor stays in range", func(t *testing.T) {
+		m := press(t, newModel(), "up", "down", "down", "down", "down")
+		assert.Equal(t, 2, m.cursor)
+	})
+
+	t.Run("all and none", func(t *testing.T) {
+		m := press(t, newModel(), "a")
+		assert.Equal(t, items, m.selected())
+		m = press(t, m, "a")
+		assert.Empty(t, m.selected())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		m := press(t, newModel(), " ", "q")
+		assert.True(t, m.cancelled)
+	})
+
+	t.Run("scrolls with a small window", func(t *testing.T) {
+		next, _ := newModel().Update(tea.WindowSizeMsg{Width: 80, Height: 4})
+		m := press(t, next.(checklistModel), "down", "down")
+		assert.Equal(t, 1, m.height)
+		assert.Equal(t, 2, m.offset)
+
+		view := m.View()
+		assert.Contains(t, view, "/data/c")
+		assert.NotContains(t, view, "/data/a")
+	})
+
+	t.Run("view", func(t *testing.T) {
+		view := press(t, newModel(), " ").View()
+		assert.Contains(t, view, "[x]")
+		assert.Contains(t, view, "(1 B, file)")
+		assert.Contains(t, view, "1 of 3 selected")
+	})
+}
+
+func TestConfirmer_SelectChecklist_AssumeYes(t *testing.T) {
+	var out bytes.Buffer
+	c := NewConfirmerWithIO(strings.NewReader(""), &out, true)
+	selected, err := c.SelectChecklist("Select", []string{"a", "b"}, func(string) string { return "" })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, selected)
+	assert.Contains(t, out.String(), "--yes")
+}
@@ -1,16 +1,24 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"peerless/pkg/constants"
+	"peerless/pkg/i18n"
 	"peerless/pkg/service"
+	"peerless/pkg/types"
 	"peerless/pkg/utils"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
 )
 
 // Color constants for better readability
@@ -92,18 +100,38 @@ var Logger *log.Logger
 
 func init() {
 	// Initialize logger with custom settings
-	Logger = log.NewWithOptions(os.Stderr, log.Options{
+	// Credentials registered with utils.RegisterSecret never reach the log output
+	Logger = log.NewWithOptions(utils.RedactingWriter(os.Stderr), log.Options{
 		ReportCaller:    false,
 		ReportTimestamp: false,
 		Prefix:          "peerless",
 	})
 
-	// Check if we're in a terminal that supports colors
-	if !isTerminal() {
-		disableColors()
+	// Colors are only used on a terminal; NO_COLOR turns them off there too
+	if !isTerminal() || os.Getenv(constants.EnvNoColor) != "" {
+		DisableColor()
 	}
 }
 
+// quiet suppresses messages, leaving errors and data; see SetQuiet
+var quiet bool
+
+// SetQuiet turns quiet mode on or off. In quiet mode headers, separators,
+// summaries and success, info and warning messages are not printed, while
+// errors and the data of commands, such as lists and JSON, still are. Turning
+// it on also limits the log to errors.
+func SetQuiet(enabled bool) {
+	quiet = enabled
+	if enabled {
+		Logger.SetLevel(log.ErrorLevel)
+	}
+}
+
+// Quiet reports whether quiet mode is on
+func Quiet() bool {
+	return quiet
+}
+
 // isTerminal checks if stdout is a terminal
 func isTerminal() bool {
 	fileInfo, err := os.Stdout.Stat()
@@ -113,8 +141,11 @@ func isTerminal() bool {
 	return (fileInfo.Mode() & os.ModeCharDevice) != 0
 }
 
-// disableColors disables all colored output for non-terminal environments
-func disableColors() {
+// DisableColor turns off colors and text attributes in all styled output,
+// including styles created elsewhere with lipgloss
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
 	SuccessStyle = SuccessStyle.UnsetBold().UnsetForeground()
 	ErrorStyle = ErrorStyle.UnsetBold().UnsetForeground()
 	WarningStyle = WarningStyle.UnsetBold().UnsetForeground()
@@ -148,35 +179,59 @@ func disableColors() {
 // Helper functions for common output patterns
 
 func PrintHeader(text string) {
+	if quiet {
+		return
+	}
 	println(HeaderStyle.Render(text))
 }
 
 func PrintSeparator(width int) {
+	if quiet {
+		return
+	}
 	separator := SeparatorStyle.Render(strings.Repeat("-", width))
 	println(separator)
 }
 
 func PrintDirectoryHeader(dir string) {
+	if quiet {
+		return
+	}
 	println(DirectoryHeaderStyle.Render("Directory: " + dir))
 }
 
 func PrintSummary(text string) {
+	if quiet {
+		return
+	}
 	println(SummaryStyle.Render(text))
 }
 
 func PrintSuccess(text string) {
+	if quiet {
+		return
+	}
 	println(SuccessStyle.Render(text))
 }
 
+// PrintError prints an error message to stderr. Like the log, it often
+// carries error strings, so registered credentials are redacted.
 func PrintError(text string) {
-	println(ErrorStyle.Render(text))
+	fmt.Fprintln(os.Stderr, ErrorStyle.Render(utils.Redact(text)))
 }
 
+// PrintWarning prints a warning to stderr with credentials redacted, except in quiet mode
 func PrintWarning(text string) {
-	println(WarningStyle.Render(text))
+	if quiet {
+		return
+	}
+	fmt.Fprintln(os.Stderr, WarningStyle.Render(utils.Redact(text)))
 }
 
 func PrintInfo(text string) {
+	if quiet {
+		return
+	}
 	println(InfoStyle.Render(text))
 }
 
@@ -185,10 +240,14 @@ func PrintPath(path string) {
 }
 
 func PrintSize(size string) {
+	if quiet {
+		return
+	}
 	print(SizeStyle.Render(size))
 }
 
-func PrintTorrentStatus(isFound bool, name string, isDir bool) {
+// PrintTorrentStatus prints a directory entry to w, marked as found in a torrent or missing
+func PrintTorrentStatus(w io.Writer, isFound bool, name string, isDir bool) {
 	var statusSymbol string
 	var entryType string
 
@@ -204,7 +263,31 @@ func PrintTorrentStatus(isFound bool, name string, isDir bool) {
 		entryType = FileSymbol
 	}
 
-	fmt.Printf("%s %s %s\n", statusSymbol, entryType, name)
+	fmt.Fprintf(w, "%s %s %s\n", statusSymbol, entryType, name)
+}
+
+// PrintMatchExplanations prints to w how each item of dir matched a torrent,
+// or why it matched none
+func PrintMatchExplanations(w io.Writer, dir string, explanations []service.MatchExplanation) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+	for _, e := range explanations {
+		name := e.Path
+		if rel, err := filepath.Rel(absDir, e.Path); err == nil {
+			name = rel
+		}
+		if e.Matched() {
+			line := fmt.Sprintf("%s %s → %q by %s", SuccessSymbol, PathStyle.Render(name), e.Torrent, e.Matcher)
+			if e.Reason != "" {
+				line += InfoStyle.Render(" (" + e.Reason + ")")
+			}
+			fmt.Fprintln(w, line)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", ErrorSymbol, PathStyle.Render(name), InfoStyle.Render(e.Reason))
+	}
 }
 
 // Status-specific styles
@@ -246,85 +329,131 @@ func PrintStatusHeader(title string) {
 }
 
 // PrintCompactStatus prints a compact one-line status summary
-func PrintCompactStatus(total, downloading, seeding, paused int, downloadSpeed, uploadSpeed int, totalSize, freeSpace int64) {
+func PrintCompactStatus(s *service.DetailedStatus) {
 	// Torrent status
-	status := fmt.Sprintf("%d torrents", total)
-	if downloading > 0 {
-		status += fmt.Sprintf(" (⬇️ %d)", downloading)
+	status := i18n.T("status.compact_torrents", s.TotalTorrents)
+	if s.DownloadingTorrents > 0 {
+		status += fmt.Sprintf(" (⬇️ %d)", s.DownloadingTorrents)
+	}
+	if s.SeedingTorrents > 0 {
+		status += fmt.Sprintf(" (⬆️ %d)", s.SeedingTorrents)
+	}
+	if s.PausedTorrents > 0 {
+		status += fmt.Sprintf(" (⏸️ %d)", s.PausedTorrents)
 	}
-	if seeding > 0 {
-		status += fmt.Sprintf(" (⬆️ %d)", seeding)
+	if s.CompletedTorrents > 0 {
+		status += fmt.Sprintf(" (✅ %d)", s.CompletedTorrents)
 	}
-	if paused > 0 {
-		status += fmt.Sprintf(" (⏸️ %d)", paused)
+	if s.QueuedTorrents > 0 {
+		status += fmt.Sprintf(" (⏳ %d)", s.QueuedTorrents)
+	}
+	if s.VerifyingTorrents > 0 {
+		status += fmt.Sprintf(" (🔍 %d)", s.VerifyingTorrents)
 	}
 
 	// Speeds
+	downloadSpeed, uploadSpeed := s.TotalDownloadSpeed, s.TotalUploadSpeed
 	speeds := ""
 	if downloadSpeed > 0 || uploadSpeed > 0 {
 		if downloadSpeed > 0 && uploadSpeed > 0 {
-			speeds = fmt.Sprintf(" • %s ↓ / %s ↑", formatSpeed(downloadSpeed), formatSpeed(uploadSpeed))
+			speeds = fmt.Sprintf(" • %s ↓ / %s ↑", utils.FormatSpeed(int64(downloadSpeed)), utils.FormatSpeed(int64(uploadSpeed)))
 		} else if downloadSpeed > 0 {
-			speeds = fmt.Sprintf(" • %s ↓", formatSpeed(downloadSpeed))
+			speeds = fmt.Sprintf(" • %s ↓", utils.FormatSpeed(int64(downloadSpeed)))
 		} else if uploadSpeed > 0 {
-			speeds = fmt.Sprintf(" • %s ↑", formatSpeed(uploadSpeed))
+			speeds = fmt.Sprintf(" • %s ↑", utils.FormatSpeed(int64(uploadSpeed)))
 		}
 	}
 
 	// Storage
 	storage := ""
-	if freeSpace > 0 {
-		storage = fmt.Sprintf(" • %s free", formatSize(statusSize(freeSpace)))
+	if s.DiskQuota != nil {
+		storage = " • " + i18n.T("status.compact_quota", utils.FormatSize(s.DiskQuota.Free()), utils.FormatSize(s.DiskQuota.Limit))
+	} else if s.FreeSpace > 0 {
+		storage = " • " + i18n.T("status.compact_free", utils.FormatSize(s.FreeSpace))
 	}
 
 	fmt.Printf("%s%s%s\n\n", StatusValueStyle.Render(status), StatusSpeedStyle.Render(speeds), StatusValueStyle.Render(storage))
 }
 
 // PrintStatusSummary prints a concise status summary
-func PrintStatusSummary(total, downloading, seeding, paused int, downloadSpeed, uploadSpeed int, totalSize, downloadedSize, remainingSize, freeSpace int64) {
+func PrintStatusSummary(s *service.DetailedStatus) {
 	// Torrent counts in one line
-	fmt.Printf("Torrents: %d", total)
-	if downloading > 0 {
-		fmt.Printf(" • %s downloading", StatusActiveStyle.Render(fmt.Sprintf("%d", downloading)))
+	fmt.Print(i18n.T("status.torrents", s.TotalTorrents))
+	if s.DownloadingTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.downloading", StatusActiveStyle.Render(fmt.Sprintf("%d", s.DownloadingTorrents))))
+	}
+	if s.SeedingTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.seeding", StatusActiveStyle.Render(fmt.Sprintf("%d", s.SeedingTorrents))))
+	}
+	if s.PausedTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.paused", WarningStyle.Render(fmt.Sprintf("%d", s.PausedTorrents))))
 	}
-	if seeding > 0 {
-		fmt.Printf(" • %s seeding", StatusActiveStyle.Render(fmt.Sprintf("%d", seeding)))
+	if s.CompletedTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.completed", SuccessStyle.Render(fmt.Sprintf("%d", s.CompletedTorrents))))
 	}
-	if paused > 0 {
-		fmt.Printf(" • %s paused", WarningStyle.Render(fmt.Sprintf("%d", paused)))
+	if s.QueuedTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.queued", StatusInactiveStyle.Render(fmt.Sprintf("%d", s.QueuedTorrents))))
+	}
+	if s.VerifyingTorrents > 0 {
+		fmt.Print(" • " + i18n.T("status.verifying", StatusInactiveStyle.Render(fmt.Sprintf("%d", s.VerifyingTorrents))))
 	}
 	fmt.Println()
 
+	// Completion breakdown
+	if totalCompleted := s.CompletedTorrents + s.CompletedSeedingTorrents; totalCompleted > 0 {
+		fmt.Println(i18n.T("status.completed_breakdown",
+			totalCompleted, s.CompletedTorrents, s.CompletedSeedingTorrents))
+	}
+
+	// Age
+	if s.TotalTorrents > 0 {
+		a := s.AgeBuckets
+		fmt.Println(i18n.T("status.age", a.LastWeek, a.LastMonth, a.LastHalfYear, a.Older))
+		fmt.Println(i18n.T("status.privacy",
+			s.Private.Count, StatusValueStyle.Render(utils.FormatSize(s.Private.TotalSize)),
+			s.Public.Count, StatusValueStyle.Render(utils.FormatSize(s.Public.TotalSize))))
+	}
+
 	// Progress
-	if totalSize > 0 {
-		percent := float64(downloadedSize) / float64(totalSize) * 100
-		fmt.Printf("Progress: %.1f%% • %s / %s", percent,
-			StatusValueStyle.Render(formatSize(statusSize(downloadedSize))),
-			StatusValueStyle.Render(formatSize(statusSize(totalSize))))
-		if remainingSize > 0 {
-			fmt.Printf(" • %s remaining", StatusValueStyle.Render(formatSize(statusSize(remainingSize))))
+	if s.TotalSize > 0 {
+		percent := float64(s.DownloadedSize) / float64(s.TotalSize) * 100
+		fmt.Print(i18n.T("status.progress", percent,
+			StatusValueStyle.Render(utils.FormatSize(s.DownloadedSize)),
+			StatusValueStyle.Render(utils.FormatSize(s.TotalSize))))
+		if s.RemainingSize > 0 {
+			fmt.Print(" • " + i18n.T("status.remaining", StatusValueStyle.Render(utils.FormatSize(s.RemainingSize))))
 		}
 		fmt.Println()
 	}
 
 	// Speeds
+	downloadSpeed, uploadSpeed := s.TotalDownloadSpeed, s.TotalUploadSpeed
 	if downloadSpeed > 0 || uploadSpeed > 0 {
-		fmt.Print("Speed: ")
+		fmt.Print(i18n.T("status.speed"))
 		if downloadSpeed > 0 {
-			fmt.Printf("%s ↓", StatusSpeedStyle.Render(formatSpeed(downloadSpeed)))
+			fmt.Printf("%s ↓", StatusSpeedStyle.Render(utils.FormatSpeed(int64(downloadSpeed))))
 		}
 		if downloadSpeed > 0 && uploadSpeed > 0 {
 			fmt.Print(" • ")
 		}
 		if uploadSpeed > 0 {
-			fmt.Printf("%s ↑", StatusSpeedStyle.Render(formatSpeed(uploadSpeed)))
+			fmt.Printf("%s ↑", StatusSpeedStyle.Render(utils.FormatSpeed(int64(uploadSpeed))))
 		}
 		fmt.Println()
 	}
 
 	// Storage
-	if freeSpace > 0 {
-		fmt.Printf("Free Space: %s\n", StatusValueStyle.Render(formatSize(statusSize(freeSpace))))
+	if q := s.DiskQuota; q != nil {
+		fmt.Println(i18n.T("status.disk_quota", StatusValueStyle.Render(utils.FormatSize(q.Used)),
+			utils.FormatSize(q.Limit), q.Percent(), StatusValueStyle.Render(utils.FormatSize(q.Free()))))
+	} else if s.FreeSpace > 0 {
+		fmt.Println(i18n.T("status.free_space", StatusValueStyle.Render(utils.FormatSize(s.FreeSpace))))
+	}
+
+	// Downloads that will run out of space before they finish
+	for _, shortage := range s.SpaceShortages {
+		fmt.Println(WarningStyle.Render(i18n.T("status.space_shortage", shortage.Path, shortage.Torrents,
+			utils.FormatSize(shortage.Remaining), utils.FormatSize(shortage.FreeSpace))))
 	}
 	fmt.Println()
 }
@@ -332,10 +461,10 @@ func PrintStatusSummary(total, downloading, seeding, paused int, downloadSpeed,
 // PrintSpeedInfo prints download/upload speeds
 func PrintSpeedItem(label string, speed int) {
 	if speed > 0 {
-		formattedSpeed := formatSpeed(speed)
+		formattedSpeed := utils.FormatSpeed(int64(speed))
 		fmt.Printf("  %s %s\n", StatusLabelStyle.Render(label+":"), StatusSpeedStyle.Render(formattedSpeed))
 	} else {
-		fmt.Printf("  %s %s\n", StatusLabelStyle.Render(label+":"), StatusInactiveStyle.Render("0 B/s"))
+		fmt.Printf("  %s %s\n", StatusLabelStyle.Render(label+":"), StatusInactiveStyle.Render(utils.FormatSpeed(0)))
 	}
 }
 
@@ -345,7 +474,7 @@ func PrintSimpleDirectoryList(breakdown map[string]service.DirectoryStatus) {
 		return
 	}
 
-	fmt.Print("Directories: ")
+	fmt.Print(i18n.T("status.directories"))
 	i := 0
 	for dir, status := range breakdown {
 		if i > 0 {
@@ -354,59 +483,490 @@ func PrintSimpleDirectoryList(breakdown map[string]service.DirectoryStatus) {
 		fmt.Printf("%s (%d)", filepath.Base(dir), status.TorrentCount)
 		i++
 		if i >= 3 { // Limit to first 3 directories
-			fmt.Printf(" + %d more", len(breakdown)-3)
+			fmt.Print(i18n.T("status.more", len(breakdown)-3))
 			break
 		}
 	}
 	fmt.Println()
 }
 
-// Helper types and functions for status display
-type statusSize int64
+// PrintTopTorrents prints a ranked table of torrents with size, ratio and upload totals
+func PrintTopTorrents(torrents []types.TorrentInfo) {
+	fmt.Printf("%4s  %10s  %7s  %10s  %s\n", "#", "Size", "Ratio", "Uploaded", "Name")
+	for i, t := range torrents {
+		fmt.Printf("%4d  %s  %7.2f  %s  %s\n",
+			i+1,
+			SizeStyle.Render(fmt.Sprintf("%10s", utils.FormatSize(t.TotalSize))),
+			t.Ratio,
+			SizeStyle.Render(fmt.Sprintf("%10s", utils.FormatSize(t.UploadedEver))),
+			utils.SanitizeString(t.Name))
+		fmt.Printf("%4s  %s\n", "", PathStyle.Render(utils.SanitizeString(t.DownloadDir)))
+	}
+}
 
-func formatSize(s statusSize) string {
-	return utils.FormatSize(int64(s))
+// PrintTorrentList prints torrents in the layout of transmission-remote --list
+func PrintTorrentList(torrents []types.TorrentInfo) {
+	fmt.Printf("%6s  %5s  %10s  %9s  %9s  %6s  %-12s  %s\n", "ID", "Done", "Have", "Up", "Down", "Ratio", "Status", "Name")
+
+	var have int64
+	var up, down int
+	for _, t := range torrents {
+		done := t.SizeWhenDone - t.LeftUntilDone
+		have += done
+		up += t.RateUpload
+		down += t.RateDownload
+		fmt.Printf("%6d  %4.0f%%  %s  %9s  %9s  %6.2f  %-12s  %s\n",
+			t.ID,
+			t.PercentDone*100,
+			SizeStyle.Render(fmt.Sprintf("%10s", utils.FormatSize(done))),
+			utils.FormatSpeed(int64(t.RateUpload)),
+			utils.FormatSpeed(int64(t.RateDownload)),
+			t.Ratio,
+			t.Status,
+			utils.SanitizeString(t.Name))
+	}
+
+	fmt.Printf("%6s  %5s  %s  %9s  %9s\n", "Sum:", "",
+		SizeStyle.Render(fmt.Sprintf("%10s", utils.FormatSize(have))), utils.FormatSpeed(int64(up)), utils.FormatSpeed(int64(down)))
 }
 
-func formatSpeed(bytesPerSecond int) string {
-	if bytesPerSecond == 0 {
-		return "0 B/s"
+// PrintTorrentDetails prints one torrent in the layout of transmission-remote --info
+func PrintTorrentDetails(t types.TorrentInfo) {
+	field := func(label, value string) {
+		fmt.Printf("  %s %s\n", StatusLabelStyle.Render(label+":"), value)
+	}
+
+	fmt.Println(StatusHeaderStyle.Render("NAME"))
+	field("Id", fmt.Sprintf("%d", t.ID))
+	field("Name", utils.SanitizeString(t.Name))
+	field("Hash", t.HashString)
+	if len(t.Labels) > 0 {
+		field("Labels", utils.SanitizeString(strings.Join(t.Labels, ", ")))
 	}
+	fmt.Println()
 
-	const unit = 1024
-	if bytesPerSecond < unit {
-		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	fmt.Println(StatusHeaderStyle.Render("TRANSFER"))
+	field("State", t.Status.String())
+	field("Location", PathStyle.Render(utils.SanitizeString(t.DownloadDir)))
+	field("Percent Done", fmt.Sprintf("%.1f%%", t.PercentDone*100))
+	field("Have", utils.FormatSize(t.SizeWhenDone-t.LeftUntilDone))
+	field("Total size", utils.FormatSize(t.TotalSize))
+	field("Downloaded", utils.FormatSize(t.DownloadedEver))
+	field("Uploaded", utils.FormatSize(t.UploadedEver))
+	field("Ratio", fmt.Sprintf("%.2f", t.Ratio))
+	field("Speed", fmt.Sprintf("%s ↓ / %s ↑", utils.FormatSpeed(int64(t.RateDownload)), utils.FormatSpeed(int64(t.RateUpload))))
+	if t.SecondsSeeding > 0 {
+		field("Seeding Time", utils.FormatDuration(time.Duration(t.SecondsSeeding)*time.Second))
+	}
+	now := time.Now()
+	if t.AddedDate > 0 {
+		added := time.Unix(t.AddedDate, 0)
+		field("Date added", fmt.Sprintf("%s (%s)", added.Format(time.DateTime), utils.FormatRelativeTime(added, now)))
 	}
+	if t.DoneDate > 0 {
+		done := time.Unix(t.DoneDate, 0)
+		field("Date finished", fmt.Sprintf("%s (%s)", done.Format(time.DateTime), utils.FormatRelativeTime(done, now)))
+	}
+}
 
-	div, exp := int64(unit), 0
-	for n := bytesPerSecond / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+// PrintSessionStats prints current and cumulative session statistics
+func PrintSessionStats(current, cumulative *types.SessionStats) {
+	section := func(title string, s *types.SessionStats) {
+		if s == nil {
+			return
+		}
+		fmt.Println(StatusHeaderStyle.Render(title))
+		fmt.Printf("  %s %s\n", StatusLabelStyle.Render("Uploaded:"), utils.FormatSize(s.UploadedBytes))
+		fmt.Printf("  %s %s\n", StatusLabelStyle.Render("Downloaded:"), utils.FormatSize(s.DownloadedBytes))
+		if s.DownloadedBytes > 0 {
+			fmt.Printf("  %s %.2f\n", StatusLabelStyle.Render("Ratio:"), float64(s.UploadedBytes)/float64(s.DownloadedBytes))
+		}
+		fmt.Printf("  %s %s\n", StatusLabelStyle.Render("Duration:"), utils.FormatDuration(time.Duration(s.SecondsActive)*time.Second))
 	}
 
-	units := []string{"KB/s", "MB/s", "GB/s", "TB/s"}
-	if exp >= len(units) {
-		exp = len(units) - 1
+	section("CURRENT SESSION", current)
+	if current != nil && cumulative != nil {
+		fmt.Println()
 	}
+	section("TOTAL", cumulative)
+	if cumulative != nil && cumulative.SessionCount > 0 {
+		fmt.Printf("  %s %d\n", StatusLabelStyle.Render("Started:"), cumulative.SessionCount)
+	}
+}
 
-	value := float64(bytesPerSecond) / float64(div)
-	return fmt.Sprintf("%.1f %s", value, units[exp])
+// PrintLabelStatistics prints a per-label statistics table
+func PrintLabelStatistics(stats []service.LabelStatistics) {
+	fmt.Printf("%-20s  %8s  %10s  %7s  %10s  %10s\n", "Label", "Torrents", "Size", "Ratio", "Down", "Up")
+	for _, l := range stats {
+		fmt.Printf("%s  %8d  %s  %7.2f  %s  %s\n",
+			StatusLabelStyle.Render(fmt.Sprintf("%-20s", utils.SanitizeString(l.Label))),
+			l.TorrentCount,
+			SizeStyle.Render(fmt.Sprintf("%10s", utils.FormatSize(l.TotalSize))),
+			l.Ratio,
+			StatusSpeedStyle.Render(fmt.Sprintf("%10s", utils.FormatSpeed(int64(l.DownloadSpeed)))),
+			StatusSpeedStyle.Render(fmt.Sprintf("%10s", utils.FormatSpeed(int64(l.UploadSpeed)))))
+	}
 }
 
-func formatDuration(seconds int) string {
-	if seconds == 0 {
-		return "0s"
+// PrintTimeline prints a textual histogram of torrents added per period
+func PrintTimeline(buckets []service.TimelineBucket) {
+	maxCount := 0
+	for _, b := range buckets {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
 	}
 
-	hours := seconds / 3600
-	minutes := (seconds % 3600) / 60
-	secs := seconds % 60
+	for _, b := range buckets {
+		barLen := 0
+		if maxCount > 0 {
+			barLen = b.Count * constants.HistogramWidth / maxCount
+		}
+		if b.Count > 0 && barLen == 0 {
+			barLen = 1
+		}
 
-	if hours > 0 {
-		return fmt.Sprintf("%dh %dm %ds", hours, minutes, secs)
-	} else if minutes > 0 {
-		return fmt.Sprintf("%dm %ds", minutes, secs)
-	} else {
-		return fmt.Sprintf("%ds", secs)
+		fmt.Printf("%-8s %s %4d  %s\n",
+			b.Label,
+			StatusActiveStyle.Render(fmt.Sprintf("%-*s", constants.HistogramWidth, strings.Repeat("█", barLen))),
+			b.Count,
+			SizeStyle.Render(utils.FormatSize(b.TotalSize)))
+	}
+}
+
+// PrintDirectoryUsage prints a proportional bar chart of the space used by
+// torrents in each directory, largest first
+func PrintDirectoryUsage(dirs []utils.DirectoryInfo) {
+	sorted := append([]utils.DirectoryInfo(nil), dirs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TotalSize > sorted[j].TotalSize })
+
+	var total int64
+	width := 0
+	for _, d := range sorted {
+		total += d.TotalSize
+		width = max(width, len(d.Path))
+	}
+
+	for _, d := range sorted {
+		fraction := 0.0
+		if total > 0 {
+			fraction = float64(d.TotalSize) / float64(total)
+		}
+		fmt.Printf("%-*s %s %10s %5.1f%%  (%d torrents)\n",
+			width, d.Path,
+			StatusActiveStyle.Render(fmt.Sprintf("%-*s", constants.HistogramWidth, usageBar(fraction, constants.HistogramWidth))),
+			SizeStyle.Render(utils.FormatSize(d.TotalSize)),
+			fraction*100,
+			d.Count)
+	}
+
+	fmt.Printf("%-*s %-*s %10s\n", width, "Total", constants.HistogramWidth, "", utils.FormatSize(total))
+}
+
+// PrintMountGroups prints the torrent count, size and free space of each
+// filesystem, followed by its directories when showDirs is set
+func PrintMountGroups(groups []utils.MountGroup, showDirs bool) {
+	for _, g := range groups {
+		mount := g.MountPoint
+		if mount == "" {
+			mount = "(unknown filesystem)"
+		}
+		line := fmt.Sprintf("%d directories • %d torrents • %s", len(g.Dirs), g.Count, utils.FormatSize(g.TotalSize))
+		if g.Free >= 0 {
+			line += " • " + i18n.T("status.compact_free", utils.FormatSize(g.Free))
+		}
+		fmt.Printf("%s  %s\n", PathStyle.Render(mount), StatusValueStyle.Render(line))
+
+		if !showDirs {
+			continue
+		}
+		for _, d := range g.Dirs {
+			fmt.Printf("  %s (%d torrents, %s)\n", d.Path, d.Count, utils.FormatSize(d.TotalSize))
+		}
+	}
+}
+
+// PrintTorrentSections prints each section's name and subtotals followed by
+// its torrent paths, then the total over all sections
+func PrintTorrentSections(sections []service.TorrentSection) {
+	var count int
+	var size int64
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  %s\n",
+			DirectoryHeaderStyle.Render(utils.SanitizeString(section.Name)),
+			StatusValueStyle.Render(fmt.Sprintf("%d torrents • %s", section.Count, utils.FormatSize(section.TotalSize))))
+		for _, path := range section.Paths {
+			fmt.Printf("  %s\n", PathStyle.Render(path))
+		}
+		count += section.Count
+		size += section.TotalSize
+	}
+	if len(sections) > 1 {
+		fmt.Println()
+		fmt.Printf("%s  %s\n", SummaryStyle.Render(fmt.Sprintf("%d groups", len(sections))),
+			StatusValueStyle.Render(fmt.Sprintf("%d torrents • %s", count, utils.FormatSize(size))))
 	}
 }
+
+// PrintTrashItems prints trashed items with their original path and deletion
+// time, naming the trash of items kept in another trash than dir
+func PrintTrashItems(items []utils.TrashItem, dir string, now time.Time) {
+	for _, item := range items {
+		fmt.Printf("%s  %s\n", WarningStyle.Render(item.Name), PathStyle.Render(item.OriginalPath))
+		fmt.Printf("  deleted %s (%s)", item.DeletedAt.Format(time.DateTime), utils.FormatRelativeTime(item.DeletedAt, now))
+		if item.Trash != dir {
+			fmt.Printf(" • in %s", PathStyle.Render(item.Trash))
+		}
+		fmt.Println()
+	}
+}
+
+// PrintStagedBatches prints staged deletion batches with their items
+func PrintStagedBatches(batches []*utils.StagedBatch, now time.Time) {
+	for _, b := range batches {
+		fmt.Printf("%s  %d items, %s\n", WarningStyle.Render(b.ID), len(b.Items), SizeStyle.Render(utils.FormatSize(b.Size())))
+		fmt.Printf("  staged %s (%s)\n", b.Created.Format(time.DateTime), utils.FormatRelativeTime(b.Created, now))
+		for _, item := range b.Items {
+			fmt.Printf("  • %s\n", PathStyle.Render(item.OriginalPath))
+		}
+	}
+}
+
+// usageBar renders fraction of width cells using eighth blocks for sub-cell precision
+func usageBar(fraction float64, width int) string {
+	eighths := int(fraction*float64(width*8) + 0.5)
+	if fraction > 0 && eighths == 0 {
+		eighths = 1
+	}
+
+	bar := strings.Repeat("█", eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[rest-1])
+	}
+	return bar
+}
+
+// PrintStuckTorrents prints torrents whose verification is not progressing
+func PrintStuckTorrents(stuck []service.StuckTorrent) {
+	for _, st := range stuck {
+		fmt.Printf("%s %s [%s] - %s\n",
+			WarningStyle.Render(fmt.Sprintf("#%d", st.Torrent.ID)),
+			utils.SanitizeString(st.Torrent.Name),
+			st.Torrent.Status,
+			st.Reason)
+	}
+}
+
+// PrintTorrentAvailability prints one line per torrent with the share of its
+// remaining data the swarm has, marking torrents that cannot finish
+func PrintTorrentAvailability(report []service.TorrentAvailability) {
+	for _, a := range report {
+		style := SuccessStyle
+		if a.Dead() {
+			style = ErrorStyle
+		}
+		fmt.Printf("%6d  %s  %s  %s\n",
+			a.ID,
+			style.Render(fmt.Sprintf("%6.1f%%", a.Fraction()*100)),
+			SizeStyle.Render(fmt.Sprintf("%s of %s left available, %d peers (%d seeding)",
+				utils.FormatSize(a.Available), utils.FormatSize(a.Remaining), a.Peers, a.Seeders)),
+			utils.SanitizeString(a.Name))
+	}
+}
+
+// PrintSeedTimes prints one line per torrent with its seeding time against
+// its goal, marking torrents that have not met it yet
+func PrintSeedTimes(report []service.TorrentSeedTime) {
+	for _, st := range report {
+		state := SuccessStyle.Render(fmt.Sprintf("%-14s", "goal met"))
+		if !st.GoalMet() {
+			state = WarningStyle.Render(fmt.Sprintf("%-14s", utils.FormatDuration(st.Remaining())+" left"))
+		}
+		fmt.Printf("%6d  %14s  %s  %s  %s\n",
+			st.ID,
+			utils.FormatDuration(time.Duration(st.SecondsSeeding)*time.Second),
+			state,
+			StatusLabelStyle.Render(fmt.Sprintf("%-24s", utils.SanitizeString(st.Tracker))),
+			utils.SanitizeString(st.Name))
+	}
+}
+
+// PrintTrackerSeedTimes prints a per-tracker seed-time table
+func PrintTrackerSeedTimes(stats []service.TrackerSeedTime) {
+	fmt.Printf("%-30s  %8s  %8s  %14s  %14s\n", "Tracker", "Torrents", "Goal met", "Average", "Total")
+	for _, st := range stats {
+		style := SuccessStyle
+		if st.GoalMet < st.Torrents {
+			style = WarningStyle
+		}
+		fmt.Printf("%s  %8d  %s  %14s  %14s\n",
+			StatusLabelStyle.Render(fmt.Sprintf("%-30s", utils.SanitizeString(st.Tracker))),
+			st.Torrents,
+			style.Render(fmt.Sprintf("%8d", st.GoalMet)),
+			utils.FormatDuration(st.Average()),
+			utils.FormatDuration(time.Duration(st.SecondsSeeding)*time.Second))
+	}
+}
+
+// PrintOrphanTorrents lists torrents whose data is missing, with the absent
+// files of partially deleted ones
+func PrintOrphanTorrents(orphans []service.OrphanTorrent) {
+	for _, o := range orphans {
+		state := "missing"
+		if o.Partial() {
+			state = fmt.Sprintf("%d files missing", len(o.MissingFiles))
+		}
+		fmt.Printf("%6d  %s  %s\n", o.ID, ErrorStyle.Render(fmt.Sprintf("%-16s", state)), PathStyle.Render(utils.SanitizeString(o.Path)))
+		for _, f := range o.MissingFiles {
+			fmt.Printf("%6s  %16s  %s\n", "", "", utils.SanitizeString(f))
+		}
+	}
+}
+
+// PrintDaemonInfo prints the daemon version and whether it supports each
+// feature that needs a minimum RPC version
+func PrintDaemonInfo(d *service.DaemonInfo) {
+	version := d.Version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Printf("daemon:  %s\n", utils.SanitizeString(version))
+	if d.RPCVersion == 0 {
+		fmt.Println("rpc:     not versioned, all features supported")
+		return
+	}
+	fmt.Printf("rpc:     %d (minimum %d)\n", d.RPCVersion, d.RPCVersionMinimum)
+	for _, f := range d.Features {
+		if f.Supported {
+			fmt.Printf("  %s %s\n", SuccessStyle.Render("✓"), f.Name)
+		} else {
+			fmt.Printf("  %s %s %s\n", ErrorStyle.Render("✗"), f.Name, WarningStyle.Render(fmt.Sprintf("(needs RPC %d)", f.MinRPCVersion)))
+		}
+	}
+}
+
+// PrintCompareDiff prints a compare result as a unified diff between the local
+// directory and Transmission: "-" local only, "+" Transmission only, " " both
+func PrintCompareDiff(dir string, r *service.CompareResult) {
+	type diffLine struct {
+		path string
+		mark string
+	}
+
+	lines := make([]diffLine, 0, len(r.LocalOnly)+len(r.InTransmissionOnly)+len(r.InBoth))
+	for _, path := range r.LocalOnly {
+		lines = append(lines, diffLine{path, "-"})
+	}
+	for _, path := range r.InTransmissionOnly {
+		lines = append(lines, diffLine{path, "+"})
+	}
+	for _, path := range r.InBoth {
+		lines = append(lines, diffLine{path, " "})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].path < lines[j].path })
+
+	fmt.Println(ErrorStyle.Render("--- local " + utils.SanitizeString(dir)))
+	fmt.Println(SuccessStyle.Render("+++ transmission"))
+	fmt.Println(InfoStyle.Render(fmt.Sprintf("@@ -%d +%d @@", r.TotalLocal, r.TotalTransmission)))
+	for _, line := range lines {
+		text := line.mark + " " + utils.SanitizeString(line.path)
+		switch line.mark {
+		case "-":
+			fmt.Println(ErrorStyle.Render(text))
+		case "+":
+			fmt.Println(SuccessStyle.Render(text))
+		default:
+			fmt.Println(SizeStyle.Render(text))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d local only, %d Transmission only, %d in both\n",
+		len(r.LocalOnly), len(r.InTransmissionOnly), len(r.InBoth))
+}
+
+// PrintBenchmarkResult prints throughput figures from a bench run
+func PrintBenchmarkResult(r *service.BenchmarkResult) {
+	iterations := time.Duration(r.Iterations)
+	fmt.Printf("%s %d torrents, %s per iteration (%.0f torrents/s)\n",
+		StatusLabelStyle.Render("Index:"), r.Torrents, r.IndexTime/iterations, r.TorrentsPerSecond())
+	fmt.Printf("%s %d items in %d directories, %s per iteration (%.0f items/s)\n",
+		StatusLabelStyle.Render("Scan: "), r.Items, r.Directories, r.ScanTime/iterations, r.ItemsPerSecond())
+	fmt.Printf("%s %s of unmatched items sized (%s/s)\n",
+		StatusLabelStyle.Render("Size: "), utils.FormatSize(r.SizedBytes), utils.FormatSize(int64(r.BytesPerSecond())))
+	fmt.Printf("%s %d\n", StatusLabelStyle.Render("Runs: "), r.Iterations)
+}
+
+// Output formats selectable with --format
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ValidateFormat checks that format is a supported output format
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (use %s or %s)", format, FormatText, FormatJSON)
+	}
+}
+
+// Levels of per-item detail selectable with check --detail
+const (
+	DetailNone    = "none"
+	DetailSummary = "summary"
+	DetailMissing = "missing"
+	DetailFull    = "full"
+)
+
+// ValidateDetail checks that detail is a supported detail level
+func ValidateDetail(detail string) error {
+	switch detail {
+	case DetailNone, DetailSummary, DetailMissing, DetailFull:
+		return nil
+	default:
+		return fmt.Errorf("unsupported detail level %q (use %s, %s, %s or %s)", detail, DetailNone, DetailSummary, DetailMissing, DetailFull)
+	}
+}
+
+// PrintJSON writes v to w as indented JSON
+func PrintJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// PlannedAction describes one change a command would make
+type PlannedAction struct {
+	Verb   string
+	Target string
+	Detail string
+}
+
+// PrintPlannedActions prints a header followed by a numbered list of planned
+// actions to w
+func PrintPlannedActions(w io.Writer, header string, actions []PlannedAction) {
+	PrintWarning(header)
+	for i, a := range actions {
+		line := fmt.Sprintf("  %d. %s %s", i+1, a.Verb, a.Target)
+		if a.Detail != "" {
+			line += " " + a.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// PrintDryRunStart announces that a command is running in preview mode
+func PrintDryRunStart() {
+	PrintInfo(i18n.T("dryrun.start"))
+}
+
+// PrintDryRunComplete announces that a preview finished without changing anything
+func PrintDryRunComplete() {
+	PrintInfo(i18n.T("dryrun.complete"))
+}
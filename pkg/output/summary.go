@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"peerless/pkg/utils"
+)
+
+// RunSummary is the outcome of a check run, printed as its final line so
+// cron mails are meaningful at a glance
+type RunSummary struct {
+	Time        time.Time
+	Host        string
+	Dirs        []string
+	Missing     int
+	MissingSize int64
+
+	// Action describes what was done with the missing items
+	Action string
+}
+
+// String formats the summary as a single line of key=value pairs. The format
+// is not translated so that it can be filtered reliably.
+func (s RunSummary) String() string {
+	return fmt.Sprintf("[%s] peerless check host=%s dirs=%s missing=%d size=%s bytes=%d action=%s",
+		s.Time.Format("2006-01-02 15:04:05 -0700"),
+		summaryValue(s.Host),
+		summaryValue(strings.Join(s.Dirs, ",")),
+		s.Missing,
+		summaryValue(utils.FormatSize(s.MissingSize)),
+		s.MissingSize,
+		summaryValue(s.Action))
+}
+
+// PrintRunSummary prints the summary line to w, or to stderr in quiet mode,
+// where stdout only carries data. Cron mails both, so the line is always part
+// of the mail.
+func PrintRunSummary(w io.Writer, s RunSummary) {
+	if quiet {
+		w = os.Stderr
+	}
+	fmt.Fprintln(w, s.String())
+}
+
+// summaryValue quotes v when it is empty or contains spaces or quotes
+func summaryValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureFile redirects *f to a pipe while fn runs and returns what was written
+func captureFile(t *testing.T, f **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	original := *f
+	*f = w
+	defer func() { *f = original }()
+
+	fn()
+	require.NoError(t, w.Close())
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPrintRunSummary(t *testing.T) {
+	summary := RunSummary{
+		Time:        time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Host:        "nas",
+		Dirs:        []string{"/downloads", "/media/tv shows"},
+		Missing:     2,
+		MissingSize: 2048,
+		Action:      "report",
+	}
+	line := `[2026-03-01 12:00:00 +0000] peerless check host=nas dirs="/downloads,/media/tv shows" missing=2 size="2.00 KB" bytes=2048 action=report` + "\n"
+
+	t.Run("writer", func(t *testing.T) {
+		var out strings.Builder
+		PrintRunSummary(&out, summary)
+		assert.Equal(t, line, out.String())
+	})
+
+	t.Run("stderr in quiet mode", func(t *testing.T) {
+		level := Logger.GetLevel()
+		defer func() {
+			SetQuiet(false)
+			Logger.SetLevel(level)
+		}()
+		SetQuiet(true)
+
+		var out strings.Builder
+		stderr := captureFile(t, &os.Stderr, func() { PrintRunSummary(&out, summary) })
+		assert.Empty(t, out.String())
+		assert.Equal(t, line, stderr)
+	})
+}
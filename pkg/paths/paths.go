@@ -0,0 +1,123 @@
+// Package paths resolves where peerless keeps its files on each OS: the XDG
+// base directories on Linux and BSD, ~/Library on macOS and %APPDATA% or
+// %LOCALAPPDATA% on Windows.
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"peerless/pkg/constants"
+)
+
+// ConfigDir returns the directory of the config file, e.g.
+// $XDG_CONFIG_HOME/peerless, ~/Library/Application Support/peerless or
+// %APPDATA%\peerless
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user config directory: %w", err)
+	}
+	return filepath.Join(dir, constants.ConfigDirName), nil
+}
+
+// CacheDir returns the directory for data that can be rebuilt at any time,
+// e.g. $XDG_CACHE_HOME/peerless, ~/Library/Caches/peerless or
+// %LOCALAPPDATA%\peerless
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user cache directory: %w", err)
+	}
+	return filepath.Join(dir, constants.ConfigDirName), nil
+}
+
+// StateDir returns the directory for history that should survive restarts but
+// is not configuration, e.g. $XDG_STATE_HOME/peerless (~/.local/state),
+// ~/Library/Application Support/peerless or %LOCALAPPDATA%\peerless
+func StateDir() (string, error) {
+	var dir string
+	switch runtime.GOOS {
+	case "windows":
+		dir = os.Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("failed to determine user state directory: %LocalAppData% is not defined")
+		}
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine user state directory: %w", err)
+		}
+		dir = filepath.Join(home, "Library", "Application Support")
+	default:
+		var err error
+		if dir, err = xdgDir("XDG_STATE_HOME", ".local", "state"); err != nil {
+			return "", fmt.Errorf("failed to determine user state directory: %w", err)
+		}
+	}
+	return filepath.Join(dir, constants.ConfigDirName), nil
+}
+
+// DataHome returns the user's base data directory shared by all applications,
+// $XDG_DATA_HOME or ~/.local/share, where e.g. the freedesktop.org trash lives
+func DataHome() (string, error) {
+	dir, err := xdgDir("XDG_DATA_HOME", ".local", "share")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user data directory: %w", err)
+	}
+	return dir, nil
+}
+
+// StateFile returns the path of the named file in StateDir. A file of that
+// name left in ConfigDir by earlier versions is moved there first.
+func StateFile(name string) (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(stateDir, name)
+
+	configDir, err := ConfigDir()
+	if err != nil || configDir == stateDir {
+		return path, nil
+	}
+	if err := moveLegacy(filepath.Join(configDir, name), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// moveLegacy moves legacy to path when legacy exists and path does not
+func moveLegacy(legacy, path string) error {
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Lstat(legacy); err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", legacy, path, err)
+	}
+	return nil
+}
+
+// xdgDir returns the directory named by the XDG variable env, or the default
+// below the home directory. Relative values are invalid per the specification
+// and ignored.
+func xdgDir(env string, defaultBelowHome ...string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home}, defaultBelowHome...)...), nil
+}
@@ -0,0 +1,75 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXDGDirs(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" || runtime.GOOS == "plan9" {
+		t.Skip("XDG base directories are only used on Linux and BSD")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Run("defaults below home", func(t *testing.T) {
+		for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME"} {
+			t.Setenv(env, "")
+		}
+		assertDir(t, ConfigDir, filepath.Join(home, ".config", "peerless"))
+		assertDir(t, CacheDir, filepath.Join(home, ".cache", "peerless"))
+		assertDir(t, StateDir, filepath.Join(home, ".local", "state", "peerless"))
+		assertDir(t, DataHome, filepath.Join(home, ".local", "share"))
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/srv/state")
+		t.Setenv("XDG_DATA_HOME", "/srv/data")
+		assertDir(t, StateDir, "/srv/state/peerless")
+		assertDir(t, DataHome, "/srv/data")
+	})
+
+	t.Run("relative values are ignored", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "state")
+		assertDir(t, StateDir, filepath.Join(home, ".local", "state", "peerless"))
+	})
+}
+
+func TestStateFile_MovesLegacyFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" || runtime.GOOS == "plan9" {
+		t.Skip("config and state directories are set through XDG variables here")
+	}
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+
+	legacy := filepath.Join(root, "config", "peerless", "history.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0755))
+	require.NoError(t, os.WriteFile(legacy, []byte("{}"), 0644))
+
+	path, err := StateFile("history.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "state", "peerless", "history.json"), path)
+	assert.FileExists(t, path)
+	assert.NoFileExists(t, legacy)
+
+	// An existing state file is never replaced
+	require.NoError(t, os.WriteFile(legacy, []byte("old"), 0644))
+	_, err = StateFile("history.json")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+}
+
+func assertDir(t *testing.T, fn func() (string, error), want string) {
+	t.Helper()
+	got, err := fn()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"peerless/pkg/store"
+)
+
+// CheckArchiveBucket holds one record per check run in the state store, keyed
+// by the time of the run so keys sort oldest first
+const CheckArchiveBucket = "checks"
+
+// archiveKeyLayout formats the key of an archived check with a fixed width
+const archiveKeyLayout = "20060102T150405.000000000Z"
+
+// ArchivedCheck is the result of one check run as kept in the archive
+type ArchivedCheck struct {
+	Time   time.Time `json:"time"`
+	Host   string    `json:"host"`
+	Action string    `json:"action"`
+	CheckReport
+}
+
+// ArchiveCheck stores run in st and removes all but the newest keep runs
+func ArchiveCheck(st store.Store, run ArchivedCheck, keep int) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode check result: %w", err)
+	}
+	if err := st.Put(CheckArchiveBucket, run.Time.UTC().Format(archiveKeyLayout), data); err != nil {
+		return fmt.Errorf("failed to archive check result: %w", err)
+	}
+
+	keys, err := st.Keys(CheckArchiveBucket)
+	if err != nil {
+		return fmt.Errorf("failed to list archived check results: %w", err)
+	}
+	for _, key := range keys[:max(len(keys)-keep, 0)] {
+		if err := st.Delete(CheckArchiveBucket, key); err != nil {
+			return fmt.Errorf("failed to remove archived check result %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// LoadCheckArchive returns the archived check runs, oldest first
+func LoadCheckArchive(st store.Store) ([]ArchivedCheck, error) {
+	keys, err := st.Keys(CheckArchiveBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived check results: %w", err)
+	}
+	runs := make([]ArchivedCheck, 0, len(keys))
+	for _, key := range keys {
+		data, err := st.Get(CheckArchiveBucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archived check result %s: %w", key, err)
+		}
+		var run ArchivedCheck
+		if err := json.Unmarshal(data, &run); err != nil {
+			return nil, fmt.Errorf("failed to parse archived check result %s: %w", key, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
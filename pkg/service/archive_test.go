@@ -0,0 +1,45 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/store"
+)
+
+func TestCheckArchive(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "peerless")
+	st := store.NewFileStore(dir)
+
+	runs, err := LoadCheckArchive(st)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		run := ArchivedCheck{
+			Time:   start.Add(time.Duration(i) * time.Hour),
+			Host:   "nas",
+			Action: "report",
+			CheckReport: CheckReport{
+				Directories:  []string{"/downloads"},
+				TotalItems:   10,
+				TotalFound:   10 - i,
+				MissingPaths: make([]string, i),
+			},
+		}
+		require.NoError(t, ArchiveCheck(st, run, 3))
+	}
+
+	runs, err = LoadCheckArchive(st)
+	require.NoError(t, err)
+	require.Len(t, runs, 3, "only the newest runs are kept")
+	assert.True(t, runs[0].Time.Equal(start.Add(time.Hour)))
+	assert.True(t, runs[2].Time.Equal(start.Add(3*time.Hour)))
+	assert.Equal(t, 7, runs[2].TotalFound)
+	assert.Equal(t, "nas", runs[2].Host)
+	assert.Equal(t, []string{"/downloads"}, runs[2].Directories)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"peerless/pkg/types"
+)
+
+// LabelChange lists the labels rules add to a torrent
+type LabelChange struct {
+	Torrent types.TorrentInfo
+	Added   []string
+}
+
+// Labels returns the torrent's labels after the change
+func (c LabelChange) Labels() []string {
+	labels := make([]string, 0, len(c.Torrent.Labels)+len(c.Added))
+	labels = append(labels, c.Torrent.Labels...)
+	return append(labels, c.Added...)
+}
+
+// PlanLabelChanges returns the labels rules would add to each torrent. Existing
+// labels are kept, and torrents that already carry every matching label are skipped.
+func PlanLabelChanges(torrents []types.TorrentInfo, rules *types.LabelRules) []LabelChange {
+	var changes []LabelChange
+	for _, t := range torrents {
+		has := make(map[string]bool, len(t.Labels))
+		for _, label := range t.Labels {
+			has[label] = true
+		}
+
+		var added []string
+		for _, label := range rules.LabelsFor(t) {
+			if !has[label] {
+				added = append(added, label)
+			}
+		}
+		if len(added) > 0 {
+			changes = append(changes, LabelChange{Torrent: t, Added: added})
+		}
+	}
+	return changes
+}
+
+// ApplyLabelChanges sets the new labels on each torrent and returns how many were updated
+func (s *TorrentService) ApplyLabelChanges(ctx context.Context, changes []LabelChange) (int, error) {
+	for i, c := range changes {
+		if err := s.client.SetTorrentLabels(ctx, []int{c.Torrent.ID}, c.Labels()); err != nil {
+			return i, fmt.Errorf("failed to label torrent %q: %w", c.Torrent.Name, err)
+		}
+	}
+	return len(changes), nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAndApplyLabelChanges(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`
+rules:
+  - label: movies
+    download-dir: /downloads/movies
+  - label: hd
+    name: 1080p
+`), 0644))
+	rules, err := types.LoadLabelRules(rulesPath)
+	require.NoError(t, err)
+
+	torrents := []types.TorrentInfo{
+		{ID: 1, Name: "A.1080p", DownloadDir: "/downloads/movies", Labels: []string{"keep"}},
+		{ID: 2, Name: "B", DownloadDir: "/downloads/movies", Labels: []string{"movies"}},
+		{ID: 3, Name: "C", DownloadDir: "/downloads/tv"},
+	}
+
+	changes := PlanLabelChanges(torrents, rules)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 1, changes[0].Torrent.ID)
+	assert.Equal(t, []string{"movies", "hd"}, changes[0].Added)
+	assert.Equal(t, []string{"keep", "movies", "hd"}, changes[0].Labels())
+
+	var captured struct {
+		Method    string `json:"method"`
+		Arguments struct {
+			IDs    []int    `json:"ids"`
+			Labels []string `json:"labels"`
+		} `json:"arguments"`
+	}
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "s"}), nil
+			}
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &captured))
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	updated, err := service.ApplyLabelChanges(context.Background(), changes)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, "torrent-set", captured.Method)
+	assert.Equal(t, []int{1}, captured.Arguments.IDs)
+	assert.Equal(t, []string{"keep", "movies", "hd"}, captured.Arguments.Labels)
+}
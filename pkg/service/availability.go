@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+// availabilityFields are requested on top of the usual torrent fields
+var availabilityFields = []string{"desiredAvailable", "peersConnected", "peersSendingToUs"}
+
+// TorrentAvailability tells how much of a downloading torrent's remaining
+// data the connected peers can supply
+type TorrentAvailability struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Peers int    `json:"peers"`
+
+	// Seeders is the number of peers sending data to us
+	Seeders int `json:"seeders"`
+
+	// Remaining is the wanted data not yet downloaded
+	Remaining int64 `json:"remaining"`
+
+	// Available is the part of Remaining the connected peers have
+	Available int64 `json:"available"`
+}
+
+// Fraction returns the share of the remaining data that is available
+func (a TorrentAvailability) Fraction() float64 {
+	if a.Remaining <= 0 {
+		return 1
+	}
+	return float64(a.Available) / float64(a.Remaining)
+}
+
+// Dead reports whether the connected peers lack part of the remaining data,
+// so the download cannot finish with the current swarm
+func (a TorrentAvailability) Dead() bool {
+	return a.Available < a.Remaining
+}
+
+// Availability reports the availability of the downloading torrents matching
+// filters, least available first. Stopped and queued torrents are left out,
+// since they have no peers to measure against.
+func (s *TorrentService) Availability(ctx context.Context, filters ...TorrentFilter) ([]TorrentAvailability, error) {
+	fields := append(append([]string{}, client.TorrentFields...), availabilityFields...)
+	torrents, err := s.client.GetTorrentsWithOptions(ctx, client.TorrentFetchOptions{Fields: fields})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	report := make([]TorrentAvailability, 0)
+	for _, t := range torrents {
+		if t.Status != types.StatusDownloading || t.IsComplete() || !matchesFilters(t, filters) {
+			continue
+		}
+		report = append(report, TorrentAvailability{
+			ID:        t.ID,
+			Name:      t.Name,
+			Peers:     t.PeersConnected,
+			Seeders:   t.PeersSendingToUs,
+			Remaining: t.LeftUntilDone,
+			Available: min(t.DesiredAvailable, t.LeftUntilDone),
+		})
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].Fraction() < report[j].Fraction()
+	})
+	return report, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestAvailability(t *testing.T) {
+	mock := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Healthy", "downloadDir": "/downloads", "status": 4, "percentDone": 0.5,
+			 "leftUntilDone": 1000, "desiredAvailable": 1000, "peersConnected": 12, "peersSendingToUs": 3},
+			{"id": 2, "name": "Dead", "downloadDir": "/downloads", "status": 4, "percentDone": 0.9,
+			 "leftUntilDone": 1000, "desiredAvailable": 250, "peersConnected": 2, "peersSendingToUs": 0},
+			{"id": 3, "name": "No peers", "downloadDir": "/other", "status": 4, "percentDone": 0.1,
+			 "leftUntilDone": 500, "desiredAvailable": 0, "peersConnected": 0},
+			{"id": 4, "name": "Stopped", "downloadDir": "/downloads", "status": 0, "percentDone": 0.2, "leftUntilDone": 800},
+			{"id": 5, "name": "Seeding", "downloadDir": "/downloads", "status": 6, "percentDone": 1.0}
+		]}, "result": "success"}`,
+	})
+	svc := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mock))
+
+	t.Run("least available first", func(t *testing.T) {
+		report, err := svc.Availability(context.Background())
+		require.NoError(t, err)
+		require.Len(t, report, 3, "only active downloads are reported")
+
+		assert.Equal(t, "No peers", report[0].Name)
+		assert.Equal(t, "Dead", report[1].Name)
+		assert.InDelta(t, 0.25, report[1].Fraction(), 0.001)
+		assert.True(t, report[1].Dead())
+		assert.Equal(t, 2, report[1].Peers)
+
+		assert.Equal(t, "Healthy", report[2].Name)
+		assert.False(t, report[2].Dead())
+		assert.Equal(t, 3, report[2].Seeders)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		report, err := svc.Availability(context.Background(), DirectoryFilter("/other"))
+		require.NoError(t, err)
+		require.Len(t, report, 1)
+		assert.Equal(t, 3, report[0].ID)
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"peerless/pkg/types"
+)
+
+// BenchmarkResult reports timings of the matching and scanning stages of a check
+type BenchmarkResult struct {
+	Iterations  int           `json:"iterations"`
+	Torrents    int           `json:"torrents"`
+	Directories int           `json:"directories"`
+	Items       int           `json:"items"`
+	SizedBytes  int64         `json:"sized_bytes"`
+	IndexTime   time.Duration `json:"index_ns"`
+	ScanTime    time.Duration `json:"scan_ns"`
+}
+
+// TorrentsPerSecond is the index build throughput
+func (r *BenchmarkResult) TorrentsPerSecond() float64 {
+	return perSecond(float64(r.Torrents*r.Iterations), r.IndexTime)
+}
+
+// ItemsPerSecond is the directory scan throughput
+func (r *BenchmarkResult) ItemsPerSecond() float64 {
+	return perSecond(float64(r.Items*r.Iterations), r.ScanTime)
+}
+
+// BytesPerSecond is the rate at which unmatched items were sized
+func (r *BenchmarkResult) BytesPerSecond() float64 {
+	return perSecond(float64(r.SizedBytes*int64(r.Iterations)), r.ScanTime)
+}
+
+func perSecond(count float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return count / elapsed.Seconds()
+}
+
+// Benchmark runs the index and scan stages of a check iterations times against
+// saved torrents, without contacting Transmission. Item and byte counts are
+// taken from a single iteration; times are summed over all of them.
+func Benchmark(ctx context.Context, torrents []types.TorrentInfo, dirs []string, iterations int) (*BenchmarkResult, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("iterations must be at least 1, got %d", iterations)
+	}
+
+	s := &TorrentService{}
+	result := &BenchmarkResult{
+		Iterations:  iterations,
+		Torrents:    len(torrents),
+		Directories: len(dirs),
+	}
+
+	for i := 0; i < iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		index := newTorrentIndex(torrents, nil)
+		result.IndexTime += time.Since(start)
+
+		var items int
+		var sized int64
+		opts := CheckOptions{Progress: func(dir string, current, total int, size int64) {
+			items++
+			sized += size
+		}}
+
+		start = time.Now()
+		for _, dir := range dirs {
+			if _, err := s.checkSingleDirectory(ctx, dir, index, opts); err != nil {
+				return nil, fmt.Errorf("failed to check directory %s: %w", dir, err)
+			}
+		}
+		result.ScanTime += time.Since(start)
+
+		result.Items = items
+		result.SizedBytes = sized
+	}
+
+	return result, nil
+}
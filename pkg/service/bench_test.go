@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"peerless/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmark(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Movie1"), []byte("movie"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Local"), []byte("local"), 0644))
+
+	torrents := []types.TorrentInfo{{ID: 1, Name: "Movie1"}, {ID: 2, Name: "Other"}}
+
+	t.Run("counts items and sized bytes", func(t *testing.T) {
+		result, err := Benchmark(context.Background(), torrents, []string{tmpDir}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Iterations)
+		assert.Equal(t, 2, result.Torrents)
+		assert.Equal(t, 2, result.Items)
+		assert.Equal(t, int64(len("local")), result.SizedBytes)
+		assert.Greater(t, result.ScanTime.Nanoseconds(), int64(0))
+	})
+
+	t.Run("rejects zero iterations", func(t *testing.T) {
+		_, err := Benchmark(context.Background(), torrents, []string{tmpDir}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := Benchmark(context.Background(), torrents, []string{filepath.Join(tmpDir, "nope")}, 1)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// RPCFeature is a peerless feature that needs a minimum Transmission RPC version
+type RPCFeature struct {
+	Name          string
+	MinRPCVersion int
+}
+
+// RPCFeatures lists the features that older Transmission daemons lack
+var RPCFeatures = []RPCFeature{
+	{Name: "move (torrent-set-location)", MinRPCVersion: 6},
+	{Name: "torrent status codes (check, status, verify-stuck)", MinRPCVersion: 14},
+	{Name: "free space per directory (status space warnings)", MinRPCVersion: 15},
+	{Name: "labels (autolabel, --label, stats labels)", MinRPCVersion: 16},
+}
+
+// FeatureSupport tells whether the daemon supports an RPCFeature
+type FeatureSupport struct {
+	RPCFeature
+	Supported bool
+}
+
+// DaemonInfo describes the connected daemon and which features it supports
+type DaemonInfo struct {
+	Version string
+
+	// RPCVersion is 0 for daemons without RPC versioning, such as qBittorrent,
+	// whose Web API provides every feature
+	RPCVersion        int
+	RPCVersionMinimum int
+	Features          []FeatureSupport
+}
+
+// Compatible reports whether the daemon supports every feature
+func (d *DaemonInfo) Compatible() bool {
+	for _, f := range d.Features {
+		if !f.Supported {
+			return false
+		}
+	}
+	return true
+}
+
+// FeatureSupportFor returns the support of each RPCFeature by a daemon
+// speaking rpcVersion; 0 means the daemon has no RPC versioning
+func FeatureSupportFor(rpcVersion int) []FeatureSupport {
+	features := make([]FeatureSupport, 0, len(RPCFeatures))
+	for _, f := range RPCFeatures {
+		features = append(features, FeatureSupport{
+			RPCFeature: f,
+			Supported:  rpcVersion == 0 || rpcVersion >= f.MinRPCVersion,
+		})
+	}
+	return features
+}
+
+// GetDaemonInfo returns the version of the connected daemon and the features
+// its RPC version supports
+func (s *TorrentService) GetDaemonInfo(ctx context.Context) (*DaemonInfo, error) {
+	session, err := s.client.GetSessionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve session info: %w", err)
+	}
+	return &DaemonInfo{
+		Version:           session.Version,
+		RPCVersion:        session.RPCVersion,
+		RPCVersionMinimum: session.RPCVersionMinimum,
+		Features:          FeatureSupportFor(session.RPCVersion),
+	}, nil
+}
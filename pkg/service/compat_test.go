@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestFeatureSupportFor(t *testing.T) {
+	supported := func(features []FeatureSupport) map[int]bool {
+		byVersion := make(map[int]bool)
+		for _, f := range features {
+			byVersion[f.MinRPCVersion] = f.Supported
+		}
+		return byVersion
+	}
+
+	t.Run("old daemon lacks labels", func(t *testing.T) {
+		byVersion := supported(FeatureSupportFor(15))
+		assert.True(t, byVersion[14])
+		assert.False(t, byVersion[16])
+	})
+
+	t.Run("current daemon supports everything", func(t *testing.T) {
+		info := DaemonInfo{RPCVersion: 17, Features: FeatureSupportFor(17)}
+		assert.True(t, info.Compatible())
+	})
+
+	t.Run("no RPC versioning supports everything", func(t *testing.T) {
+		info := DaemonInfo{Features: FeatureSupportFor(0)}
+		assert.True(t, info.Compatible())
+	})
+}
+
+func TestTorrentService_GetDaemonInfo(t *testing.T) {
+	mockHTTP := newMethodMockClient(map[string]string{
+		"session-get": `{"arguments": {"version": "2.94 (d8e60ee44f)", "rpc-version": 15, "rpc-version-minimum": 1}, "result": "success"}`,
+	})
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	info, err := service.GetDaemonInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2.94 (d8e60ee44f)", info.Version)
+	assert.Equal(t, 15, info.RPCVersion)
+	assert.Equal(t, 1, info.RPCVersionMinimum)
+	assert.False(t, info.Compatible())
+}
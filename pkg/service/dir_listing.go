@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"peerless/pkg/constants"
+)
+
+// dirListing reads a directory in fixed-size batches so huge directories are
+// never held in memory at once. One batch is read ahead, which tells whether
+// the batch handed out is the last one.
+type dirListing struct {
+	f       *os.File
+	pending []os.DirEntry
+	done    bool
+}
+
+// openListing opens dir and reads its first batch of entries
+func openListing(dir string) (*dirListing, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &dirListing{f: f}
+	if err := l.fill(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// fill reads the next batch into pending
+func (l *dirListing) fill() error {
+	entries, err := l.f.ReadDir(constants.DirScanBatchSize)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	l.pending = entries
+	l.done = len(entries) < constants.DirScanBatchSize
+	return nil
+}
+
+// next returns the pending batch and whether no entries follow it
+func (l *dirListing) next() ([]os.DirEntry, bool, error) {
+	batch := l.pending
+	l.pending = nil
+	if !l.done {
+		if err := l.fill(); err != nil {
+			return nil, false, err
+		}
+	}
+	return batch, l.done && len(l.pending) == 0, nil
+}
+
+// Close releases the directory handle
+func (l *dirListing) Close() error {
+	return l.f.Close()
+}
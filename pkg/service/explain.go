@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"peerless/pkg/types"
+)
+
+// Matchers named in a MatchExplanation
+const (
+	MatcherName     = "name"
+	MatcherNameCase = "name (case-insensitive)"
+	MatcherPartial  = "partial file"
+	MatcherSize     = "size"
+	MatcherHash     = "hash"
+	MatcherNone     = ""
+)
+
+// Classes of items that are not plainly found, for automation to handle each
+// differently
+const (
+	// ClassNoNameMatch items match no torrent and are reported missing
+	ClassNoNameMatch = "no-name-match"
+	// ClassNotInFileList items sit inside a matched torrent directory but are not
+	// in its file list; they are reported missing with --files
+	ClassNotInFileList = "not-in-torrent-file-list"
+	// ClassDifferentDir items match a torrent whose download directory is
+	// another one, e.g. a leftover copy after moving the data
+	ClassDifferentDir = "torrent-present-but-different-dir"
+	// ClassIncomplete items belong to a torrent that is still downloading
+	ClassIncomplete = "incomplete-download"
+	// ClassExcluded items match no torrent but are left out of the missing
+	// items by the keep file or the age or size options
+	ClassExcluded = "excluded-by-filter"
+)
+
+// Outcomes of addMissing for items it does not record as missing
+const (
+	reasonKept     = "retained by the keep file"
+	reasonExcluded = "excluded by the age or size options"
+)
+
+// MatchExplanation records how a local item was matched to a torrent, or why
+// it matched none. Only filled in with CheckOptions.Explain.
+type MatchExplanation struct {
+	Path string
+
+	// Matcher is the matcher that found a torrent, MatcherNone when none did
+	Matcher string
+	Torrent string
+
+	// Reason details the outcome, e.g. why nothing matched
+	Reason string
+
+	// Class classifies items that are not plainly found; empty otherwise
+	Class string
+}
+
+// Matched reports whether a matcher found a torrent for the item
+func (e MatchExplanation) Matched() bool {
+	return e.Matcher != MatcherNone
+}
+
+// explainLookup describes the name match lookup found for name in dir.
+// DownloadDir is the torrent's download directory as a local path.
+func (idx *torrentIndex) explainLookup(dir, name string, torrent types.TorrentInfo, downloadDir string, inProgress bool) MatchExplanation {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+	e := MatchExplanation{Path: filepath.Join(absDir, name), Matcher: MatcherName, Torrent: torrent.Name}
+	switch {
+	case torrent.Name == name:
+	case torrent.Name+partialSuffix == name:
+		e.Matcher = MatcherPartial
+	default:
+		e.Matcher = MatcherNameCase
+	}
+	switch {
+	case inProgress:
+		e.Reason = "still downloading"
+		e.Class = ClassIncomplete
+	case downloadDir != "" && filepath.Clean(downloadDir) != absDir:
+		e.Reason = "torrent downloads to " + downloadDir
+		e.Class = ClassDifferentDir
+	}
+	return e
+}
+
+// explainMissing describes why an unmatched item matched no torrent. Outcome is
+// what addMissing did with it.
+func (idx *torrentIndex) explainMissing(item unmatchedItem, outcome string, nameMatched map[int]bool, opts CheckOptions) MatchExplanation {
+	e := MatchExplanation{Path: item.absPath, Reason: fmt.Sprintf("no torrent named %q", filepath.Base(item.absPath)), Class: ClassNoNameMatch}
+	if opts.MatchBySize || opts.MatchByHash {
+		e.Reason += "; " + idx.sizeCandidates(item, nameMatched, opts)
+	}
+	if outcome != "" {
+		e.Reason += "; " + outcome
+		e.Class = ClassExcluded
+	}
+	return e
+}
+
+// sizeCandidates describes the torrents a size or hash match could have used
+func (idx *torrentIndex) sizeCandidates(item unmatchedItem, nameMatched map[int]bool, opts CheckOptions) string {
+	if item.size == nil {
+		return "size unknown"
+	}
+	candidates := 0
+	for _, t := range idx.bySize[item.size.Size] {
+		if !nameMatched[t.ID] {
+			candidates++
+		}
+	}
+	switch {
+	case candidates == 0:
+		return "no other torrent has the same size"
+	case opts.MatchByHash:
+		return fmt.Sprintf("%d torrent(s) of the same size have a different file structure", candidates)
+	default:
+		return fmt.Sprintf("%d torrent(s) of the same size have a different file count", candidates)
+	}
+}
+
+// ClassifiedItem is an item of a CheckReport that is not plainly found
+type ClassifiedItem struct {
+	Path    string `json:"path"`
+	Class   string `json:"class"`
+	Torrent string `json:"torrent,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// FailedDirectory is a directory of a CheckReport that could not be checked
+type FailedDirectory struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// CheckReport is the machine-readable result of a check. Only items of class
+// no-name-match and not-in-torrent-file-list are in MissingPaths.
+type CheckReport struct {
+	Directories       []string          `json:"directories"`
+	FailedDirectories []FailedDirectory `json:"failed_directories"`
+	TotalItems        int               `json:"total_items"`
+	TotalFound        int               `json:"total_found"`
+	TotalMissingSize  int64             `json:"total_missing_size"`
+	IncompleteSize    bool              `json:"incomplete_size"`
+	MissingPaths      []string          `json:"missing_paths"`
+	Items             []ClassifiedItem  `json:"items"`
+
+	// Renames are the rename suggestions not applied
+	Renames []RenameSuggestion `json:"renames"`
+}
+
+// Report builds the CheckReport of r; items are only classified when the check
+// ran with CheckOptions.Explain
+func (r *DirectoryCheckResult) Report() CheckReport {
+	report := CheckReport{
+		Directories:       make([]string, 0, len(r.Directories)),
+		FailedDirectories: make([]FailedDirectory, 0),
+		TotalItems:        r.TotalItems,
+		TotalFound:        r.TotalFound,
+		TotalMissingSize:  r.TotalMissingSize,
+		IncompleteSize:    r.IncompleteSize,
+		MissingPaths:      r.MissingPaths,
+		Items:             make([]ClassifiedItem, 0),
+		Renames:           r.RenameSuggestions(),
+	}
+	if report.MissingPaths == nil {
+		report.MissingPaths = []string{}
+	}
+	for _, dirResult := range r.Directories {
+		report.Directories = append(report.Directories, dirResult.Path)
+		if dirResult.Err != nil {
+			report.FailedDirectories = append(report.FailedDirectories, FailedDirectory{Path: dirResult.Path, Error: dirResult.Err.Error()})
+		}
+		for _, e := range dirResult.Explanations {
+			if e.Class == "" {
+				continue
+			}
+			report.Items = append(report.Items, ClassifiedItem{Path: e.Path, Class: e.Class, Torrent: e.Torrent, Reason: e.Reason})
+		}
+	}
+	return report
+}
+
+// MissingReasons maps the missing paths to the reason recorded for them; it is
+// empty unless the check ran with CheckOptions.Explain
+func (r *DirectoryCheckResult) MissingReasons() map[string]string {
+	reasons := make(map[string]string)
+	for _, dirResult := range r.Directories {
+		for _, e := range dirResult.Explanations {
+			if e.Class != "" && e.Class != ClassExcluded && e.Class != ClassIncomplete {
+				reasons[e.Path] = e.Reason
+			}
+		}
+	}
+	return reasons
+}
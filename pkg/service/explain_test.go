@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+func TestTorrentService_CheckDirectories_Explain(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Done.mkv"), []byte("done"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Partial.mkv.part"), []byte("pa"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Renamed.mkv"), []byte("1234567"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Orphan.mkv"), []byte("orphan!!!"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.keep"), []byte("k"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"session-get": `{"arguments": {"rename-partial-files": true}, "result": "success"}`,
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Done.mkv", "downloadDir": "/downloads", "percentDone": 1.0, "totalSize": 4},
+			{"id": 2, "name": "Partial.mkv", "downloadDir": "/downloads", "percentDone": 0.5, "totalSize": 9},
+			{"id": 3, "name": "Original.mkv", "downloadDir": "/downloads", "percentDone": 1.0, "totalSize": 7,
+			 "files": [{"name": "Original.mkv", "length": 7}]}
+		]}, "result": "success"}`,
+	})
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	keep, err := utils.ParseKeepList(strings.NewReader("*.keep"))
+	require.NoError(t, err)
+	opts := CheckOptions{Explain: true, MatchBySize: true, Keep: keep}
+	result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	byName := make(map[string]MatchExplanation)
+	for _, e := range result.Directories[0].Explanations {
+		byName[filepath.Base(e.Path)] = e
+	}
+	require.Len(t, byName, 5)
+
+	assert.Equal(t, MatchExplanation{
+		Path:    filepath.Join(dir, "Done.mkv"),
+		Matcher: MatcherName,
+		Torrent: "Done.mkv",
+		Reason:  "torrent downloads to /downloads",
+		Class:   ClassDifferentDir,
+	}, byName["Done.mkv"])
+
+	partial := byName["Partial.mkv.part"]
+	assert.Equal(t, MatcherPartial, partial.Matcher)
+	assert.Equal(t, "still downloading", partial.Reason)
+	assert.Equal(t, ClassIncomplete, partial.Class)
+
+	assert.Equal(t, MatcherSize, byName["Renamed.mkv"].Matcher)
+	assert.Equal(t, "Original.mkv", byName["Renamed.mkv"].Torrent)
+	assert.Empty(t, byName["Renamed.mkv"].Class)
+
+	orphan := byName["Orphan.mkv"]
+	assert.False(t, orphan.Matched())
+	assert.Equal(t, `no torrent named "Orphan.mkv"; no other torrent has the same size`, orphan.Reason)
+	assert.Equal(t, ClassNoNameMatch, orphan.Class)
+
+	assert.Contains(t, byName["notes.keep"].Reason, "retained by the keep file")
+	assert.Equal(t, ClassExcluded, byName["notes.keep"].Class)
+
+	t.Run("report", func(t *testing.T) {
+		report := result.Report()
+		assert.Equal(t, []string{dir}, report.Directories)
+		assert.Equal(t, []string{filepath.Join(dir, "Orphan.mkv")}, report.MissingPaths)
+
+		classes := make(map[string]string)
+		for _, item := range report.Items {
+			classes[filepath.Base(item.Path)] = item.Class
+		}
+		assert.Equal(t, map[string]string{
+			"Done.mkv":         ClassDifferentDir,
+			"Partial.mkv.part": ClassIncomplete,
+			"Orphan.mkv":       ClassNoNameMatch,
+			"notes.keep":       ClassExcluded,
+		}, classes)
+	})
+}
+
+func TestDirectoryCheckResult_Report_Empty(t *testing.T) {
+	report := (&DirectoryCheckResult{}).Report()
+	assert.NotNil(t, report.MissingPaths)
+	assert.NotNil(t, report.Items)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// FileMismatch records where a torrent's local directory differs from its file list
+type FileMismatch struct {
+	Path        string
+	TorrentName string
+
+	// ExtraPaths lists local files and directories the torrent does not contain
+	ExtraPaths []string
+
+	// AbsentFiles lists torrent files that do not exist on disk
+	AbsentFiles []string
+}
+
+// fileCandidate is a local directory matched by name to a complete multi-file torrent
+type fileCandidate struct {
+	fullPath string
+	absPath  string
+	torrent  types.TorrentInfo
+}
+
+// matchFiles compares each candidate's local files with its torrent's file list.
+// Local paths outside the file list are recorded as missing items, and torrent
+// files absent on disk are reported in the directory's FileMismatches.
+func (s *TorrentService) matchFiles(ctx context.Context, candidates []fileCandidate, result *DirectoryResult, opts CheckOptions) error {
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.torrent.ID
+	}
+
+	files, err := s.client.GetTorrentFiles(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve torrent files: %w", err)
+	}
+
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		torrentFiles := files[c.torrent.ID]
+		if len(torrentFiles) == 1 && !strings.Contains(torrentFiles[0].Name, "/") {
+			// A single-file torrent cannot be compared with a directory
+			continue
+		}
+
+		mismatch, err := compareTorrentFiles(c, torrentFiles, opts.Throttle)
+		if err != nil {
+			result.InaccessiblePaths = append(result.InaccessiblePaths, c.absPath)
+			continue
+		}
+		if len(mismatch.ExtraPaths) == 0 && len(mismatch.AbsentFiles) == 0 {
+			continue
+		}
+
+		result.FileMismatches = append(result.FileMismatches, *mismatch)
+		for _, extra := range mismatch.ExtraPaths {
+			item := unmatchedItem{fullPath: extra, absPath: extra}
+			item.size, err = utils.GetSizeInfoThrottled(extra, opts.Throttle)
+			if err != nil {
+				item.size = nil
+			}
+			outcome := addMissing(result, item, opts)
+			if opts.Explain {
+				e := MatchExplanation{Path: extra, Reason: fmt.Sprintf("not in the file list of torrent %q", c.torrent.Name), Class: ClassNotInFileList}
+				if outcome != "" {
+					e.Reason += "; " + outcome
+					e.Class = ClassExcluded
+				}
+				result.Explanations = append(result.Explanations, e)
+			}
+		}
+	}
+
+	return nil
+}
+
+// compareTorrentFiles walks a candidate's local directory against the torrent's
+// file list. A local directory holding no torrent file is reported as a whole.
+func compareTorrentFiles(c fileCandidate, torrentFiles []types.TorrentFile, throttle *utils.Throttle) (*FileMismatch, error) {
+	// Paths below the torrent root, normalized for comparison
+	expected := make(map[string]string, len(torrentFiles))
+	expectedDirs := make(map[string]bool)
+	for _, f := range torrentFiles {
+		_, rel, ok := strings.Cut(f.Name, "/")
+		if !ok {
+			continue
+		}
+		expected[utils.NormalizeName(rel)] = rel
+		for dir := pathDir(rel); dir != ""; dir = pathDir(dir) {
+			expectedDirs[utils.NormalizeName(dir)] = true
+		}
+	}
+
+	mismatch := &FileMismatch{Path: c.absPath, TorrentName: c.torrent.Name}
+	seen := make(map[string]bool, len(expected))
+
+	err := filepath.WalkDir(c.fullPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == c.fullPath {
+			return nil
+		}
+		throttle.Wait()
+
+		rel, err := filepath.Rel(c.fullPath, p)
+		if err != nil {
+			return err
+		}
+		key := utils.NormalizeName(filepath.ToSlash(rel))
+		absPath := filepath.Join(c.absPath, rel)
+
+		if d.IsDir() {
+			if !expectedDirs[key] {
+				mismatch.ExtraPaths = append(mismatch.ExtraPaths, absPath)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := expected[key]; !ok {
+			mismatch.ExtraPaths = append(mismatch.ExtraPaths, absPath)
+			return nil
+		}
+		seen[key] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, rel := range expected {
+		if !seen[key] {
+			mismatch.AbsentFiles = append(mismatch.AbsentFiles, filepath.Join(c.absPath, filepath.FromSlash(rel)))
+		}
+	}
+	sort.Strings(mismatch.AbsentFiles)
+
+	return mismatch, nil
+}
+
+// pathDir returns the parent of a slash-separated relative path, or "" at the top
+func pathDir(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
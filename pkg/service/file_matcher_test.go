@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestTorrentService_CheckDirectories_MatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	show := filepath.Join(dir, "Show")
+	require.NoError(t, os.MkdirAll(filepath.Join(show, "S01"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(show, "Extras"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(show, "S01", "e01.mkv"), []byte("12345"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(show, "S01", "sample.mkv"), []byte("12"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(show, "Extras", "a.mkv"), []byte("123"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Movie.mkv"), []byte("1"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Show", "downloadDir": "/downloads", "percentDone": 1.0,
+			 "files": [{"name": "Show/S01/e01.mkv", "length": 5}, {"name": "Show/S01/e02.mkv", "length": 5}]},
+			{"id": 2, "name": "Movie.mkv", "downloadDir": "/downloads", "percentDone": 1.0,
+			 "files": [{"name": "Movie.mkv", "length": 1}]}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		result, err := service.CheckDirectories(context.Background(), []string{dir})
+		require.NoError(t, err)
+		assert.Empty(t, result.MissingPaths)
+		assert.Empty(t, result.Directories[0].FileMismatches)
+	})
+
+	t.Run("reports extra and absent files", func(t *testing.T) {
+		result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, CheckOptions{MatchFiles: true})
+		require.NoError(t, err)
+		require.Len(t, result.Directories, 1)
+
+		dirResult := result.Directories[0]
+		assert.Equal(t, 2, dirResult.FoundItems)
+		assert.Equal(t, []string{
+			filepath.Join(show, "Extras"),
+			filepath.Join(show, "S01", "sample.mkv"),
+		}, dirResult.MissingPaths)
+		assert.Equal(t, int64(5), dirResult.MissingSize)
+
+		require.Len(t, dirResult.FileMismatches, 1)
+		mismatch := dirResult.FileMismatches[0]
+		assert.Equal(t, "Show", mismatch.TorrentName)
+		assert.Equal(t, []string{filepath.Join(show, "S01", "e02.mkv")}, mismatch.AbsentFiles)
+	})
+}
+
+func TestPathDir(t *testing.T) {
+	assert.Equal(t, "a/b", pathDir("a/b/c.mkv"))
+	assert.Equal(t, "", pathDir("c.mkv"))
+}
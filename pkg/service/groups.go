@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// Keys accepted by GroupTorrents
+const (
+	GroupByDir     = "dir"
+	GroupByTracker = "tracker"
+	GroupByLabel   = "label"
+	GroupByStatus  = "status"
+)
+
+// ValidateGroupBy checks that key is a supported group-by key
+func ValidateGroupBy(key string) error {
+	switch key {
+	case GroupByDir, GroupByTracker, GroupByLabel, GroupByStatus:
+		return nil
+	default:
+		return fmt.Errorf("invalid group-by key %q: must be %s, %s, %s or %s", key, GroupByDir, GroupByTracker, GroupByLabel, GroupByStatus)
+	}
+}
+
+// TorrentSection is a group of torrents with its subtotals
+type TorrentSection struct {
+	Name      string   `json:"name"`
+	Count     int      `json:"count"`
+	TotalSize int64    `json:"totalSize"`
+	Paths     []string `json:"paths"`
+}
+
+// GroupTorrents returns the torrents matching filters grouped by key, sections
+// sorted by name and paths sorted within each. Torrents with several labels
+// are listed under each of them; unlabeled ones under constants.UnlabeledName.
+func (s *TorrentService) GroupTorrents(ctx context.Context, key string, filters ...TorrentFilter) ([]TorrentSection, error) {
+	if err := ValidateGroupBy(key); err != nil {
+		return nil, err
+	}
+	torrents, err := s.GetTorrents(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*TorrentSection)
+	for _, t := range torrents {
+		path := utils.SanitizeString(filepath.Join(t.DownloadDir, t.Name))
+		for _, name := range groupNames(t, key) {
+			section, exists := byName[name]
+			if !exists {
+				section = &TorrentSection{Name: name, Paths: make([]string, 0)}
+				byName[name] = section
+			}
+			section.Count++
+			section.TotalSize += t.TotalSize
+			section.Paths = append(section.Paths, path)
+		}
+	}
+
+	sections := make([]TorrentSection, 0, len(byName))
+	for _, section := range byName {
+		sort.Strings(section.Paths)
+		sections = append(sections, *section)
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Name < sections[j].Name
+	})
+	return sections, nil
+}
+
+// groupNames returns the sections t belongs to when grouping by key
+func groupNames(t types.TorrentInfo, key string) []string {
+	switch key {
+	case GroupByTracker:
+		return []string{TrackerName(t)}
+	case GroupByLabel:
+		if len(t.Labels) == 0 {
+			return []string{constants.UnlabeledName}
+		}
+		return t.Labels
+	case GroupByStatus:
+		return []string{t.Status.String()}
+	default:
+		return []string{t.DownloadDir}
+	}
+}
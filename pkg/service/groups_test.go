@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+)
+
+func TestGroupTorrents(t *testing.T) {
+	mock := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Film", "downloadDir": "/downloads/movies", "totalSize": 100, "status": 6,
+			 "labels": ["movies", "keep"], "trackers": [{"announce": "https://a.example/announce"}]},
+			{"id": 2, "name": "Film 2", "downloadDir": "/downloads/movies", "totalSize": 200, "status": 0,
+			 "labels": ["movies"], "trackers": [{"announce": "https://b.example/announce"}]},
+			{"id": 3, "name": "Show", "downloadDir": "/downloads/tv", "totalSize": 50, "status": 4}
+		]}, "result": "success"}`,
+	})
+	svc := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mock))
+
+	t.Run("by directory", func(t *testing.T) {
+		sections, err := svc.GroupTorrents(context.Background(), GroupByDir)
+		require.NoError(t, err)
+		assert.Equal(t, []TorrentSection{
+			{Name: "/downloads/movies", Count: 2, TotalSize: 300, Paths: []string{"/downloads/movies/Film", "/downloads/movies/Film 2"}},
+			{Name: "/downloads/tv", Count: 1, TotalSize: 50, Paths: []string{"/downloads/tv/Show"}},
+		}, sections)
+	})
+
+	t.Run("by label lists torrents under each label", func(t *testing.T) {
+		sections, err := svc.GroupTorrents(context.Background(), GroupByLabel)
+		require.NoError(t, err)
+		require.Len(t, sections, 3)
+		assert.Equal(t, constants.UnlabeledName, sections[0].Name)
+		assert.Equal(t, "keep", sections[1].Name)
+		assert.Equal(t, 1, sections[1].Count)
+		assert.Equal(t, "movies", sections[2].Name)
+		assert.Equal(t, int64(300), sections[2].TotalSize)
+	})
+
+	t.Run("by tracker", func(t *testing.T) {
+		sections, err := svc.GroupTorrents(context.Background(), GroupByTracker)
+		require.NoError(t, err)
+		names := make([]string, 0, len(sections))
+		for _, s := range sections {
+			names = append(names, s.Name)
+		}
+		assert.Equal(t, []string{constants.NoTrackerName, "a.example", "b.example"}, names)
+	})
+
+	t.Run("by status with filters", func(t *testing.T) {
+		sections, err := svc.GroupTorrents(context.Background(), GroupByStatus, DirectoryFilter("/downloads/movies"))
+		require.NoError(t, err)
+		require.Len(t, sections, 2)
+		assert.Equal(t, types.StatusSeeding.String(), sections[0].Name)
+		assert.Equal(t, types.StatusStopped.String(), sections[1].Name)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := svc.GroupTorrents(context.Background(), "size")
+		assert.ErrorContains(t, err, "must be dir, tracker, label or status")
+	})
+}
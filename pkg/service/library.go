@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// LibraryPlacement is the library directory chosen for a torrent's data
+type LibraryPlacement struct {
+	Torrent     types.TorrentInfo
+	Destination string
+}
+
+// PlanLibraryPlacements picks a directory under root for each torrent. The
+// first of a torrent's labels found in labelDirs selects a subdirectory;
+// relative subdirectories are joined to root and absolute ones used as is.
+// Torrents whose data already lives in their destination are skipped.
+func PlanLibraryPlacements(torrents []types.TorrentInfo, root string, labelDirs map[string]string) []LibraryPlacement {
+	placements := make([]LibraryPlacement, 0, len(torrents))
+	for _, t := range torrents {
+		dest := root
+		for _, label := range t.Labels {
+			if dir, ok := labelDirs[label]; ok {
+				if filepath.IsAbs(dir) {
+					dest = dir
+				} else {
+					dest = filepath.Join(root, dir)
+				}
+				break
+			}
+		}
+
+		dest = filepath.Clean(dest)
+		if dest == filepath.Clean(t.DownloadDir) {
+			continue
+		}
+		placements = append(placements, LibraryPlacement{Torrent: t, Destination: dest})
+	}
+	return placements
+}
+
+// MoveTorrents has Transmission move each torrent's data to its destination,
+// one request per destination, and returns how many torrents were moved
+func (s *TorrentService) MoveTorrents(ctx context.Context, placements []LibraryPlacement) (int, error) {
+	byDest := make(map[string][]int)
+	for _, p := range placements {
+		byDest[p.Destination] = append(byDest[p.Destination], p.Torrent.ID)
+	}
+
+	dests := make([]string, 0, len(byDest))
+	for dest := range byDest {
+		dests = append(dests, dest)
+	}
+	sort.Strings(dests)
+
+	moved := 0
+	for _, dest := range dests {
+		if err := s.client.SetTorrentLocation(ctx, byDest[dest], dest, true); err != nil {
+			return moved, fmt.Errorf("failed to move torrents to %s: %w", dest, err)
+		}
+		moved += len(byDest[dest])
+	}
+	return moved, nil
+}
+
+// LinkTorrents hardlinks each torrent's local data into its destination,
+// leaving the original in place so Transmission keeps seeding it
+func (s *TorrentService) LinkTorrents(placements []LibraryPlacement, copyFallback bool) (*utils.LinkResult, error) {
+	total := &utils.LinkResult{}
+	for _, p := range placements {
+		src := filepath.Join(s.pathMappings.ToLocal(p.Torrent.DownloadDir), p.Torrent.Name)
+		result, err := utils.LinkTree(src, filepath.Join(p.Destination, p.Torrent.Name), copyFallback)
+		total.Add(result)
+		if err != nil {
+			return total, fmt.Errorf("failed to link torrent %q: %w", p.Torrent.Name, err)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanLibraryPlacements(t *testing.T) {
+	torrents := []types.TorrentInfo{
+		{ID: 1, DownloadDir: "/downloads", Labels: []string{"movies"}},
+		{ID: 2, DownloadDir: "/downloads", Labels: []string{"other", "tv"}},
+		{ID: 3, DownloadDir: "/downloads"},
+		{ID: 4, DownloadDir: "/archive/music", Labels: []string{"music"}},
+		{ID: 5, DownloadDir: "/media/library/movies/", Labels: []string{"movies"}},
+	}
+	labelDirs := map[string]string{"movies": "movies", "tv": "tv", "music": "/archive/music"}
+
+	placements := PlanLibraryPlacements(torrents, "/media/library", labelDirs)
+
+	dests := make(map[int]string)
+	for _, p := range placements {
+		dests[p.Torrent.ID] = p.Destination
+	}
+	assert.Equal(t, map[int]string{
+		1: "/media/library/movies",
+		2: "/media/library/tv",
+		3: "/media/library",
+	}, dests)
+}
+
+func TestTorrentService_MoveTorrents(t *testing.T) {
+	type call struct {
+		IDs      []int  `json:"ids"`
+		Location string `json:"location"`
+		Move     bool   `json:"move"`
+	}
+	var calls []call
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "s"}), nil
+			}
+
+			var rpcReq struct {
+				Method    string `json:"method"`
+				Arguments call   `json:"arguments"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &rpcReq))
+			assert.Equal(t, "torrent-set-location", rpcReq.Method)
+			calls = append(calls, rpcReq.Arguments)
+
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	moved, err := service.MoveTorrents(context.Background(), []LibraryPlacement{
+		{Torrent: types.TorrentInfo{ID: 1}, Destination: "/lib/tv"},
+		{Torrent: types.TorrentInfo{ID: 2}, Destination: "/lib/movies"},
+		{Torrent: types.TorrentInfo{ID: 3}, Destination: "/lib/tv"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, moved)
+	assert.Equal(t, []call{
+		{IDs: []int{2}, Location: "/lib/movies", Move: true},
+		{IDs: []int{1, 3}, Location: "/lib/tv", Move: true},
+	}, calls)
+}
+
+func TestTorrentService_LinkTorrents(t *testing.T) {
+	root := t.TempDir()
+	local := filepath.Join(root, "local")
+	require.NoError(t, os.MkdirAll(filepath.Join(local, "Album"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(local, "Album", "01.flac"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(local, "movie.mkv"), []byte("m"), 0644))
+
+	mappings := types.PathMappings{{Remote: "/data", Local: local}}
+	service := NewTorrentServiceWithPathMappings(nil, mappings)
+
+	library := filepath.Join(root, "library")
+	result, err := service.LinkTorrents([]LibraryPlacement{
+		{Torrent: types.TorrentInfo{Name: "Album", DownloadDir: "/data"}, Destination: filepath.Join(library, "music")},
+		{Torrent: types.TorrentInfo{Name: "movie.mkv", DownloadDir: "/data"}, Destination: library},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Linked)
+	assert.FileExists(t, filepath.Join(library, "music", "Album", "01.flac"))
+	assert.FileExists(t, filepath.Join(library, "movie.mkv"))
+	assert.FileExists(t, filepath.Join(local, "movie.mkv"))
+
+	_, err = service.LinkTorrents([]LibraryPlacement{
+		{Torrent: types.TorrentInfo{Name: "gone", DownloadDir: "/data"}, Destination: library},
+	}, false)
+	assert.Error(t, err)
+}
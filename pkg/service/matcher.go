@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// partialSuffix is appended by Transmission to incomplete files when rename-partial-files is enabled
+const partialSuffix = ".part"
+
+// torrentIndex looks up torrents by the local item names they produce
+type torrentIndex struct {
+	byName map[string]types.TorrentInfo
+	bySize map[int64][]types.TorrentInfo
+
+	// byLoose maps names compared by looseName, for near-miss suggestions
+	byLoose map[string][]types.TorrentInfo
+
+	// partial maps "<name>.part" to in-progress torrents
+	partial map[string]types.TorrentInfo
+}
+
+// newTorrentIndex builds an index over torrents. When session settings make
+// Transmission rename partial files, in-progress torrents are also indexed
+// under their ".part" names.
+func newTorrentIndex(torrents []types.TorrentInfo, session *types.SessionInfo) *torrentIndex {
+	idx := &torrentIndex{
+		byName:  make(map[string]types.TorrentInfo, len(torrents)),
+		bySize:  make(map[int64][]types.TorrentInfo),
+		byLoose: make(map[string][]types.TorrentInfo),
+		partial: make(map[string]types.TorrentInfo),
+	}
+
+	renamePartial := session != nil && session.RenamePartialFiles
+	for _, t := range torrents {
+		idx.byName[utils.NormalizeName(t.Name)] = t
+		idx.byLoose[looseName(t.Name)] = append(idx.byLoose[looseName(t.Name)], t)
+		if t.TotalSize > 0 {
+			idx.bySize[t.TotalSize] = append(idx.bySize[t.TotalSize], t)
+		}
+		if renamePartial && !t.IsComplete() {
+			idx.partial[utils.NormalizeName(t.Name+partialSuffix)] = t
+		}
+	}
+
+	return idx
+}
+
+// lookup returns the torrent matching a local item name and whether the match
+// is an in-progress torrent
+func (idx *torrentIndex) lookup(name string) (types.TorrentInfo, bool, bool) {
+	key := utils.NormalizeName(name)
+	if t, ok := idx.byName[key]; ok {
+		return t, true, !t.IsComplete()
+	}
+	if t, ok := idx.partial[key]; ok {
+		return t, true, true
+	}
+	return types.TorrentInfo{}, false, false
+}
+
+// isIncompleteDir reports whether dir is Transmission's enabled incomplete-dir
+func isIncompleteDir(dir string, session *types.SessionInfo) bool {
+	if session == nil || !session.IncompleteDirEnabled || session.IncompleteDir == "" {
+		return false
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+	return filepath.Clean(absDir) == filepath.Clean(session.IncompleteDir)
+}
+
+// matchBySize pairs unmatched local items with torrents of identical total size and
+// top-level shape, or identical structure fingerprint with opts.MatchByHash.
+// Torrents already matched by name, or claimed by an earlier item, are not
+// reused. The result is keyed by the item's absolute path.
+func (s *TorrentService) matchBySize(ctx context.Context, items []unmatchedItem, index *torrentIndex, nameMatched map[int]bool, opts CheckOptions) (map[string]types.TorrentInfo, error) {
+	candidateIDs := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.size == nil || item.size.Incomplete() {
+			continue
+		}
+		for _, t := range index.bySize[item.size.Size] {
+			if !nameMatched[t.ID] && !seen[t.ID] {
+				candidateIDs = append(candidateIDs, t.ID)
+				seen[t.ID] = true
+			}
+		}
+	}
+
+	matches := make(map[string]types.TorrentInfo)
+	if len(candidateIDs) == 0 {
+		return matches, nil
+	}
+
+	files, err := s.client.GetTorrentFiles(ctx, candidateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrent files: %w", err)
+	}
+
+	claimed := make(map[int]bool)
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if item.size == nil || item.size.Incomplete() {
+			continue
+		}
+
+		var matchesTorrent func([]types.TorrentFile) bool
+		if opts.MatchByHash {
+			fingerprint, err := localFingerprint(item.fullPath, opts.Throttle)
+			if err != nil {
+				continue
+			}
+			matchesTorrent = func(tf []types.TorrentFile) bool { return torrentFingerprint(tf) == fingerprint }
+		} else {
+			localIsDir, localCount, err := localShape(item.fullPath)
+			if err != nil {
+				continue
+			}
+			matchesTorrent = func(tf []types.TorrentFile) bool {
+				torrentIsDir, torrentCount := torrentShape(tf)
+				return torrentIsDir == localIsDir && torrentCount == localCount
+			}
+		}
+
+		for _, t := range index.bySize[item.size.Size] {
+			if nameMatched[t.ID] || claimed[t.ID] || !seen[t.ID] {
+				continue
+			}
+			if matchesTorrent(files[t.ID]) {
+				matches[item.absPath] = t
+				claimed[t.ID] = true
+				break
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// localShape reports whether a local item is a directory and how many entries it has at its top level
+func localShape(path string) (bool, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, 0, err
+	}
+	if !info.IsDir() {
+		return false, 1, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return true, 0, err
+	}
+	return true, len(entries), nil
+}
+
+// torrentShape reports whether a torrent's content is a directory and how many
+// distinct entries sit directly below its root
+func torrentShape(files []types.TorrentFile) (bool, int) {
+	if len(files) == 1 && !strings.Contains(files[0].Name, "/") {
+		return false, 1
+	}
+
+	topLevel := make(map[string]bool)
+	for _, f := range files {
+		parts := strings.SplitN(f.Name, "/", 3)
+		if len(parts) >= 2 {
+			topLevel[parts[1]] = true
+		}
+	}
+	return true, len(topLevel)
+}
+
+// localFingerprint returns the structure fingerprint of a local file or
+// directory; see structureFingerprint
+func localFingerprint(path string, throttle *utils.Throttle) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return structureFingerprint(false, []string{fingerprintEntry(0, info.Size())}), nil
+	}
+
+	entries := make([]string, 0)
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		throttle.Wait()
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fingerprintEntry(strings.Count(filepath.ToSlash(rel), "/"), info.Size()))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return structureFingerprint(true, entries), nil
+}
+
+// torrentFingerprint returns the structure fingerprint of a torrent's file list
+func torrentFingerprint(files []types.TorrentFile) string {
+	if len(files) == 1 && !strings.Contains(files[0].Name, "/") {
+		return structureFingerprint(false, []string{fingerprintEntry(0, files[0].Length)})
+	}
+
+	entries := make([]string, 0, len(files))
+	for _, f := range files {
+		// Depth below the torrent's root directory
+		entries = append(entries, fingerprintEntry(strings.Count(f.Name, "/")-1, f.Length))
+	}
+	return structureFingerprint(true, entries)
+}
+
+// fingerprintEntry describes one file by its directory depth and size
+func fingerprintEntry(depth int, size int64) string {
+	return fmt.Sprintf("%d:%d", depth, size)
+}
+
+// structureFingerprint hashes the sorted file entries of a content root. Names
+// are left out, so the fingerprint survives renaming files and directories.
+func structureFingerprint(isDir bool, entries []string) string {
+	sort.Strings(entries)
+	h := sha256.New()
+	fmt.Fprintf(h, "dir=%t\n", isDir)
+	for _, e := range entries {
+		fmt.Fprintln(h, e)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+func TestTorrentIndex_Lookup(t *testing.T) {
+	torrents := []types.TorrentInfo{
+		{ID: 1, Name: "Done.mkv", PercentDone: 1.0},
+		{ID: 2, Name: "Partial.mkv", PercentDone: 0.4},
+	}
+
+	t.Run("partial names when rename-partial-files is enabled", func(t *testing.T) {
+		idx := newTorrentIndex(torrents, &types.SessionInfo{RenamePartialFiles: true})
+
+		torrent, found, inProgress := idx.lookup("Partial.mkv.part")
+		assert.True(t, found)
+		assert.True(t, inProgress)
+		assert.Equal(t, 2, torrent.ID)
+
+		_, found, inProgress = idx.lookup("Done.mkv")
+		assert.True(t, found)
+		assert.False(t, inProgress)
+
+		_, found, _ = idx.lookup("Done.mkv.part")
+		assert.False(t, found)
+	})
+
+	t.Run("partial names ignored when rename-partial-files is disabled", func(t *testing.T) {
+		idx := newTorrentIndex(torrents, &types.SessionInfo{})
+
+		_, found, _ := idx.lookup("Partial.mkv.part")
+		assert.False(t, found)
+	})
+}
+
+func TestTorrentService_CheckDirectories_IncompleteDir(t *testing.T) {
+	incompleteDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(incompleteDir, "Partial.mkv.part"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(incompleteDir, "Stray.mkv"), []byte("x"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Partial.mkv", "downloadDir": "/downloads", "percentDone": 0.5, "status": 4}
+		]}, "result": "success"}`,
+		"session-get": `{"arguments": {
+			"incomplete-dir": "` + incompleteDir + `",
+			"incomplete-dir-enabled": true,
+			"rename-partial-files": true
+		}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	result, err := service.CheckDirectories(context.Background(), []string{incompleteDir})
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	dirResult := result.Directories[0]
+	assert.True(t, dirResult.IsIncompleteDir)
+	assert.Equal(t, 1, dirResult.FoundItems)
+	assert.Equal(t, 1, dirResult.InProgressItems)
+	assert.Equal(t, []string{filepath.Join(incompleteDir, "Stray.mkv")}, dirResult.MissingPaths)
+}
+
+func TestTorrentShape(t *testing.T) {
+	isDir, count := torrentShape([]types.TorrentFile{{Name: "Movie.mkv", Length: 10}})
+	assert.False(t, isDir)
+	assert.Equal(t, 1, count)
+
+	isDir, count = torrentShape([]types.TorrentFile{
+		{Name: "Album/01.flac"},
+		{Name: "Album/02.flac"},
+		{Name: "Album/Scans/front.jpg"},
+		{Name: "Album/Scans/back.jpg"},
+	})
+	assert.True(t, isDir)
+	assert.Equal(t, 3, count)
+}
+
+func TestTorrentService_CheckDirectories_MatchBySize(t *testing.T) {
+	dir := t.TempDir()
+	renamed := filepath.Join(dir, "Album (renamed)")
+	require.NoError(t, os.MkdirAll(filepath.Join(renamed, "Scans"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(renamed, "01.flac"), []byte("12345"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(renamed, "Scans", "front.jpg"), []byte("123"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Other.bin"), []byte("12345678"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Album", "downloadDir": "/downloads", "percentDone": 1.0, "totalSize": 8,
+			 "files": [{"name": "Album/01.flac", "length": 5}, {"name": "Album/Scans/front.jpg", "length": 3}]}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		result, err := service.CheckDirectories(context.Background(), []string{dir})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.TotalFound)
+		assert.Len(t, result.MissingPaths, 2)
+	})
+
+	t.Run("matches renamed item by size and file count", func(t *testing.T) {
+		result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, CheckOptions{MatchBySize: true})
+		require.NoError(t, err)
+		require.Len(t, result.Directories, 1)
+
+		dirResult := result.Directories[0]
+		assert.Equal(t, 1, dirResult.FoundItems)
+		require.Len(t, dirResult.SizeMatches, 1)
+		assert.Equal(t, renamed, dirResult.SizeMatches[0].Path)
+		assert.Equal(t, "Album", dirResult.SizeMatches[0].TorrentName)
+
+		// Same size, but a single file rather than a two-entry directory
+		assert.Equal(t, []string{filepath.Join(dir, "Other.bin")}, dirResult.MissingPaths)
+	})
+}
+
+func TestTorrentService_CheckDirectories_MatchByHash(t *testing.T) {
+	dir := t.TempDir()
+	renamed := filepath.Join(dir, "Album (renamed)")
+	require.NoError(t, os.MkdirAll(filepath.Join(renamed, "Artwork"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(renamed, "track01.flac"), []byte("12345"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(renamed, "Artwork", "cover.jpg"), []byte("123"), 0644))
+
+	// Same total size and top-level shape, but different file sizes
+	lookalike := filepath.Join(dir, "Lookalike")
+	require.NoError(t, os.MkdirAll(filepath.Join(lookalike, "Sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(lookalike, "a.bin"), []byte("1234"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(lookalike, "Sub", "b.bin"), []byte("1234"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Album", "downloadDir": "/downloads", "percentDone": 1.0, "totalSize": 8,
+			 "files": [{"name": "Album/01.flac", "length": 5}, {"name": "Album/Scans/front.jpg", "length": 3}]}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, CheckOptions{MatchByHash: true})
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	dirResult := result.Directories[0]
+	assert.Equal(t, 1, dirResult.FoundItems)
+	require.Len(t, dirResult.SizeMatches, 1)
+	assert.Equal(t, renamed, dirResult.SizeMatches[0].Path)
+	assert.Equal(t, []string{lookalike}, dirResult.MissingPaths)
+}
+
+func TestStructureFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Show", "Extras"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Show", "e01.mkv"), []byte("12345"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Show", "Extras", "x.mkv"), []byte("12"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Single.mkv"), []byte("1234567"), 0644))
+
+	local, err := localFingerprint(filepath.Join(dir, "Show"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, torrentFingerprint([]types.TorrentFile{
+		{Name: "Renamed/Bonus/b.mkv", Length: 2},
+		{Name: "Renamed/episode.mkv", Length: 5},
+	}), local)
+
+	// Moving a file to another depth changes the fingerprint
+	assert.NotEqual(t, torrentFingerprint([]types.TorrentFile{
+		{Name: "Renamed/b.mkv", Length: 2},
+		{Name: "Renamed/episode.mkv", Length: 5},
+	}), local)
+
+	single, err := localFingerprint(filepath.Join(dir, "Single.mkv"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, torrentFingerprint([]types.TorrentFile{{Name: "Other.mkv", Length: 7}}), single)
+	assert.NotEqual(t, torrentFingerprint([]types.TorrentFile{{Name: "Dir/Other.mkv", Length: 7}}), single)
+}
+
+func TestTorrentService_CheckDirectories_AgeAndSizeOptions(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-90 * 24 * time.Hour)
+
+	oldLarge := filepath.Join(dir, "old-large.bin")
+	require.NoError(t, os.WriteFile(oldLarge, make([]byte, 2048), 0644))
+	require.NoError(t, os.Chtimes(oldLarge, old, old))
+
+	oldSmall := filepath.Join(dir, "old-small.bin")
+	require.NoError(t, os.WriteFile(oldSmall, []byte("x"), 0644))
+	require.NoError(t, os.Chtimes(oldSmall, old, old))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new-large.bin"), make([]byte, 2048), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": []}, "result": "success"}`,
+	})
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	opts := CheckOptions{
+		ModifiedBefore: time.Now().Add(-60 * 24 * time.Hour),
+		MinSize:        1024,
+	}
+	result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	assert.Equal(t, []string{oldLarge}, result.MissingPaths)
+	assert.Equal(t, 2, result.Directories[0].ExcludedItems)
+	assert.Equal(t, int64(2048), result.TotalMissingSize)
+}
+
+func TestTorrentService_CheckDirectories_KeepList(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Family Videos"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backup.iso"), []byte("iso"), 0644))
+	orphan := filepath.Join(dir, "Old.Movie.2019.mkv")
+	require.NoError(t, os.WriteFile(orphan, []byte("movie"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": []}, "result": "success"}`,
+	})
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	keep, err := utils.ParseKeepList(strings.NewReader("Family Videos\n*.iso\n"))
+	require.NoError(t, err)
+	result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, CheckOptions{Keep: keep})
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	assert.Equal(t, []string{orphan}, result.MissingPaths)
+	assert.Equal(t, 2, result.Directories[0].KeptItems)
+	assert.Equal(t, int64(5), result.TotalMissingSize)
+}
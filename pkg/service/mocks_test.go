@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 )
@@ -29,3 +30,30 @@ func NewMockResponse(statusCode int, body string, headers map[string]string) *ht
 
 	return resp
 }
+
+// newMethodMockClient creates a mock HTTP client that answers each RPC method with a canned body
+func newMethodMockClient(responses map[string]string) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session",
+				}), nil
+			}
+
+			var rpcReq struct {
+				Method string `json:"method"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, &rpcReq)
+
+			responseBody, ok := responses[rpcReq.Method]
+			if !ok {
+				responseBody = `{"arguments": {}, "result": "success"}`
+			}
+			return NewMockResponse(200, responseBody, map[string]string{
+				"Content-Type": "application/json",
+			}), nil
+		},
+	}
+}
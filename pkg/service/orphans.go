@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"peerless/pkg/types"
+)
+
+// OrphanTorrent is a torrent whose data no longer exists on disk
+type OrphanTorrent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	// Path is the local path the torrent's data was expected at
+	Path string `json:"path"`
+
+	// MissingFiles lists the absent files when only part of the data is gone;
+	// it is empty when Path itself does not exist
+	MissingFiles []string `json:"missing_files"`
+}
+
+// Partial reports whether some of the torrent's data still exists
+func (o OrphanTorrent) Partial() bool {
+	return len(o.MissingFiles) > 0
+}
+
+// FindOrphanTorrents returns the completed torrents matching filters whose data
+// is missing from downloadDir/name. With checkFiles, torrents whose data root
+// exists are also checked against their file lists, so partially deleted data
+// is reported too. Torrents still downloading are skipped, since their data may
+// live in the incomplete-dir.
+func (s *TorrentService) FindOrphanTorrents(ctx context.Context, checkFiles bool, filters ...TorrentFilter) ([]OrphanTorrent, error) {
+	torrents, err := s.GetTorrents(ctx, append(filters, CompletedFilter)...)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := make([]OrphanTorrent, 0)
+	present := make([]types.TorrentInfo, 0)
+	for _, t := range torrents {
+		path := filepath.Join(s.pathMappings.ToLocal(t.DownloadDir), t.Name)
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			orphans = append(orphans, OrphanTorrent{ID: t.ID, Name: t.Name, Path: path, MissingFiles: []string{}})
+			continue
+		}
+		present = append(present, t)
+	}
+
+	if checkFiles && len(present) > 0 {
+		partial, err := s.partialOrphans(ctx, present)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, partial...)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].ID < orphans[j].ID })
+	return orphans, nil
+}
+
+// partialOrphans checks each torrent's files on disk and returns those missing any
+func (s *TorrentService) partialOrphans(ctx context.Context, present []types.TorrentInfo) ([]OrphanTorrent, error) {
+	ids := make([]int, len(present))
+	for i, t := range present {
+		ids[i] = t.ID
+	}
+	files, err := s.client.GetTorrentFiles(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrent files: %w", err)
+	}
+
+	orphans := make([]OrphanTorrent, 0)
+	for _, t := range present {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		downloadDir := s.pathMappings.ToLocal(t.DownloadDir)
+		missing := make([]string, 0)
+		for _, f := range files[t.ID] {
+			path := filepath.Join(downloadDir, filepath.FromSlash(f.Name))
+			if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+				missing = append(missing, path)
+			}
+		}
+		if len(missing) > 0 {
+			orphans = append(orphans, OrphanTorrent{
+				ID:           t.ID,
+				Name:         t.Name,
+				Path:         filepath.Join(downloadDir, t.Name),
+				MissingFiles: missing,
+			})
+		}
+	}
+
+	return orphans, nil
+}
+
+// RemoveOrphanTorrents removes orphans from the daemon, deleting whatever of
+// their data remains when deleteData is set, and returns how many were removed
+func (s *TorrentService) RemoveOrphanTorrents(ctx context.Context, orphans []OrphanTorrent, deleteData bool) (int, error) {
+	ids := make([]int, len(orphans))
+	for i, o := range orphans {
+		ids[i] = o.ID
+	}
+	if err := s.client.RemoveTorrents(ctx, ids, deleteData); err != nil {
+		return 0, fmt.Errorf("failed to remove torrents: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestTorrentService_FindOrphanTorrents(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Present.mkv"), []byte("x"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Album"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Album", "01.flac"), []byte("x"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Present.mkv", "downloadDir": "` + dir + `", "percentDone": 1.0,
+			 "files": [{"name": "Present.mkv", "length": 1}]},
+			{"id": 2, "name": "Gone.mkv", "downloadDir": "` + dir + `", "percentDone": 1.0,
+			 "files": [{"name": "Gone.mkv", "length": 1}]},
+			{"id": 3, "name": "Album", "downloadDir": "` + dir + `", "percentDone": 1.0,
+			 "files": [{"name": "Album/01.flac", "length": 1}, {"name": "Album/02.flac", "length": 1}]},
+			{"id": 4, "name": "Downloading.mkv", "downloadDir": "` + dir + `", "percentDone": 0.5}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("missing data roots", func(t *testing.T) {
+		orphans, err := service.FindOrphanTorrents(context.Background(), false)
+		require.NoError(t, err)
+		require.Len(t, orphans, 1)
+		assert.Equal(t, 2, orphans[0].ID)
+		assert.Equal(t, filepath.Join(dir, "Gone.mkv"), orphans[0].Path)
+		assert.False(t, orphans[0].Partial())
+	})
+
+	t.Run("missing files with checkFiles", func(t *testing.T) {
+		orphans, err := service.FindOrphanTorrents(context.Background(), true)
+		require.NoError(t, err)
+		require.Len(t, orphans, 2)
+		assert.Equal(t, 2, orphans[0].ID)
+		assert.Equal(t, 3, orphans[1].ID)
+		assert.True(t, orphans[1].Partial())
+		assert.Equal(t, []string{filepath.Join(dir, "Album", "02.flac")}, orphans[1].MissingFiles)
+	})
+
+	t.Run("filters apply", func(t *testing.T) {
+		orphans, err := service.FindOrphanTorrents(context.Background(), false, IDFilter(1))
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+}
+
+func TestTorrentService_RemoveOrphanTorrents(t *testing.T) {
+	var method string
+	var args map[string]interface{}
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "test-session"}), nil
+			}
+			var rpcReq struct {
+				Method    string                 `json:"method"`
+				Arguments map[string]interface{} `json:"arguments"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &rpcReq))
+			method, args = rpcReq.Method, rpcReq.Arguments
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	removed, err := service.RemoveOrphanTorrents(context.Background(), []OrphanTorrent{{ID: 2}, {ID: 7}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, "torrent-remove", method)
+	assert.Equal(t, []interface{}{float64(2), float64(7)}, args["ids"])
+	assert.Equal(t, false, args["delete-local-data"])
+}
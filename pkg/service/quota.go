@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/store"
+)
+
+// QuotaSample is a reading of the daemon's all-time transfer counters
+type QuotaSample struct {
+	Time       time.Time `json:"time"`
+	Uploaded   int64     `json:"uploaded"`
+	Downloaded int64     `json:"downloaded"`
+}
+
+// QuotaHistory keeps samples of the transfer counters per connection, so
+// totals for a billing period can be derived from the all-time counters
+type QuotaHistory struct {
+	Samples map[string][]QuotaSample `json:"samples"`
+}
+
+// QuotaUsage is the data transferred since the start of a billing period
+type QuotaUsage struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Uploaded    int64
+	Downloaded  int64
+
+	// Since is the first sample counted; when it is after PeriodStart the
+	// history does not cover the whole period and the totals are lower bounds
+	Since time.Time
+}
+
+// Total returns the uploaded and downloaded bytes combined
+func (u QuotaUsage) Total() int64 {
+	return u.Uploaded + u.Downloaded
+}
+
+// Complete reports whether the history covers the whole billing period
+func (u QuotaUsage) Complete() bool {
+	return !u.Since.After(u.PeriodStart)
+}
+
+// QuotaSample reads the daemon's all-time transfer counters
+func (s *TorrentService) QuotaSample(ctx context.Context) (QuotaSample, error) {
+	_, cumulative, err := s.client.GetSessionStats(ctx)
+	if err != nil {
+		return QuotaSample{}, fmt.Errorf("failed to retrieve session stats: %w", err)
+	}
+	return QuotaSample{
+		Time:       time.Now(),
+		Uploaded:   cumulative.UploadedBytes,
+		Downloaded: cumulative.DownloadedBytes,
+	}, nil
+}
+
+// Location of the quota history in the state store; with the file backend
+// this is the quota-history.json file
+const (
+	QuotaHistoryBucket = "quota"
+	QuotaHistoryKey    = "history"
+)
+
+// LoadQuotaHistory reads the history from st; a missing record yields an empty history
+func LoadQuotaHistory(st store.Store) (*QuotaHistory, error) {
+	history := &QuotaHistory{Samples: make(map[string][]QuotaSample)}
+
+	data, err := st.Get(QuotaHistoryBucket, QuotaHistoryKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota history: %w", err)
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse quota history: %w", err)
+	}
+	if history.Samples == nil {
+		history.Samples = make(map[string][]QuotaSample)
+	}
+	return history, nil
+}
+
+// Save writes the history to st
+func (h *QuotaHistory) Save(st store.Store) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode quota history: %w", err)
+	}
+	if err := st.Put(QuotaHistoryBucket, QuotaHistoryKey, data); err != nil {
+		return fmt.Errorf("failed to write quota history: %w", err)
+	}
+	return nil
+}
+
+// Record appends sample to the connection's history and drops samples older
+// than the retention period, keeping the newest of those as a baseline
+func (h *QuotaHistory) Record(key string, sample QuotaSample) {
+	samples := append(h.Samples[key], sample)
+
+	cutoff := sample.Time.Add(-constants.QuotaHistoryRetention)
+	first := 0
+	for first+1 < len(samples) && !samples[first+1].Time.After(cutoff) {
+		first++
+	}
+	h.Samples[key] = samples[first:]
+}
+
+// Usage sums the transfers of the connection's samples since periodStart,
+// ending with current. A counter that decreased between two samples was
+// reset by the daemon, so its new value is counted in full.
+func (h *QuotaHistory) Usage(key string, periodStart, periodEnd time.Time, current QuotaSample) QuotaUsage {
+	// Count from the last sample at or before the period start, or from the
+	// earliest one inside the period when the history begins later
+	points := make([]QuotaSample, 0)
+	for _, sample := range h.Samples[key] {
+		if sample.Time.After(current.Time) {
+			break
+		}
+		if !sample.Time.After(periodStart) {
+			points = points[:0]
+		}
+		points = append(points, sample)
+	}
+	points = append(points, current)
+
+	usage := QuotaUsage{PeriodStart: periodStart, PeriodEnd: periodEnd, Since: points[0].Time}
+	if usage.Since.Before(periodStart) {
+		usage.Since = periodStart
+	}
+	for i := 1; i < len(points); i++ {
+		usage.Uploaded += counterDelta(points[i-1].Uploaded, points[i].Uploaded)
+		usage.Downloaded += counterDelta(points[i-1].Downloaded, points[i].Downloaded)
+	}
+	return usage
+}
+
+// counterDelta returns how much a counter grew from prev to next, treating a
+// decrease as a reset to zero in between
+func counterDelta(prev, next int64) int64 {
+	if next < prev {
+		return next
+	}
+	return next - prev
+}
+
+// QuotaPeriod returns the billing period containing now, which starts at
+// midnight on resetDay of each month
+func QuotaPeriod(now time.Time, resetDay int) (time.Time, time.Time) {
+	start := time.Date(now.Year(), now.Month(), resetDay, 0, 0, 0, 0, now.Location())
+	if now.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start, start.AddDate(0, 1, 0)
+}
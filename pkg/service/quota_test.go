@@ -0,0 +1,92 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/store"
+)
+
+func TestQuotaPeriod(t *testing.T) {
+	start, end := QuotaPeriod(time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC), 15)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), end)
+
+	start, _ = QuotaPeriod(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC), 15)
+	assert.Equal(t, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), start)
+}
+
+func TestQuotaHistory_Usage(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	start, end := QuotaPeriod(day(20), 1)
+
+	t.Run("counts from the last sample before the period", func(t *testing.T) {
+		h := &QuotaHistory{Samples: map[string][]QuotaSample{"default": {
+			{Time: day(1).AddDate(0, -1, 0), Uploaded: 100, Downloaded: 10},
+			{Time: start.Add(-time.Hour), Uploaded: 500, Downloaded: 50},
+			{Time: day(10), Uploaded: 700, Downloaded: 80},
+		}}}
+
+		usage := h.Usage("default", start, end, QuotaSample{Time: day(20), Uploaded: 1000, Downloaded: 100})
+		assert.Equal(t, int64(500), usage.Uploaded)
+		assert.Equal(t, int64(50), usage.Downloaded)
+		assert.Equal(t, int64(550), usage.Total())
+		assert.True(t, usage.Complete())
+	})
+
+	t.Run("counter reset counts the new value", func(t *testing.T) {
+		h := &QuotaHistory{Samples: map[string][]QuotaSample{"default": {
+			{Time: start.Add(-time.Hour), Uploaded: 500},
+			{Time: day(10), Uploaded: 700},
+		}}}
+
+		usage := h.Usage("default", start, end, QuotaSample{Time: day(20), Uploaded: 30})
+		assert.Equal(t, int64(230), usage.Uploaded)
+	})
+
+	t.Run("history starting inside the period is partial", func(t *testing.T) {
+		h := &QuotaHistory{Samples: map[string][]QuotaSample{"default": {
+			{Time: day(10), Uploaded: 700},
+		}}}
+
+		usage := h.Usage("default", start, end, QuotaSample{Time: day(20), Uploaded: 900})
+		assert.Equal(t, int64(200), usage.Uploaded)
+		assert.False(t, usage.Complete())
+		assert.Equal(t, day(10), usage.Since)
+	})
+
+	t.Run("no history", func(t *testing.T) {
+		h := &QuotaHistory{Samples: map[string][]QuotaSample{}}
+		usage := h.Usage("default", start, end, QuotaSample{Time: day(20), Uploaded: 900})
+		assert.Equal(t, int64(0), usage.Total())
+		assert.False(t, usage.Complete())
+	})
+}
+
+func TestQuotaHistory_RecordAndSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "peerless")
+	st := store.NewFileStore(dir)
+
+	h, err := LoadQuotaHistory(st)
+	require.NoError(t, err)
+	assert.Empty(t, h.Samples)
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	h.Record("default", QuotaSample{Time: now.AddDate(0, -4, 0), Uploaded: 1})
+	h.Record("default", QuotaSample{Time: now.AddDate(0, -3, 0), Uploaded: 2})
+	h.Record("default", QuotaSample{Time: now, Uploaded: 3})
+
+	// Only the newest sample beyond the retention period is kept as a baseline
+	require.Len(t, h.Samples["default"], 2)
+	assert.Equal(t, int64(2), h.Samples["default"][0].Uploaded)
+
+	require.NoError(t, h.Save(st))
+	assert.FileExists(t, filepath.Join(dir, "quota-history.json"), "the file name of earlier versions is kept")
+	loaded, err := LoadQuotaHistory(st)
+	require.NoError(t, err)
+	assert.Equal(t, h.Samples["default"][1].Uploaded, loaded.Samples["default"][1].Uploaded)
+	assert.True(t, h.Samples["default"][1].Time.Equal(loaded.Samples["default"][1].Time))
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// RelinkMatch is a torrent whose data is missing and the data found for it
+// under the searched roots
+type RelinkMatch struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	// OldPath is the local path the torrent's data was expected at
+	OldPath string `json:"old_path"`
+
+	// NewPath is the local path of the data found; its parent directory
+	// becomes the torrent's location. Empty when no data was chosen.
+	NewPath string `json:"new_path,omitempty"`
+
+	// Reason explains why no data was chosen; empty when NewPath is set
+	Reason string `json:"reason,omitempty"`
+}
+
+// Found reports whether data was found for the torrent
+func (m RelinkMatch) Found() bool {
+	return m.NewPath != ""
+}
+
+// Location returns the download directory to give the torrent, as a local path
+func (m RelinkMatch) Location() string {
+	return filepath.Dir(m.NewPath)
+}
+
+// FindRelinks searches roots for the data of the completed torrents matching
+// filters whose data is missing. Data matches when its name is the torrent's
+// and its total size the torrent's, at most maxDepth levels below a root.
+// Torrents with no match or several are returned with a Reason instead.
+func (s *TorrentService) FindRelinks(ctx context.Context, roots []string, maxDepth int, filters ...TorrentFilter) ([]RelinkMatch, error) {
+	torrents, err := s.GetTorrents(ctx, append(filters, CompletedFilter)...)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]types.TorrentInfo, 0)
+	wanted := make(map[string]bool)
+	for _, t := range torrents {
+		path := filepath.Join(s.pathMappings.ToLocal(t.DownloadDir), t.Name)
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, t)
+			wanted[utils.NormalizeName(t.Name)] = true
+		}
+	}
+	if len(missing) == 0 {
+		return []RelinkMatch{}, nil
+	}
+
+	found, err := findNamed(ctx, roots, maxDepth, wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]RelinkMatch, 0, len(missing))
+	for _, t := range missing {
+		m := RelinkMatch{ID: t.ID, Name: t.Name, OldPath: filepath.Join(s.pathMappings.ToLocal(t.DownloadDir), t.Name)}
+		candidates := found[utils.NormalizeName(t.Name)]
+
+		sized := make([]string, 0, len(candidates))
+		for _, path := range candidates {
+			size, err := utils.GetSizeInfo(path)
+			if err == nil && !size.Incomplete() && size.Size == t.TotalSize {
+				sized = append(sized, path)
+			}
+		}
+
+		switch {
+		case len(candidates) == 0:
+			m.Reason = "no item of that name under the data roots"
+		case len(sized) == 0:
+			m.Reason = fmt.Sprintf("%d item(s) of that name differ in size: %s", len(candidates), strings.Join(candidates, ", "))
+		case len(sized) > 1:
+			m.Reason = "found in several places: " + strings.Join(sized, ", ")
+		default:
+			m.NewPath = sized[0]
+		}
+		matches = append(matches, m)
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches, nil
+}
+
+// findNamed walks roots down to maxDepth levels and returns the paths of the
+// items whose normalized names are wanted, keyed by that name. Matched
+// directories are not descended into; unreadable directories are skipped.
+func findNamed(ctx context.Context, roots []string, maxDepth int, wanted map[string]bool) (map[string][]string, error) {
+	found := make(map[string][]string)
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data root %s: %w", root, err)
+		}
+		rootDepth := strings.Count(absRoot, string(filepath.Separator))
+
+		err = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				if path == absRoot {
+					return fmt.Errorf("failed to read data root: %w", err)
+				}
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if path == absRoot {
+				return nil
+			}
+
+			if key := utils.NormalizeName(d.Name()); wanted[key] {
+				found[key] = append(found[key], path)
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() && strings.Count(path, string(filepath.Separator))-rootDepth >= maxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// Relink points each found torrent at the directory holding its data, one
+// request per directory, and starts verifying them so Transmission picks the
+// data up. It returns how many torrents were relinked.
+func (s *TorrentService) Relink(ctx context.Context, matches []RelinkMatch) (int, error) {
+	byLocation := make(map[string][]int)
+	for _, m := range matches {
+		if m.Found() {
+			location := s.pathMappings.ToRemote(m.Location())
+			byLocation[location] = append(byLocation[location], m.ID)
+		}
+	}
+
+	locations := make([]string, 0, len(byLocation))
+	for location := range byLocation {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	relinked := 0
+	for _, location := range locations {
+		ids := byLocation[location]
+		if err := s.client.SetTorrentLocation(ctx, ids, location, false); err != nil {
+			return relinked, fmt.Errorf("failed to set the location of torrents to %s: %w", location, err)
+		}
+		if err := s.client.VerifyTorrents(ctx, ids); err != nil {
+			return relinked, fmt.Errorf("failed to verify torrents relinked to %s: %w", location, err)
+		}
+		relinked += len(ids)
+	}
+	return relinked, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestTorrentService_FindRelinks(t *testing.T) {
+	root := t.TempDir()
+	oldDir := filepath.Join(root, "old")
+	newRoot := filepath.Join(root, "new")
+	require.NoError(t, os.MkdirAll(oldDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(oldDir, "Present.mkv"), []byte("p"), 0644))
+
+	// Moved.mkv has the right size; the copy of Resized.mkv does not
+	require.NoError(t, os.MkdirAll(filepath.Join(newRoot, "movies"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(newRoot, "movies", "Moved.mkv"), []byte("abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(newRoot, "Resized.mkv"), []byte("a"), 0644))
+	// Twice.mkv matches in two places
+	require.NoError(t, os.MkdirAll(filepath.Join(newRoot, "a"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(newRoot, "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(newRoot, "a", "Twice.mkv"), []byte("tt"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(newRoot, "b", "Twice.mkv"), []byte("tt"), 0644))
+	// Deep.mkv lies below the search depth
+	require.NoError(t, os.MkdirAll(filepath.Join(newRoot, "x", "y", "z"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(newRoot, "x", "y", "z", "Deep.mkv"), []byte("d"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Present.mkv", "downloadDir": "` + oldDir + `", "percentDone": 1.0, "totalSize": 1},
+			{"id": 2, "name": "Moved.mkv", "downloadDir": "` + oldDir + `", "percentDone": 1.0, "totalSize": 3},
+			{"id": 3, "name": "Resized.mkv", "downloadDir": "` + oldDir + `", "percentDone": 1.0, "totalSize": 5},
+			{"id": 4, "name": "Twice.mkv", "downloadDir": "` + oldDir + `", "percentDone": 1.0, "totalSize": 2},
+			{"id": 5, "name": "Deep.mkv", "downloadDir": "` + oldDir + `", "percentDone": 1.0, "totalSize": 1},
+			{"id": 6, "name": "Partial.mkv", "downloadDir": "` + oldDir + `", "percentDone": 0.5, "totalSize": 1}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	matches, err := service.FindRelinks(context.Background(), []string{newRoot}, 3)
+	require.NoError(t, err)
+	require.Len(t, matches, 4)
+
+	assert.Equal(t, 2, matches[0].ID)
+	assert.True(t, matches[0].Found())
+	assert.Equal(t, filepath.Join(newRoot, "movies", "Moved.mkv"), matches[0].NewPath)
+	assert.Equal(t, filepath.Join(newRoot, "movies"), matches[0].Location())
+	assert.Equal(t, filepath.Join(oldDir, "Moved.mkv"), matches[0].OldPath)
+
+	assert.False(t, matches[1].Found())
+	assert.Contains(t, matches[1].Reason, "differ in size")
+	assert.False(t, matches[2].Found())
+	assert.Contains(t, matches[2].Reason, "several places")
+	assert.False(t, matches[3].Found())
+	assert.Contains(t, matches[3].Reason, "no item of that name")
+
+	t.Run("deeper search", func(t *testing.T) {
+		matches, err := service.FindRelinks(context.Background(), []string{newRoot}, 4)
+		require.NoError(t, err)
+		require.Len(t, matches, 4)
+		assert.Equal(t, filepath.Join(newRoot, "x", "y", "z", "Deep.mkv"), matches[3].NewPath)
+	})
+}
+
+func TestTorrentService_Relink(t *testing.T) {
+	type call struct {
+		Method   string
+		IDs      []int  `json:"ids"`
+		Location string `json:"location"`
+		Move     bool   `json:"move"`
+	}
+	var calls []call
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "s"}), nil
+			}
+
+			var rpcReq struct {
+				Method    string          `json:"method"`
+				Arguments json.RawMessage `json:"arguments"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &rpcReq))
+			c := call{Method: rpcReq.Method}
+			require.NoError(t, json.Unmarshal(rpcReq.Arguments, &c))
+			calls = append(calls, c)
+
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	mappings := types.PathMappings{{Remote: "/downloads", Local: "/mnt/seedbox"}}
+	service := NewTorrentServiceWithPathMappings(client.NewTransmissionClientWithHTTPClient(config, mockHTTP), mappings)
+
+	relinked, err := service.Relink(context.Background(), []RelinkMatch{
+		{ID: 1, NewPath: "/mnt/seedbox/tv/Show"},
+		{ID: 2, Reason: "no item of that name under the data roots"},
+		{ID: 3, NewPath: "/mnt/seedbox/tv/Other Show"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, relinked)
+	assert.Equal(t, []call{
+		{Method: "torrent-set-location", IDs: []int{1, 3}, Location: "/downloads/tv"},
+		{Method: "torrent-verify", IDs: []int{1, 3}},
+	}, calls)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"peerless/pkg/types"
+	"peerless/pkg/utils"
+)
+
+// looseNameSeparators matches the runs of punctuation and spaces ignored when
+// comparing names loosely, so "Some.Movie.2020" and "Some Movie (2020)" agree
+var looseNameSeparators = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// looseName returns name lowercased with punctuation and spaces removed
+func looseName(name string) string {
+	return strings.ToLower(looseNameSeparators.ReplaceAllString(name, ""))
+}
+
+// RenameSuggestion proposes renaming a local item back to the name of the
+// torrent it belongs to, so the torrent finds its data and can seed again
+type RenameSuggestion struct {
+	Path        string `json:"path"`
+	Target      string `json:"target"`
+	TorrentName string `json:"torrent"`
+
+	// Missing is set for near-miss names, which count as missing until they
+	// are renamed; items matched by size or hash already count as found
+	Missing bool  `json:"missing"`
+	Size    int64 `json:"size"`
+}
+
+// Command returns the shell command performing the rename
+func (r RenameSuggestion) Command() string {
+	return "mv -- " + utils.ShellQuote(r.Path) + " " + utils.ShellQuote(r.Target)
+}
+
+// Apply renames the item to its target, which must not exist
+func (r RenameSuggestion) Apply() error {
+	if _, err := os.Lstat(r.Target); err == nil {
+		return fmt.Errorf("cannot rename %s: %s already exists", r.Path, r.Target)
+	}
+	if err := os.Rename(r.Path, r.Target); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", r.Path, err)
+	}
+	return nil
+}
+
+// nearMiss returns the torrent whose name equals name loosely, provided the
+// name is not ambiguous between several torrents
+func (idx *torrentIndex) nearMiss(name string) (types.TorrentInfo, bool) {
+	candidates := idx.byLoose[looseName(name)]
+	if len(candidates) != 1 {
+		return types.TorrentInfo{}, false
+	}
+	return candidates[0], true
+}
+
+// suggestRenames proposes renames for the items of dir matched by size or hash
+// and for the missing items whose names are near misses of a torrent name.
+// Only complete torrents downloading to dir itself are suggested, each to one
+// item at most, and never one already found under its own name.
+func (s *TorrentService) suggestRenames(dir string, result *DirectoryResult, index *torrentIndex, nameMatched map[int]bool, nearMisses []unmatchedItem) []RenameSuggestion {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+
+	suggestions := make([]RenameSuggestion, 0)
+	claimed := make(map[int]bool)
+	suggest := func(path string, torrent types.TorrentInfo, missing bool, size int64) {
+		if claimed[torrent.ID] || nameMatched[torrent.ID] || !torrent.IsComplete() {
+			return
+		}
+		if filepath.Clean(s.pathMappings.ToLocal(torrent.DownloadDir)) != absDir {
+			return
+		}
+		target := filepath.Join(absDir, torrent.Name)
+		if target == path || filepath.Dir(target) != absDir {
+			return
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return
+		}
+		claimed[torrent.ID] = true
+		suggestions = append(suggestions, RenameSuggestion{Path: path, Target: target, TorrentName: torrent.Name, Missing: missing, Size: size})
+		if !missing {
+			return
+		}
+		for i := range result.Explanations {
+			if e := &result.Explanations[i]; e.Path == path {
+				e.Torrent = torrent.Name
+				e.Reason += fmt.Sprintf("; near miss of torrent %q, rename it to seed again", torrent.Name)
+			}
+		}
+	}
+
+	for _, match := range result.SizeMatches {
+		if torrent, ok := index.byName[utils.NormalizeName(match.TorrentName)]; ok {
+			suggest(match.Path, torrent, false, 0)
+		}
+	}
+	for _, item := range nearMisses {
+		torrent, ok := index.nearMiss(filepath.Base(item.absPath))
+		if !ok {
+			continue
+		}
+		var size int64
+		if item.size != nil {
+			size = item.size.Size
+		}
+		suggest(item.absPath, torrent, true, size)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Path < suggestions[j].Path })
+	return suggestions
+}
+
+// RenameSuggestions returns the rename suggestions of every directory
+func (r *DirectoryCheckResult) RenameSuggestions() []RenameSuggestion {
+	suggestions := make([]RenameSuggestion, 0)
+	for _, dirResult := range r.Directories {
+		suggestions = append(suggestions, dirResult.RenameSuggestions...)
+	}
+	return suggestions
+}
+
+// Renamed records that a suggestion was applied. It is no longer suggested,
+// and a near-miss item counts as found under its new name instead of missing.
+func (r *DirectoryCheckResult) Renamed(suggestion RenameSuggestion) {
+	for i := range r.Directories {
+		dirResult := &r.Directories[i]
+		idx := slices.Index(dirResult.RenameSuggestions, suggestion)
+		if idx < 0 {
+			continue
+		}
+		dirResult.RenameSuggestions = slices.Delete(dirResult.RenameSuggestions, idx, idx+1)
+		if !suggestion.Missing {
+			return
+		}
+
+		dirResult.MissingPaths = slices.DeleteFunc(dirResult.MissingPaths, func(p string) bool { return p == suggestion.Path })
+		dirResult.MissingSize -= suggestion.Size
+		dirResult.FoundItems++
+		for j := range dirResult.Explanations {
+			if e := &dirResult.Explanations[j]; e.Path == suggestion.Path {
+				*e = MatchExplanation{Path: suggestion.Target, Matcher: MatcherName, Torrent: suggestion.TorrentName}
+			}
+		}
+		r.MissingPaths = slices.DeleteFunc(r.MissingPaths, func(p string) bool { return p == suggestion.Path })
+		r.TotalMissingSize -= suggestion.Size
+		r.TotalFound++
+		return
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+func TestLooseName(t *testing.T) {
+	assert.Equal(t, looseName("Some Movie (2020)"), looseName("some.movie.2020"))
+	assert.Equal(t, looseName("Band - Album [FLAC]"), looseName("Band_Album_FLAC"))
+	assert.NotEqual(t, looseName("Some Movie 2020"), looseName("Some Movie 2021"))
+}
+
+func TestRenameSuggestion_Command(t *testing.T) {
+	r := RenameSuggestion{Path: "/data/it's here", Target: "/data/It's Here"}
+	assert.Equal(t, `mv -- '/data/it'\''s here' '/data/It'\''s Here'`, r.Command())
+}
+
+func TestTorrentService_CheckDirectories_RenameSuggestions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"some.movie.2020", "Other Show", "elsewhere.album", "Twin A"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644))
+	}
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Some Movie (2020)", "downloadDir": "` + dir + `", "percentDone": 1.0, "status": 6},
+			{"id": 2, "name": "Other Show", "downloadDir": "` + dir + `", "percentDone": 1.0, "status": 6},
+			{"id": 3, "name": "Elsewhere Album", "downloadDir": "/other", "percentDone": 1.0, "status": 6},
+			{"id": 4, "name": "Twin.A", "downloadDir": "` + dir + `", "percentDone": 1.0, "status": 6},
+			{"id": 5, "name": "twin_a", "downloadDir": "` + dir + `", "percentDone": 1.0, "status": 6}
+		]}, "result": "success"}`,
+		"session-get": `{"arguments": {}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{dir}, CheckOptions{Explain: true})
+	require.NoError(t, err)
+	require.Len(t, result.Directories, 1)
+
+	// Torrents downloading elsewhere and ambiguous names get no suggestion
+	renames := result.RenameSuggestions()
+	require.Len(t, renames, 1)
+	rename := renames[0]
+	assert.Equal(t, filepath.Join(dir, "some.movie.2020"), rename.Path)
+	assert.Equal(t, filepath.Join(dir, "Some Movie (2020)"), rename.Target)
+	assert.True(t, rename.Missing)
+	assert.Equal(t, int64(1), rename.Size)
+	assert.Contains(t, result.MissingPaths, rename.Path)
+	assert.Equal(t, result.Report().Renames, renames)
+
+	require.NoError(t, rename.Apply())
+	result.Renamed(rename)
+
+	assert.FileExists(t, rename.Target)
+	assert.NotContains(t, result.MissingPaths, rename.Path)
+	assert.NotContains(t, result.Directories[0].MissingPaths, rename.Path)
+	assert.Equal(t, 2, result.TotalFound)
+	assert.Equal(t, int64(2), result.TotalMissingSize)
+	assert.Empty(t, result.RenameSuggestions())
+	assert.NotContains(t, result.MissingReasons(), rename.Path)
+}
+
+func TestRenameSuggestion_ApplyRefusesExistingTarget(t *testing.T) {
+	dir := t.TempDir()
+	r := RenameSuggestion{Path: filepath.Join(dir, "a"), Target: filepath.Join(dir, "b")}
+	require.NoError(t, os.WriteFile(r.Path, []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(r.Target, []byte("b"), 0644))
+
+	assert.Error(t, r.Apply())
+	assert.FileExists(t, r.Path)
+}
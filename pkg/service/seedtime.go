@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+)
+
+// SeedGoals are the times torrents should seed for. Trackers maps text
+// contained in an announce URL, ignoring case, to the goal of the torrents
+// using that tracker; the others get Default. A goal of zero means none.
+type SeedGoals struct {
+	Default  time.Duration
+	Trackers map[string]time.Duration
+}
+
+// For returns the goal of t. When several tracker entries match, the longest
+// one wins, so "tracker.example.org" can refine "example.org".
+func (g SeedGoals) For(t types.TorrentInfo) time.Duration {
+	goal, best := g.Default, ""
+	for match, d := range g.Trackers {
+		lowered := strings.ToLower(match)
+		for _, tracker := range t.Trackers {
+			if !strings.Contains(strings.ToLower(tracker.Announce), lowered) {
+				continue
+			}
+			if len(match) > len(best) || (len(match) == len(best) && match < best) {
+				goal, best = d, match
+			}
+		}
+	}
+	return goal
+}
+
+// TrackerName returns the host of the torrent's first announce URL, or
+// constants.NoTrackerName when it has none
+func TrackerName(t types.TorrentInfo) string {
+	for _, tracker := range t.Trackers {
+		if u, err := url.Parse(tracker.Announce); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	return constants.NoTrackerName
+}
+
+// TorrentSeedTime is how long a finished torrent has seeded against its goal
+type TorrentSeedTime struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Tracker string    `json:"tracker"`
+	Done    time.Time `json:"done,omitzero"`
+
+	// SecondsSeeding is the total time spent seeding
+	SecondsSeeding int64 `json:"secondsSeeding"`
+
+	// GoalSeconds is the seed-time goal, 0 when none applies
+	GoalSeconds int64 `json:"goalSeconds"`
+}
+
+// GoalMet reports whether the torrent has seeded for its goal. Torrents
+// without a goal always meet it.
+func (s TorrentSeedTime) GoalMet() bool {
+	return s.SecondsSeeding >= s.GoalSeconds
+}
+
+// Remaining returns the seeding time left until the goal is met
+func (s TorrentSeedTime) Remaining() time.Duration {
+	return time.Duration(max(0, s.GoalSeconds-s.SecondsSeeding)) * time.Second
+}
+
+// TrackerSeedTime aggregates the seed times of the torrents of one tracker
+type TrackerSeedTime struct {
+	Tracker  string `json:"tracker"`
+	Torrents int    `json:"torrents"`
+
+	// GoalMet counts the torrents that have seeded for their goal
+	GoalMet int `json:"goalMet"`
+
+	// SecondsSeeding is the seeding time of all the torrents together
+	SecondsSeeding int64 `json:"secondsSeeding"`
+}
+
+// Average returns the mean seeding time per torrent
+func (s TrackerSeedTime) Average() time.Duration {
+	if s.Torrents == 0 {
+		return 0
+	}
+	return time.Duration(s.SecondsSeeding/int64(s.Torrents)) * time.Second
+}
+
+// SeededForFilter matches torrents that have seeded for at least d
+func SeededForFilter(d time.Duration) TorrentFilter {
+	return func(t types.TorrentInfo) bool {
+		return time.Duration(t.SecondsSeeding)*time.Second >= d
+	}
+}
+
+// SeedTimes reports the seeding time of the finished torrents matching
+// filters against goals. Torrents furthest from their goal come first,
+// followed by those that met it, longest seeding first.
+func (s *TorrentService) SeedTimes(ctx context.Context, goals SeedGoals, filters ...TorrentFilter) ([]TorrentSeedTime, error) {
+	torrents, err := s.GetTorrents(ctx, append([]TorrentFilter{CompletedFilter}, filters...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]TorrentSeedTime, 0, len(torrents))
+	for _, t := range torrents {
+		entry := TorrentSeedTime{
+			ID:             t.ID,
+			Name:           t.Name,
+			Tracker:        TrackerName(t),
+			SecondsSeeding: t.SecondsSeeding,
+			GoalSeconds:    int64(goals.For(t) / time.Second),
+		}
+		if t.DoneDate > 0 {
+			entry.Done = time.Unix(t.DoneDate, 0)
+		}
+		report = append(report, entry)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if ri, rj := report[i].Remaining(), report[j].Remaining(); ri != rj {
+			return ri > rj
+		}
+		return report[i].SecondsSeeding > report[j].SecondsSeeding
+	})
+	return report, nil
+}
+
+// TrackerSeedTimes groups a SeedTimes report by tracker, sorted by tracker name
+func TrackerSeedTimes(report []TorrentSeedTime) []TrackerSeedTime {
+	byTracker := make(map[string]*TrackerSeedTime)
+	for _, entry := range report {
+		stats, exists := byTracker[entry.Tracker]
+		if !exists {
+			stats = &TrackerSeedTime{Tracker: entry.Tracker}
+			byTracker[entry.Tracker] = stats
+		}
+		stats.Torrents++
+		stats.SecondsSeeding += entry.SecondsSeeding
+		if entry.GoalMet() {
+			stats.GoalMet++
+		}
+	}
+
+	result := make([]TrackerSeedTime, 0, len(byTracker))
+	for _, stats := range byTracker {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tracker < result[j].Tracker
+	})
+	return result
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+)
+
+func TestSeedGoalsFor(t *testing.T) {
+	goals := SeedGoals{
+		Default: 14 * 24 * time.Hour,
+		Trackers: map[string]time.Duration{
+			"example.org":         3 * 24 * time.Hour,
+			"tracker.example.org": 30 * 24 * time.Hour,
+		},
+	}
+	torrent := func(announce string) types.TorrentInfo {
+		return types.TorrentInfo{Trackers: []types.Tracker{{Announce: announce}}}
+	}
+
+	assert.Equal(t, 30*24*time.Hour, goals.For(torrent("https://Tracker.Example.org/announce")), "longest match wins")
+	assert.Equal(t, 3*24*time.Hour, goals.For(torrent("https://other.example.org/announce")))
+	assert.Equal(t, 14*24*time.Hour, goals.For(torrent("udp://open.tracker:1337")))
+	assert.Equal(t, 14*24*time.Hour, goals.For(types.TorrentInfo{}))
+}
+
+func TestTrackerName(t *testing.T) {
+	assert.Equal(t, "tracker.example.org", TrackerName(types.TorrentInfo{Trackers: []types.Tracker{{Announce: "https://tracker.example.org:443/announce?passkey=secret"}}}))
+	assert.Equal(t, constants.NoTrackerName, TrackerName(types.TorrentInfo{}))
+}
+
+func TestSeedTimes(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	mock := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "Done", "downloadDir": "/downloads", "percentDone": 1.0, "doneDate": 1700000000,
+			 "secondsSeeding": ` + strconv.FormatInt(20*day, 10) + `, "trackers": [{"announce": "https://a.example/announce"}]},
+			{"id": 2, "name": "Halfway", "downloadDir": "/downloads", "percentDone": 1.0,
+			 "secondsSeeding": ` + strconv.FormatInt(7*day, 10) + `, "trackers": [{"announce": "https://a.example/announce"}]},
+			{"id": 3, "name": "Fresh", "downloadDir": "/other", "percentDone": 1.0,
+			 "secondsSeeding": ` + strconv.FormatInt(day, 10) + `, "trackers": [{"announce": "https://b.example/announce"}]},
+			{"id": 4, "name": "Downloading", "downloadDir": "/downloads", "percentDone": 0.5}
+		]}, "result": "success"}`,
+	})
+	svc := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mock))
+	goals := SeedGoals{Default: 14 * 24 * time.Hour}
+
+	report, err := svc.SeedTimes(context.Background(), goals)
+	require.NoError(t, err)
+	require.Len(t, report, 3, "unfinished torrents are left out")
+
+	assert.Equal(t, "Fresh", report[0].Name, "furthest from the goal first")
+	assert.Equal(t, 13*24*time.Hour, report[0].Remaining())
+	assert.Equal(t, "Halfway", report[1].Name)
+	assert.Equal(t, "Done", report[2].Name)
+	assert.True(t, report[2].GoalMet())
+	assert.Equal(t, "a.example", report[2].Tracker)
+	assert.Equal(t, time.Unix(1700000000, 0), report[2].Done)
+
+	trackers := TrackerSeedTimes(report)
+	require.Len(t, trackers, 2)
+	assert.Equal(t, TrackerSeedTime{Tracker: "a.example", Torrents: 2, GoalMet: 1, SecondsSeeding: 27 * day}, trackers[0])
+	assert.Equal(t, time.Duration(27*day/2)*time.Second, trackers[0].Average())
+
+	t.Run("filters", func(t *testing.T) {
+		report, err := svc.SeedTimes(context.Background(), goals, SeededForFilter(7*24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, report, 2)
+		assert.Equal(t, "Halfway", report[0].Name)
+	})
+}
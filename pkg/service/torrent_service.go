@@ -5,22 +5,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"peerless/pkg/client"
+	"peerless/pkg/constants"
 	"peerless/pkg/types"
 	"peerless/pkg/utils"
 )
 
 // TorrentService handles torrent-related business logic
 type TorrentService struct {
-	client *client.TransmissionClient
+	client       client.Client
+	pathMappings types.PathMappings
 }
 
 // NewTorrentService creates a new TorrentService
-func NewTorrentService(client *client.TransmissionClient) *TorrentService {
+func NewTorrentService(client client.Client) *TorrentService {
 	return &TorrentService{client: client}
 }
 
+// NewTorrentServiceWithPathMappings creates a TorrentService that translates
+// Transmission's paths into local ones using mappings
+func NewTorrentServiceWithPathMappings(client client.Client, mappings types.PathMappings) *TorrentService {
+	return &TorrentService{client: client, pathMappings: mappings}
+}
+
 // DirectoryCheckResult contains the results of checking directories
 type DirectoryCheckResult struct {
 	Directories      []DirectoryResult
@@ -28,6 +40,9 @@ type DirectoryCheckResult struct {
 	TotalFound       int
 	TotalMissingSize int64
 	MissingPaths     []string
+
+	// IncompleteSize is set when any directory's missing size is a lower bound
+	IncompleteSize bool
 }
 
 // DirectoryResult contains results for a single directory
@@ -37,78 +52,403 @@ type DirectoryResult struct {
 	FoundItems   int
 	MissingSize  int64
 	MissingPaths []string
+
+	// InaccessiblePaths lists paths skipped while sizing missing items;
+	// when non-empty MissingSize underreports the real size
+	InaccessiblePaths []string
+	IncompleteSize    bool
+
+	// InProgressItems counts found items belonging to unfinished torrents
+	InProgressItems int
+
+	// IsIncompleteDir is set when Path is Transmission's incomplete-dir
+	IsIncompleteDir bool
+
+	// SizeMatches lists items found by size and file count instead of name
+	SizeMatches []SizeMatch
+
+	// RenameSuggestions proposes renaming size matches and near-miss missing
+	// items back to their torrent names
+	RenameSuggestions []RenameSuggestion
+
+	// ExcludedItems counts missing items skipped by the age or size options
+	ExcludedItems int
+
+	// KeptItems counts missing items retained by the keep list
+	KeptItems int
+
+	// Explanations records how each item matched or why it did not; only
+	// filled in with CheckOptions.Explain
+	Explanations []MatchExplanation
+
+	// FileMismatches lists matched torrents whose local files differ from
+	// their file list; only filled in with MatchFiles
+	FileMismatches []FileMismatch
+
+	// Err is set when the directory could not be checked, e.g. because it is
+	// unreadable or its mount is gone; all counts are then zero
+	Err error
+}
+
+// CheckOptions controls optional matching behaviour of CheckDirectoriesWithOptions
+type CheckOptions struct {
+	// MatchBySize matches items whose name matches no torrent by total size and
+	// top-level file count, catching renamed but otherwise identical content
+	MatchBySize bool
+
+	// MatchByHash matches items whose name matches no torrent by total size and
+	// a fingerprint of their file structure, the size and depth of every file.
+	// It is stricter than MatchBySize and still finds content whose files were
+	// renamed. Transmission does not expose piece hashes, so data is not read.
+	MatchByHash bool
+
+	// MatchFiles compares the files inside each directory matched by name with
+	// its torrent's file list, so partially deleted or restructured torrents are
+	// detected. Local files outside the list are reported as missing.
+	MatchFiles bool
+
+	// ModifiedBefore, when set, excludes missing items modified at or after it
+	ModifiedBefore time.Time
+
+	// MinSize excludes missing items smaller than this many bytes
+	MinSize int64
+
+	// Keep, when set, lists local-only content never reported missing
+	Keep *utils.KeepList
+
+	// Explain records a MatchExplanation for every item
+	Explain bool
+
+	// Progress, when set, is called after each local item is scanned
+	Progress ScanProgressCallback
+
+	// Throttle, when set, rate limits the filesystem calls made to size unmatched items
+	Throttle *utils.Throttle
+}
+
+// ScanProgressCallback is called for each item scanned in dir. Size is the
+// size of the item when it matched no torrent by name, otherwise zero.
+type ScanProgressCallback func(dir string, current, total int, size int64)
+
+// SizeMatch records a local item matched to a torrent by size rather than name
+type SizeMatch struct {
+	Path        string
+	TorrentName string
 }
 
 // CheckDirectories checks local directories against Transmission torrents
 func (s *TorrentService) CheckDirectories(ctx context.Context, dirs []string) (*DirectoryCheckResult, error) {
-	torrents, err := s.client.GetTorrents(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	return s.CheckDirectoriesWithOptions(ctx, dirs, CheckOptions{})
+}
+
+// CheckDirectoriesWithOptions checks local directories against Transmission torrents.
+// The torrent list is fetched while the directories are being opened, since
+// neither depends on the other. A directory that cannot be read does not stop
+// the others from being checked; its result carries the error instead.
+func (s *TorrentService) CheckDirectoriesWithOptions(ctx context.Context, dirs []string, opts CheckOptions) (*DirectoryCheckResult, error) {
+	fetched := make(chan torrentSnapshot, 1)
+	go func() {
+		fetched <- s.fetchSnapshot(ctx)
+	}()
+
+	// Open every directory and read its first batch while the RPC is in flight
+	listings := make([]*dirListing, len(dirs))
+	listErrs := make([]error, len(dirs))
+	for i, dir := range dirs {
+		listings[i], listErrs[i] = openListing(dir)
 	}
+	defer func() {
+		for _, listing := range listings {
+			if listing != nil {
+				listing.Close()
+			}
+		}
+	}()
 
-	torrentMap := make(map[string]bool)
-	for _, t := range torrents {
-		torrentMap[utils.NormalizeName(t.Name)] = true
+	snapshot := <-fetched
+	if snapshot.err != nil {
+		return nil, snapshot.err
 	}
+	sessionInfo := snapshot.session
+	index := newTorrentIndex(snapshot.torrents, sessionInfo)
 
 	result := &DirectoryCheckResult{
 		Directories: make([]DirectoryResult, 0, len(dirs)),
 	}
 
-	for _, dir := range dirs {
-		dirResult, err := s.checkSingleDirectory(dir, torrentMap)
+	for i, dir := range dirs {
+		if listErrs[i] != nil {
+			result.add(DirectoryResult{Path: dir, Err: fmt.Errorf("failed to read directory: %w", listErrs[i])})
+			continue
+		}
+		dirResult, err := s.checkListing(ctx, dir, listings[i], index, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check directory %s: %w", dir, err)
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to check directory %s: %w", dir, err)
+			}
+			result.add(DirectoryResult{Path: dir, Err: err})
+			continue
 		}
-
-		result.Directories = append(result.Directories, *dirResult)
-		result.TotalItems += dirResult.TotalItems
-		result.TotalFound += dirResult.FoundItems
-		result.TotalMissingSize += dirResult.MissingSize
-		result.MissingPaths = append(result.MissingPaths, dirResult.MissingPaths...)
+		dirResult.IsIncompleteDir = isIncompleteDir(dir, sessionInfo)
+		result.add(*dirResult)
 	}
 
 	return result, nil
 }
 
+// torrentSnapshot holds the daemon state a check is matched against
+type torrentSnapshot struct {
+	torrents []types.TorrentInfo
+	session  *types.SessionInfo
+	err      error
+}
+
+// fetchSnapshot retrieves the torrents and session settings used for matching
+func (s *TorrentService) fetchSnapshot(ctx context.Context) torrentSnapshot {
+	torrents, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return torrentSnapshot{err: fmt.Errorf("failed to retrieve torrents: %w", err)}
+	}
+
+	sessionInfo, err := s.client.GetSessionInfo(ctx)
+	if err != nil {
+		return torrentSnapshot{err: fmt.Errorf("failed to retrieve session info: %w", err)}
+	}
+	sessionInfo.IncompleteDir = s.pathMappings.ToLocal(sessionInfo.IncompleteDir)
+
+	return torrentSnapshot{torrents: torrents, session: sessionInfo}
+}
+
+// Merge appends the directories of other, e.g. ones checked against another daemon
+func (r *DirectoryCheckResult) Merge(other *DirectoryCheckResult) {
+	for _, dirResult := range other.Directories {
+		r.add(dirResult)
+	}
+}
+
+// Failed returns the directories that could not be checked
+func (r *DirectoryCheckResult) Failed() []DirectoryResult {
+	var failed []DirectoryResult
+	for _, dirResult := range r.Directories {
+		if dirResult.Err != nil {
+			failed = append(failed, dirResult)
+		}
+	}
+	return failed
+}
+
+// add appends a directory result and updates the totals
+func (r *DirectoryCheckResult) add(dirResult DirectoryResult) {
+	r.Directories = append(r.Directories, dirResult)
+	r.TotalItems += dirResult.TotalItems
+	r.TotalFound += dirResult.FoundItems
+	r.TotalMissingSize += dirResult.MissingSize
+	r.MissingPaths = append(r.MissingPaths, dirResult.MissingPaths...)
+	r.IncompleteSize = r.IncompleteSize || dirResult.IncompleteSize
+}
+
+// unmatchedItem is a local item whose name matched no torrent
+type unmatchedItem struct {
+	fullPath string
+	absPath  string
+	size     *utils.SizeInfo
+}
+
 // checkSingleDirectory checks a single directory
-func (s *TorrentService) checkSingleDirectory(dir string, torrentMap map[string]bool) (*DirectoryResult, error) {
-	entries, err := os.ReadDir(dir)
+func (s *TorrentService) checkSingleDirectory(ctx context.Context, dir string, index *torrentIndex, opts CheckOptions) (*DirectoryResult, error) {
+	listing, err := openListing(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
+	defer listing.Close()
+	return s.checkListing(ctx, dir, listing, index, opts)
+}
 
+// checkListing matches the entries of dir against index batch by batch.
+// Unmatched items are only kept around when MatchBySize needs them, so memory
+// stays bounded by the batch size and the missing items found.
+func (s *TorrentService) checkListing(ctx context.Context, dir string, listing *dirListing, index *torrentIndex, opts CheckOptions) (*DirectoryResult, error) {
 	result := &DirectoryResult{
-		Path:         dir,
-		TotalItems:   len(entries),
-		MissingPaths: make([]string, 0),
+		Path:              dir,
+		MissingPaths:      make([]string, 0),
+		InaccessiblePaths: make([]string, 0),
+		SizeMatches:       make([]SizeMatch, 0),
+		FileMismatches:    make([]FileMismatch, 0),
 	}
 
-	for _, entry := range entries {
-		name := entry.Name()
-		inTransmission := torrentMap[utils.NormalizeName(name)]
+	nameMatched := make(map[int]bool)
+	unmatched := make([]unmatchedItem, 0)
+	// Missing items resembling a torrent name, kept for rename suggestions
+	nearMisses := make([]unmatchedItem, 0)
+	fileCandidates := make([]fileCandidate, 0)
+
+	for last := false; !last; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var batch []os.DirEntry
+		var err error
+		batch, last, err = listing.next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+
+		// The total is only known once the final batch has been read
+		total := 0
+		if last {
+			total = result.TotalItems + len(batch)
+		}
+
+		for _, entry := range batch {
+			name := entry.Name()
+			if utils.IsDeletedItemsDir(name) {
+				// Items peerless staged or trashed on this filesystem
+				continue
+			}
+			result.TotalItems++
+			torrent, inTransmission, inProgress := index.lookup(name)
+
+			if inTransmission {
+				result.FoundItems++
+				nameMatched[torrent.ID] = true
+				if opts.Explain {
+					result.Explanations = append(result.Explanations, index.explainLookup(dir, name, torrent, s.pathMappings.ToLocal(torrent.DownloadDir), inProgress))
+				}
+				if inProgress {
+					result.InProgressItems++
+				} else if opts.MatchFiles && entry.IsDir() {
+					fullPath := filepath.Join(dir, name)
+					absPath, err := filepath.Abs(fullPath)
+					if err != nil {
+						absPath = fullPath
+					}
+					fileCandidates = append(fileCandidates, fileCandidate{fullPath: fullPath, absPath: absPath, torrent: torrent})
+				}
+				if opts.Progress != nil {
+					opts.Progress(dir, result.TotalItems, total, 0)
+				}
+				continue
+			}
 
-		if inTransmission {
-			result.FoundItems++
-		} else {
 			fullPath := filepath.Join(dir, name)
 			absPath, err := filepath.Abs(fullPath)
 			if err != nil {
 				absPath = fullPath
 			}
 
-			result.MissingPaths = append(result.MissingPaths, absPath)
+			item := unmatchedItem{fullPath: fullPath, absPath: absPath}
+			item.size, err = utils.GetSizeInfoThrottled(fullPath, opts.Throttle)
+			if err != nil {
+				item.size = nil
+			}
+
+			if opts.Progress != nil {
+				var size int64
+				if item.size != nil {
+					size = item.size.Size
+				}
+				opts.Progress(dir, result.TotalItems, total, size)
+			}
+
+			if opts.MatchBySize || opts.MatchByHash {
+				unmatched = append(unmatched, item)
+				continue
+			}
+			outcome := addMissing(result, item, opts)
+			if _, ok := index.nearMiss(name); ok && outcome == "" {
+				nearMisses = append(nearMisses, item)
+			}
+			if opts.Explain {
+				result.Explanations = append(result.Explanations, index.explainMissing(item, outcome, nameMatched, opts))
+			}
+		}
+	}
+
+	if len(unmatched) > 0 {
+		sizeMatched, err := s.matchBySize(ctx, unmatched, index, nameMatched, opts)
+		if err != nil {
+			return nil, err
+		}
 
-			size, err := utils.GetSize(fullPath)
-			if err == nil {
-				result.MissingSize += size
+		for _, item := range unmatched {
+			if torrent, ok := sizeMatched[item.absPath]; ok {
+				result.FoundItems++
+				result.SizeMatches = append(result.SizeMatches, SizeMatch{Path: item.absPath, TorrentName: torrent.Name})
+				if opts.Explain {
+					matcher := MatcherSize
+					if opts.MatchByHash {
+						matcher = MatcherHash
+					}
+					result.Explanations = append(result.Explanations, MatchExplanation{Path: item.absPath, Matcher: matcher, Torrent: torrent.Name})
+				}
+				continue
+			}
+			outcome := addMissing(result, item, opts)
+			if _, ok := index.nearMiss(filepath.Base(item.absPath)); ok && outcome == "" {
+				nearMisses = append(nearMisses, item)
 			}
+			if opts.Explain {
+				result.Explanations = append(result.Explanations, index.explainMissing(item, outcome, nameMatched, opts))
+			}
+		}
+	}
+
+	if len(fileCandidates) > 0 {
+		if err := s.matchFiles(ctx, fileCandidates, result, opts); err != nil {
+			return nil, err
 		}
 	}
 
+	result.RenameSuggestions = s.suggestRenames(dir, result, index, nameMatched, nearMisses)
+
+	// Batches arrive in directory order; sort to keep reports stable
+	sort.Strings(result.MissingPaths)
+	sort.Slice(result.Explanations, func(i, j int) bool { return result.Explanations[i].Path < result.Explanations[j].Path })
+	result.IncompleteSize = len(result.InaccessiblePaths) > 0
+
 	return result, nil
 }
 
+// addMissing records an item that matched no torrent, unless the keep list
+// retains it or the age or size options exclude it. It returns why the item
+// was not recorded, or an empty string when it was.
+func addMissing(result *DirectoryResult, item unmatchedItem, opts CheckOptions) string {
+	if opts.Keep.Keeps(item.absPath, result.Path) {
+		result.KeptItems++
+		return reasonKept
+	}
+	if excludedByOptions(item, opts) {
+		result.ExcludedItems++
+		return reasonExcluded
+	}
+
+	result.MissingPaths = append(result.MissingPaths, item.absPath)
+	if item.size == nil {
+		result.InaccessiblePaths = append(result.InaccessiblePaths, item.absPath)
+		return ""
+	}
+	result.MissingSize += item.size.Size
+	result.InaccessiblePaths = append(result.InaccessiblePaths, item.size.Inaccessible...)
+	return ""
+}
+
+// excludedByOptions reports whether a missing item falls outside the age or size options
+func excludedByOptions(item unmatchedItem, opts CheckOptions) bool {
+	if opts.MinSize > 0 && (item.size == nil || item.size.Size < opts.MinSize) {
+		return true
+	}
+
+	if !opts.ModifiedBefore.IsZero() {
+		info, err := os.Lstat(item.fullPath)
+		if err != nil || !info.ModTime().Before(opts.ModifiedBefore) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // TorrentStatistics contains statistics about torrents
 type TorrentStatistics struct {
 	TotalTorrents int
@@ -141,7 +481,12 @@ type DetailedStatus struct {
 	DownloadingTorrents int
 	SeedingTorrents     int
 	PausedTorrents      int
-	CompletedTorrents   int
+	CompletedTorrents   int // finished and stopped
+	QueuedTorrents      int
+	VerifyingTorrents   int
+
+	// CompletedSeedingTorrents counts finished torrents that are still seeding
+	CompletedSeedingTorrents int
 
 	// Size information
 	TotalSize      int64
@@ -158,12 +503,81 @@ type DetailedStatus struct {
 	PeerPort        int
 	AltSpeedEnabled bool
 
+	// DiskQuota is set by callers that know the account's storage quota; it is
+	// shown instead of FreeSpace, which is often wrong on shared seedboxes
+	DiskQuota *utils.DiskUsage
+
 	// Statistics
 	CurrentSessionStats *types.SessionStats
 	CumulativeStats     *types.SessionStats
 
+	// Torrent counts by time since they were added
+	AgeBuckets AgeBuckets
+
+	// Private and public torrents, which usually need different cleanup policies
+	Private TorrentGroup
+	Public  TorrentGroup
+
 	// Torrent breakdown by directory
 	DirectoryBreakdown map[string]DirectoryStatus
+
+	// SpaceShortages lists directories without the free space their
+	// downloading torrents still need, sorted by path
+	SpaceShortages []SpaceShortage
+}
+
+// SpaceShortage is a directory whose downloading torrents have more left to
+// download than its filesystem has free, so they will fail to finish
+type SpaceShortage struct {
+	Path      string
+	Remaining int64
+	FreeSpace int64
+	Torrents  int
+}
+
+// Missing returns how many more bytes must be freed for the downloads to finish
+func (s SpaceShortage) Missing() int64 {
+	return s.Remaining - s.FreeSpace
+}
+
+// TorrentGroup is the number and total size of a group of torrents
+type TorrentGroup struct {
+	Count     int
+	TotalSize int64
+}
+
+// add counts t in the group
+func (g *TorrentGroup) add(t types.TorrentInfo) {
+	g.Count++
+	g.TotalSize += t.TotalSize
+}
+
+// AgeBuckets counts torrents by how long ago they were added
+type AgeBuckets struct {
+	LastWeek     int // under 7 days
+	LastMonth    int // 7 to 30 days
+	LastHalfYear int // 30 to 180 days
+	Older        int // over 180 days
+	Unknown      int // no added date reported
+}
+
+// add counts a torrent added at addedDate (Unix seconds) relative to now
+func (b *AgeBuckets) add(addedDate int64, now time.Time) {
+	if addedDate <= 0 {
+		b.Unknown++
+		return
+	}
+
+	switch age := now.Sub(time.Unix(addedDate, 0)); {
+	case age < constants.AgeBucketWeek:
+		b.LastWeek++
+	case age < constants.AgeBucketMonth:
+		b.LastMonth++
+	case age < constants.AgeBucketHalfYear:
+		b.LastHalfYear++
+	default:
+		b.Older++
+	}
 }
 
 // DirectoryStatus contains status for a specific download directory
@@ -172,6 +586,19 @@ type DirectoryStatus struct {
 	TotalSize      int64
 	DownloadedSize int64
 	FreeSpace      int64
+
+	// RemainingSize is left to download by its downloading or queued torrents
+	RemainingSize int64
+}
+
+// BreakdownDirectories returns the directory breakdown as a list sorted by path
+func (st *DetailedStatus) BreakdownDirectories() []utils.DirectoryInfo {
+	dirs := make([]utils.DirectoryInfo, 0, len(st.DirectoryBreakdown))
+	for path, d := range st.DirectoryBreakdown {
+		dirs = append(dirs, utils.DirectoryInfo{Path: path, Count: d.TorrentCount, TotalSize: d.TotalSize})
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	return dirs
 }
 
 // GetDetailedStatus returns comprehensive Transmission status
@@ -211,7 +638,16 @@ func (s *TorrentService) GetDetailedStatus(ctx context.Context) (*DetailedStatus
 	}
 
 	// Process torrents
+	now := time.Now()
+	pending := make(map[string]*SpaceShortage)
 	for _, torrent := range torrents {
+		status.AgeBuckets.add(torrent.AddedDate, now)
+		if torrent.IsPrivate {
+			status.Private.add(torrent)
+		} else {
+			status.Public.add(torrent)
+		}
+
 		status.TotalSize += torrent.TotalSize
 		status.DownloadedSize += torrent.DownloadedEver
 		status.RemainingSize += torrent.LeftUntilDone
@@ -220,22 +656,26 @@ func (s *TorrentService) GetDetailedStatus(ctx context.Context) (*DetailedStatus
 
 		// Count by status
 		switch torrent.Status {
-		case 0: // Stopped
-			if torrent.PercentDone >= 1.0 {
+		case types.StatusStopped:
+			if torrent.IsComplete() {
 				status.CompletedTorrents++
 			} else {
 				status.PausedTorrents++
 			}
-		case 1: // Queued to verify
-		case 2: // Verifying
-		case 3: // Queued to download
-		case 4: // Downloading
+		case types.StatusQueuedVerify, types.StatusVerifying:
+			status.VerifyingTorrents++
+		case types.StatusQueuedDownload, types.StatusQueuedSeed:
+			status.QueuedTorrents++
+		case types.StatusDownloading:
 			status.DownloadingTorrents++
-		case 5: // Queued to seed
-		case 6: // Seeding
+		case types.StatusSeeding:
 			status.SeedingTorrents++
 		}
 
+		if torrent.IsComplete() && torrent.Status.IsSeeding() {
+			status.CompletedSeedingTorrents++
+		}
+
 		// Directory breakdown
 		dirStatus, exists := status.DirectoryBreakdown[torrent.DownloadDir]
 		if !exists {
@@ -248,22 +688,60 @@ func (s *TorrentService) GetDetailedStatus(ctx context.Context) (*DetailedStatus
 		dirStatus.TotalSize += torrent.TotalSize
 		dirStatus.DownloadedSize += torrent.DownloadedEver
 
+		// Unfinished data is written to the incomplete-dir while it is enabled
+		if torrent.LeftUntilDone > 0 && (torrent.Status == types.StatusDownloading || torrent.Status == types.StatusQueuedDownload) {
+			dirStatus.RemainingSize += torrent.LeftUntilDone
+			target := torrent.DownloadDir
+			if sessionInfo.IncompleteDirEnabled && sessionInfo.IncompleteDir != "" {
+				target = sessionInfo.IncompleteDir
+			}
+			if pending[target] == nil {
+				pending[target] = &SpaceShortage{Path: target}
+			}
+			pending[target].Remaining += torrent.LeftUntilDone
+			pending[target].Torrents++
+		}
+
 		status.DirectoryBreakdown[torrent.DownloadDir] = dirStatus
 	}
 
+	status.SpaceShortages = s.spaceShortages(ctx, pending, sessionInfo)
 	return status, nil
 }
 
+// spaceShortages returns the entries of pending whose remaining size exceeds
+// the free space the daemon reports for their path. Paths whose free space is
+// unknown, e.g. ones the daemon cannot see, are left out.
+func (s *TorrentService) spaceShortages(ctx context.Context, pending map[string]*SpaceShortage, sessionInfo *types.SessionInfo) []SpaceShortage {
+	var shortages []SpaceShortage
+	for path, shortage := range pending {
+		free, err := s.client.GetFreeSpace(ctx, path)
+		if err != nil {
+			if path != sessionInfo.DownloadDir || sessionInfo.DownloadDirFree <= 0 {
+				continue
+			}
+			free = sessionInfo.DownloadDirFree
+		}
+		if shortage.Remaining > free {
+			shortage.FreeSpace = free
+			shortages = append(shortages, *shortage)
+		}
+	}
+	sort.Slice(shortages, func(i, j int) bool { return shortages[i].Path < shortages[j].Path })
+	return shortages
+}
+
 // CompareResult represents the result of comparing local vs Transmission
 type CompareResult struct {
-	InTransmissionOnly []string
-	LocalOnly          []string
-	InBoth             []string
-	TotalLocal         int
-	TotalTransmission  int
+	InTransmissionOnly []string `json:"in_transmission_only"`
+	LocalOnly          []string `json:"local_only"`
+	InBoth             []string `json:"in_both"`
+	TotalLocal         int      `json:"total_local"`
+	TotalTransmission  int      `json:"total_transmission"`
 }
 
-// CompareLocalWithTransmission compares local files with Transmission torrents
+// CompareLocalWithTransmission compares local files with Transmission torrents,
+// translating torrent paths with the path mappings
 func (s *TorrentService) CompareLocalWithTransmission(ctx context.Context, dir string) (*CompareResult, error) {
 	torrentPaths, err := s.client.GetAllTorrentPaths(ctx)
 	if err != nil {
@@ -272,7 +750,7 @@ func (s *TorrentService) CompareLocalWithTransmission(ctx context.Context, dir s
 
 	torrentMap := make(map[string]bool)
 	for _, path := range torrentPaths {
-		torrentMap[path] = true
+		torrentMap[s.pathMappings.ToLocal(path)] = true
 	}
 
 	entries, err := os.ReadDir(dir)
@@ -280,6 +758,9 @@ func (s *TorrentService) CompareLocalWithTransmission(ctx context.Context, dir s
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
+	// Items peerless staged or trashed on this filesystem are not local items
+	entries = slices.DeleteFunc(entries, func(entry os.DirEntry) bool { return utils.IsDeletedItemsDir(entry.Name()) })
+
 	result := &CompareResult{
 		InTransmissionOnly: make([]string, 0),
 		LocalOnly:          make([]string, 0),
@@ -306,6 +787,7 @@ func (s *TorrentService) CompareLocalWithTransmission(ctx context.Context, dir s
 	for path := range torrentMap {
 		result.InTransmissionOnly = append(result.InTransmissionOnly, path)
 	}
+	sort.Strings(result.InTransmissionOnly)
 
 	return result, nil
 }
@@ -315,7 +797,491 @@ func (s *TorrentService) GetDownloadDirectories(ctx context.Context) ([]utils.Di
 	return s.client.GetDownloadDirectories(ctx)
 }
 
+// GroupDirectoriesByMount groups download directories by the local filesystem
+// they are stored on, after applying the path mappings
+func (s *TorrentService) GroupDirectoriesByMount(dirs []utils.DirectoryInfo) []utils.MountGroup {
+	return utils.GroupByMount(dirs, s.pathMappings.ToLocal)
+}
+
+// GetLocalDownloadDirectories returns Transmission download directories, translated by the
+// service path mappings, that exist locally as directories
+func (s *TorrentService) GetLocalDownloadDirectories(ctx context.Context) ([]string, error) {
+	dirs, err := s.client.GetDownloadDirectories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve download directories: %w", err)
+	}
+
+	local := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		path := s.pathMappings.ToLocal(d.Path)
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		local = append(local, path)
+	}
+
+	return local, nil
+}
+
 // GetAllTorrentPaths returns all torrent paths
 func (s *TorrentService) GetAllTorrentPaths(ctx context.Context) ([]string, error) {
 	return s.client.GetAllTorrentPaths(ctx)
 }
+
+// TorrentFilter reports whether a torrent should be included in a listing
+type TorrentFilter func(t types.TorrentInfo) bool
+
+// CompletedFilter matches torrents that have finished downloading
+func CompletedFilter(t types.TorrentInfo) bool {
+	return t.IsComplete()
+}
+
+// DirectoryFilter matches torrents whose download directory is one of dirs
+func DirectoryFilter(dirs ...string) TorrentFilter {
+	wanted := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		wanted[filepath.Clean(dir)] = true
+	}
+	return func(t types.TorrentInfo) bool {
+		return wanted[filepath.Clean(t.DownloadDir)]
+	}
+}
+
+// LabelFilter matches torrents carrying at least one of labels
+func LabelFilter(labels ...string) TorrentFilter {
+	wanted := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		wanted[label] = true
+	}
+	return func(t types.TorrentInfo) bool {
+		for _, label := range t.Labels {
+			if wanted[label] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AddedBeforeFilter matches torrents added before cutoff
+func AddedBeforeFilter(cutoff time.Time) TorrentFilter {
+	return func(t types.TorrentInfo) bool {
+		return t.AddedDate > 0 && time.Unix(t.AddedDate, 0).Before(cutoff)
+	}
+}
+
+// PrivateFilter matches private torrents when private is set and public
+// torrents otherwise
+func PrivateFilter(private bool) TorrentFilter {
+	return func(t types.TorrentInfo) bool {
+		return t.IsPrivate == private
+	}
+}
+
+// MinSizeFilter matches torrents whose total size is at least minSize bytes
+func MinSizeFilter(minSize int64) TorrentFilter {
+	return func(t types.TorrentInfo) bool {
+		return t.TotalSize >= minSize
+	}
+}
+
+// IDFilter matches torrents whose ID is one of ids
+func IDFilter(ids ...int) TorrentFilter {
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return func(t types.TorrentInfo) bool {
+		return wanted[t.ID]
+	}
+}
+
+// NameFilter matches torrents whose name contains one of patterns, ignoring case
+func NameFilter(patterns ...string) TorrentFilter {
+	lowered := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lowered[i] = strings.ToLower(pattern)
+	}
+	return func(t types.TorrentInfo) bool {
+		name := strings.ToLower(t.Name)
+		for _, pattern := range lowered {
+			if strings.Contains(name, pattern) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GetTorrents returns torrents matching all filters
+func (s *TorrentService) GetTorrents(ctx context.Context, filters ...TorrentFilter) ([]types.TorrentInfo, error) {
+	torrents, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	matched := make([]types.TorrentInfo, 0, len(torrents))
+	for _, t := range torrents {
+		if matchesFilters(t, filters) {
+			matched = append(matched, t)
+		}
+	}
+
+	return matched, nil
+}
+
+// StartTorrents starts all torrents matching the filters and returns how many were started
+func (s *TorrentService) StartTorrents(ctx context.Context, filters ...TorrentFilter) (int, error) {
+	ids, err := s.matchingIDs(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.client.StartTorrents(ctx, ids); err != nil {
+		return 0, fmt.Errorf("failed to start torrents: %w", err)
+	}
+	return len(ids), nil
+}
+
+// StopTorrents stops all torrents matching the filters and returns how many were stopped
+func (s *TorrentService) StopTorrents(ctx context.Context, filters ...TorrentFilter) (int, error) {
+	ids, err := s.matchingIDs(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.client.StopTorrents(ctx, ids); err != nil {
+		return 0, fmt.Errorf("failed to stop torrents: %w", err)
+	}
+	return len(ids), nil
+}
+
+// VerifyTorrents starts verification of all torrents matching the filters and returns how many were queued
+func (s *TorrentService) VerifyTorrents(ctx context.Context, filters ...TorrentFilter) (int, error) {
+	ids, err := s.matchingIDs(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.client.VerifyTorrents(ctx, ids); err != nil {
+		return 0, fmt.Errorf("failed to verify torrents: %w", err)
+	}
+	return len(ids), nil
+}
+
+// matchingIDs returns the IDs of torrents matching all filters
+func (s *TorrentService) matchingIDs(ctx context.Context, filters []TorrentFilter) ([]int, error) {
+	torrents, err := s.GetTorrents(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(torrents))
+	for _, t := range torrents {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// GetTorrentPaths returns sorted paths of torrents matching all filters
+func (s *TorrentService) GetTorrentPaths(ctx context.Context, filters ...TorrentFilter) ([]string, error) {
+	if len(filters) == 0 {
+		return s.client.GetAllTorrentPaths(ctx)
+	}
+
+	torrents, err := s.GetTorrents(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(torrents))
+	for _, t := range torrents {
+		paths = append(paths, utils.SanitizeString(filepath.Join(t.DownloadDir, t.Name)))
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// matchesFilters reports whether a torrent satisfies every filter
+func matchesFilters(t types.TorrentInfo, filters []TorrentFilter) bool {
+	for _, filter := range filters {
+		if !filter(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sort keys accepted by GetTopTorrents
+const (
+	TopBySize     = "size"
+	TopByRatio    = "ratio"
+	TopByUploaded = "uploaded"
+)
+
+// GetTopTorrents returns up to limit torrents ordered descending by the given key
+func (s *TorrentService) GetTopTorrents(ctx context.Context, by string, limit int) ([]types.TorrentInfo, error) {
+	var less func(a, b types.TorrentInfo) bool
+	switch by {
+	case TopBySize:
+		less = func(a, b types.TorrentInfo) bool { return a.TotalSize > b.TotalSize }
+	case TopByRatio:
+		less = func(a, b types.TorrentInfo) bool { return a.Ratio > b.Ratio }
+	case TopByUploaded:
+		less = func(a, b types.TorrentInfo) bool { return a.UploadedEver > b.UploadedEver }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q: must be one of %s, %s, %s", by, TopBySize, TopByRatio, TopByUploaded)
+	}
+
+	torrents, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	sort.SliceStable(torrents, func(i, j int) bool {
+		return less(torrents[i], torrents[j])
+	})
+
+	if limit > 0 && len(torrents) > limit {
+		torrents = torrents[:limit]
+	}
+
+	return torrents, nil
+}
+
+// LabelStatistics contains aggregated statistics for a Transmission label
+type LabelStatistics struct {
+	Label          string
+	TorrentCount   int
+	TotalSize      int64
+	UploadedEver   int64
+	DownloadedEver int64
+	Ratio          float64
+	DownloadSpeed  int
+	UploadSpeed    int
+}
+
+// GetLabelStatistics aggregates torrent statistics grouped by label, sorted by label name.
+// Torrents with several labels count towards each of them; unlabeled torrents are
+// grouped under constants.UnlabeledName.
+func (s *TorrentService) GetLabelStatistics(ctx context.Context) ([]LabelStatistics, error) {
+	torrents, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	byLabel := make(map[string]*LabelStatistics)
+	for _, t := range torrents {
+		labels := t.Labels
+		if len(labels) == 0 {
+			labels = []string{constants.UnlabeledName}
+		}
+
+		for _, label := range labels {
+			stats, exists := byLabel[label]
+			if !exists {
+				stats = &LabelStatistics{Label: label}
+				byLabel[label] = stats
+			}
+
+			stats.TorrentCount++
+			stats.TotalSize += t.TotalSize
+			stats.UploadedEver += t.UploadedEver
+			stats.DownloadedEver += t.DownloadedEver
+			stats.DownloadSpeed += t.RateDownload
+			stats.UploadSpeed += t.RateUpload
+		}
+	}
+
+	result := make([]LabelStatistics, 0, len(byLabel))
+	for _, stats := range byLabel {
+		if stats.DownloadedEver > 0 {
+			stats.Ratio = float64(stats.UploadedEver) / float64(stats.DownloadedEver)
+		}
+		result = append(result, *stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Label < result[j].Label
+	})
+
+	return result, nil
+}
+
+// Timeline periods accepted by GetAddedTimeline
+const (
+	TimelineByWeek  = "week"
+	TimelineByMonth = "month"
+)
+
+// TimelineBucket contains the torrents added during a single period
+type TimelineBucket struct {
+	Label     string
+	Start     time.Time
+	Count     int
+	TotalSize int64
+}
+
+// GetAddedTimeline groups torrents by the week or month they were added.
+// Buckets are returned in chronological order, including empty periods between
+// the first and last addition so gaps remain visible.
+func (s *TorrentService) GetAddedTimeline(ctx context.Context, period string) ([]TimelineBucket, error) {
+	if period != TimelineByWeek && period != TimelineByMonth {
+		return nil, fmt.Errorf("unknown timeline period %q: must be %s or %s", period, TimelineByWeek, TimelineByMonth)
+	}
+
+	torrents, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	buckets := make(map[time.Time]*TimelineBucket)
+	var first, last time.Time
+	for _, t := range torrents {
+		if t.AddedDate <= 0 {
+			continue
+		}
+
+		start := periodStart(time.Unix(t.AddedDate, 0), period)
+		bucket, exists := buckets[start]
+		if !exists {
+			bucket = &TimelineBucket{Label: periodLabel(start, period), Start: start}
+			buckets[start] = bucket
+		}
+		bucket.Count++
+		bucket.TotalSize += t.TotalSize
+
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	if len(buckets) == 0 {
+		return []TimelineBucket{}, nil
+	}
+
+	result := make([]TimelineBucket, 0)
+	for start := first; !start.After(last); start = nextPeriod(start, period) {
+		if bucket, exists := buckets[start]; exists {
+			result = append(result, *bucket)
+		} else {
+			result = append(result, TimelineBucket{Label: periodLabel(start, period), Start: start})
+		}
+	}
+
+	return result, nil
+}
+
+// periodStart truncates a time to the beginning of its week (Monday) or month
+func periodStart(t time.Time, period string) time.Time {
+	year, month, day := t.Date()
+	if period == TimelineByMonth {
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod returns the start of the period following start
+func nextPeriod(start time.Time, period string) time.Time {
+	if period == TimelineByMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// periodLabel formats a period start as YYYY-MM or ISO YYYY-Www
+func periodLabel(start time.Time, period string) string {
+	if period == TimelineByMonth {
+		return start.Format("2006-01")
+	}
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// StuckTorrent describes a torrent whose verification does not appear to progress
+type StuckTorrent struct {
+	Torrent types.TorrentInfo
+	Reason  string
+}
+
+// FindStuckVerifying samples torrents twice, interval apart, and reports torrents in
+// verifying or queued-to-verify state whose verification made no progress in between.
+// Queued torrents are only reported when no verification anywhere advanced, since
+// Transmission verifies one torrent at a time.
+func (s *TorrentService) FindStuckVerifying(ctx context.Context, interval time.Duration) ([]StuckTorrent, error) {
+	before, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(interval):
+	}
+
+	after, err := s.client.GetTorrents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+	}
+
+	previous := make(map[int]types.TorrentInfo, len(before))
+	for _, t := range before {
+		previous[t.ID] = t
+	}
+
+	queueMoving := false
+	for _, t := range after {
+		prev, exists := previous[t.ID]
+		if !exists {
+			continue
+		}
+		if t.Status != prev.Status || t.RecheckProgress > prev.RecheckProgress {
+			if t.Status.IsVerifying() || prev.Status.IsVerifying() {
+				queueMoving = true
+			}
+		}
+	}
+
+	stuck := make([]StuckTorrent, 0)
+	for _, t := range after {
+		prev, exists := previous[t.ID]
+		if !exists || !t.Status.IsVerifying() || !prev.Status.IsVerifying() {
+			continue
+		}
+
+		switch t.Status {
+		case types.StatusVerifying:
+			if prev.Status == types.StatusVerifying && t.RecheckProgress <= prev.RecheckProgress {
+				stuck = append(stuck, StuckTorrent{
+					Torrent: t,
+					Reason:  fmt.Sprintf("verification stalled at %.1f%%", t.RecheckProgress*100),
+				})
+			}
+		case types.StatusQueuedVerify:
+			if !queueMoving {
+				stuck = append(stuck, StuckTorrent{
+					Torrent: t,
+					Reason:  "queued to verify but the verify queue is not moving",
+				})
+			}
+		}
+	}
+
+	return stuck, nil
+}
+
+// ReverifyTorrents restarts verification for the given torrent IDs
+func (s *TorrentService) ReverifyTorrents(ctx context.Context, ids []int) error {
+	if err := s.client.VerifyTorrents(ctx, ids); err != nil {
+		return fmt.Errorf("failed to restart verification: %w", err)
+	}
+	return nil
+}
@@ -2,14 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"peerless/pkg/client"
+	"peerless/pkg/constants"
 	"peerless/pkg/types"
 )
 
@@ -52,6 +59,8 @@ func TestTorrentService_CheckDirectories(t *testing.T) {
 		require.NoError(t, err)
 		err = os.WriteFile(file3, []byte("local content"), 0644)
 		require.NoError(t, err)
+		// Staged deletions are never reported missing
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, constants.StagingDirName), 0700))
 
 		// Mock torrent data
 		mockResponse := `{
@@ -171,6 +180,133 @@ func TestTorrentService_CheckDirectories(t *testing.T) {
 		assert.Equal(t, 1, result.TotalFound)
 		assert.Len(t, result.MissingPaths, 1)
 	})
+
+	t.Run("unreadable directory", func(t *testing.T) {
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{
+						"X-Transmission-Session-Id": "test-session",
+					}), nil
+				}
+				return NewMockResponse(200, `{"arguments": {"torrents": []}, "result": "success"}`, nil), nil
+			},
+		}
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+		readable := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(readable, "orphan.mkv"), nil, 0644))
+		missing := filepath.Join(t.TempDir(), "missing")
+		result, err := service.CheckDirectories(context.Background(), []string{missing, readable})
+		require.NoError(t, err)
+
+		require.Len(t, result.Directories, 2)
+		failed := result.Failed()
+		require.Len(t, failed, 1)
+		assert.Equal(t, missing, failed[0].Path)
+		assert.ErrorIs(t, failed[0].Err, os.ErrNotExist)
+		assert.Equal(t, 1, result.TotalItems)
+		assert.Len(t, result.MissingPaths, 1)
+
+		report := result.Report()
+		require.Len(t, report.FailedDirectories, 1)
+		assert.Equal(t, missing, report.FailedDirectories[0].Path)
+		assert.Contains(t, report.FailedDirectories[0].Error, "failed to read directory")
+	})
+
+	t.Run("torrent fetch failure wins over directory errors", func(t *testing.T) {
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return NewMockResponse(500, "", nil), nil
+			},
+		}
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+		_, err := service.CheckDirectories(context.Background(), []string{filepath.Join(t.TempDir(), "missing")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to retrieve torrents")
+	})
+
+	t.Run("large directory is read in batches", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		count := constants.DirScanBatchSize*2 + 1
+		for i := 0; i < count; i++ {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("item-%05d", i)), nil, 0644))
+		}
+
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{
+						"X-Transmission-Session-Id": "test-session",
+					}), nil
+				}
+				return NewMockResponse(200, `{"arguments": {"torrents": [{"id": 1, "name": "item-00000"}]}, "result": "success"}`, nil), nil
+			},
+		}
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+		var unknownTotals, lastCurrent, lastTotal int
+		opts := CheckOptions{Progress: func(dir string, current, total int, size int64) {
+			if total == 0 {
+				unknownTotals++
+			}
+			lastCurrent, lastTotal = current, total
+		}}
+
+		result, err := service.CheckDirectoriesWithOptions(context.Background(), []string{tmpDir}, opts)
+		require.NoError(t, err)
+
+		dirResult := result.Directories[0]
+		assert.Equal(t, count, dirResult.TotalItems)
+		assert.Equal(t, 1, dirResult.FoundItems)
+		assert.Len(t, dirResult.MissingPaths, count-1)
+		assert.True(t, sort.StringsAreSorted(dirResult.MissingPaths))
+		assert.Equal(t, constants.DirScanBatchSize*2, unknownTotals)
+		assert.Equal(t, count, lastCurrent)
+		assert.Equal(t, count, lastTotal)
+	})
+
+	t.Run("reports scan progress", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Movie1"), []byte("movie"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Local"), []byte("local content"), 0644))
+
+		mockResponse := `{"arguments": {"torrents": [{"id": 1, "name": "Movie1"}]}, "result": "success"}`
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{
+						"X-Transmission-Session-Id": "test-session",
+					}), nil
+				}
+				return NewMockResponse(200, mockResponse, nil), nil
+			},
+		}
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+		var currents []int
+		var bytes int64
+		opts := CheckOptions{Progress: func(dir string, current, total int, size int64) {
+			assert.Equal(t, tmpDir, dir)
+			assert.Equal(t, 2, total)
+			currents = append(currents, current)
+			bytes += size
+		}}
+
+		_, err := service.CheckDirectoriesWithOptions(context.Background(), []string{tmpDir}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, currents)
+		assert.Equal(t, int64(len("local content")), bytes)
+	})
 }
 
 func TestTorrentService_GetTorrentStatistics(t *testing.T) {
@@ -298,6 +434,42 @@ func TestTorrentService_CompareLocalWithTransmission(t *testing.T) {
 		absLocalFile, _ := filepath.Abs(localFile)
 		assert.Contains(t, result.LocalOnly, absLocalFile)
 	})
+
+	t.Run("applies path mappings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Movie.mkv"), []byte("x"), 0644))
+
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": `{"arguments": {"torrents": [
+				{"id": 1, "name": "Movie.mkv", "downloadDir": "/downloads"}
+			]}, "result": "success"}`,
+		})
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		mappings := types.PathMappings{{Remote: "/downloads", Local: tmpDir}}
+		service := NewTorrentServiceWithPathMappings(client.NewTransmissionClientWithHTTPClient(config, mockHTTP), mappings)
+
+		result, err := service.CompareLocalWithTransmission(context.Background(), tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "Movie.mkv")}, result.InBoth)
+		assert.Empty(t, result.InTransmissionOnly)
+	})
+
+	t.Run("skips the staging directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, constants.StagingDirName), 0700))
+
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": `{"arguments": {"torrents": []}, "result": "success"}`,
+		})
+		config := types.Config{Host: "localhost", Port: 9091}
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+		result, err := service.CompareLocalWithTransmission(context.Background(), tmpDir)
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalLocal)
+		assert.Empty(t, result.LocalOnly)
+	})
 }
 
 func TestTorrentService_GetDownloadDirectories(t *testing.T) {
@@ -403,3 +575,478 @@ func TestTorrentService_GetAllTorrentPaths(t *testing.T) {
 		assert.Contains(t, paths, "/downloads/tv/Movie2.2024")
 	})
 }
+
+func TestTorrentService_GetDetailedStatus(t *testing.T) {
+	t.Run("counts every torrent state", func(t *testing.T) {
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": `{
+				"arguments": {
+					"torrents": [
+						{"id": 1, "name": "Stopped", "downloadDir": "/downloads", "status": 0, "percentDone": 0.5},
+						{"id": 2, "name": "Completed", "downloadDir": "/downloads", "status": 0, "percentDone": 1.0},
+						{"id": 3, "name": "QueuedVerify", "downloadDir": "/downloads", "status": 1},
+						{"id": 4, "name": "Verifying", "downloadDir": "/downloads", "status": 2},
+						{"id": 5, "name": "QueuedDownload", "downloadDir": "/downloads", "status": 3},
+						{"id": 6, "name": "Downloading", "downloadDir": "/downloads", "status": 4},
+						{"id": 7, "name": "QueuedSeed", "downloadDir": "/downloads", "status": 5},
+						{"id": 8, "name": "Seeding", "downloadDir": "/downloads", "status": 6, "percentDone": 1.0, "isPrivate": true, "totalSize": 300}
+					]
+				},
+				"result": "success"
+			}`,
+			"session-get": `{"arguments": {"download-dir": "/downloads", "download-dir-free": 1024}, "result": "success"}`,
+		})
+
+		config := types.Config{Host: "localhost", Port: 9091}
+		transmissionClient := client.NewTransmissionClientWithHTTPClient(config, mockHTTP)
+		service := NewTorrentService(transmissionClient)
+
+		status, err := service.GetDetailedStatus(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 8, status.TotalTorrents)
+		assert.Equal(t, 1, status.PausedTorrents)
+		assert.Equal(t, 1, status.CompletedTorrents)
+		assert.Equal(t, 2, status.VerifyingTorrents)
+		assert.Equal(t, 2, status.QueuedTorrents)
+		assert.Equal(t, 1, status.DownloadingTorrents)
+		assert.Equal(t, 1, status.SeedingTorrents)
+
+		assert.Equal(t, 1, status.CompletedSeedingTorrents)
+
+		sum := status.PausedTorrents + status.CompletedTorrents + status.VerifyingTorrents +
+			status.QueuedTorrents + status.DownloadingTorrents + status.SeedingTorrents
+		assert.Equal(t, status.TotalTorrents, sum)
+		assert.Equal(t, TorrentGroup{Count: 1, TotalSize: 300}, status.Private)
+		assert.Equal(t, TorrentGroup{Count: 7}, status.Public)
+		assert.Empty(t, status.SpaceShortages)
+	})
+
+	downloading := `{
+		"arguments": {
+			"torrents": [
+				{"id": 1, "name": "Film", "downloadDir": "/downloads/movies", "status": 4, "leftUntilDone": 600},
+				{"id": 2, "name": "Film2", "downloadDir": "/downloads/movies", "status": 3, "leftUntilDone": 200},
+				{"id": 3, "name": "Paused", "downloadDir": "/downloads/movies", "status": 0, "leftUntilDone": 5000},
+				{"id": 4, "name": "Show", "downloadDir": "/downloads/tv", "status": 4, "leftUntilDone": 100}
+			]
+		},
+		"result": "success"
+	}`
+	freeSpace := `{"arguments": {"path": "/downloads", "size-bytes": 500}, "result": "success"}`
+
+	t.Run("warns when downloads need more space than is free", func(t *testing.T) {
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": downloading,
+			"session-get": `{"arguments": {"download-dir": "/downloads"}, "result": "success"}`,
+			"free-space":  freeSpace,
+		})
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP))
+
+		status, err := service.GetDetailedStatus(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(800), status.DirectoryBreakdown["/downloads/movies"].RemainingSize)
+		assert.Equal(t, []SpaceShortage{{Path: "/downloads/movies", Remaining: 800, FreeSpace: 500, Torrents: 2}}, status.SpaceShortages)
+		assert.Equal(t, int64(300), status.SpaceShortages[0].Missing())
+	})
+
+	t.Run("downloads fill the incomplete-dir", func(t *testing.T) {
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": downloading,
+			"session-get": `{"arguments": {"download-dir": "/downloads", "incomplete-dir": "/incomplete", "incomplete-dir-enabled": true}, "result": "success"}`,
+			"free-space":  freeSpace,
+		})
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP))
+
+		status, err := service.GetDetailedStatus(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []SpaceShortage{{Path: "/incomplete", Remaining: 900, FreeSpace: 500, Torrents: 3}}, status.SpaceShortages)
+	})
+
+	t.Run("unknown free space is not a shortage", func(t *testing.T) {
+		mockHTTP := newMethodMockClient(map[string]string{
+			"torrent-get": downloading,
+			"session-get": `{"arguments": {"download-dir": "/downloads"}, "result": "success"}`,
+			"free-space":  `{"arguments": {}, "result": "method name not recognized"}`,
+		})
+		service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, mockHTTP))
+
+		status, err := service.GetDetailedStatus(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, status.SpaceShortages)
+	})
+}
+
+func TestAgeBuckets(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) int64 {
+		return now.Add(-time.Duration(days) * 24 * time.Hour).Unix()
+	}
+
+	var buckets AgeBuckets
+	for _, added := range []int64{daysAgo(0), daysAgo(6), daysAgo(7), daysAgo(29), daysAgo(30), daysAgo(179), daysAgo(180), daysAgo(1000), 0} {
+		buckets.add(added, now)
+	}
+
+	assert.Equal(t, AgeBuckets{LastWeek: 2, LastMonth: 2, LastHalfYear: 2, Older: 2, Unknown: 1}, buckets)
+}
+
+func TestTorrentService_GetTorrentPaths(t *testing.T) {
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{
+			"arguments": {
+				"torrents": [
+					{"id": 1, "name": "Done", "downloadDir": "/downloads", "status": 6, "percentDone": 1.0},
+					{"id": 2, "name": "Partial", "downloadDir": "/downloads", "status": 4, "percentDone": 0.3},
+					{"id": 3, "name": "Archived", "downloadDir": "/archive", "status": 0, "percentDone": 1.0}
+				]
+			},
+			"result": "success"
+		}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("no filters returns all paths", func(t *testing.T) {
+		paths, err := service.GetTorrentPaths(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, paths, 3)
+	})
+
+	t.Run("completed filter", func(t *testing.T) {
+		paths, err := service.GetTorrentPaths(context.Background(), CompletedFilter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/archive/Archived", "/downloads/Done"}, paths)
+	})
+}
+
+func TestAgeAndSizeFilters(t *testing.T) {
+	now := time.Now()
+	old := types.TorrentInfo{AddedDate: now.Add(-90 * 24 * time.Hour).Unix(), TotalSize: 2048}
+	recent := types.TorrentInfo{AddedDate: now.Add(-time.Hour).Unix(), TotalSize: 512}
+	unknown := types.TorrentInfo{}
+
+	addedBefore := AddedBeforeFilter(now.Add(-60 * 24 * time.Hour))
+	assert.True(t, addedBefore(old))
+	assert.False(t, addedBefore(recent))
+	assert.False(t, addedBefore(unknown))
+
+	minSize := MinSizeFilter(1024)
+	assert.True(t, minSize(old))
+	assert.False(t, minSize(recent))
+}
+
+func TestPrivateFilter(t *testing.T) {
+	private := types.TorrentInfo{IsPrivate: true}
+	public := types.TorrentInfo{}
+
+	assert.True(t, PrivateFilter(true)(private))
+	assert.False(t, PrivateFilter(true)(public))
+	assert.True(t, PrivateFilter(false)(public))
+	assert.False(t, PrivateFilter(false)(private))
+}
+
+func TestNameFilter(t *testing.T) {
+	filter := NameFilter("ubuntu", "Arch")
+	for name, want := range map[string]bool{"Ubuntu 24.04": true, "archlinux.iso": true, "Debian": false} {
+		assert.Equal(t, want, filter(types.TorrentInfo{Name: name}), name)
+	}
+}
+
+func TestIDFilter(t *testing.T) {
+	filter := IDFilter(2, 5)
+	assert.True(t, filter(types.TorrentInfo{ID: 5}))
+	assert.False(t, filter(types.TorrentInfo{ID: 3}))
+	assert.False(t, IDFilter()(types.TorrentInfo{ID: 1}))
+}
+
+func TestTorrentService_GetTopTorrents(t *testing.T) {
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{
+			"arguments": {
+				"torrents": [
+					{"id": 1, "name": "Small", "totalSize": 100, "uploadRatio": 3.0, "uploadedEver": 300},
+					{"id": 2, "name": "Large", "totalSize": 1000, "uploadRatio": 0.5, "uploadedEver": 500},
+					{"id": 3, "name": "Medium", "totalSize": 500, "uploadRatio": 1.0, "uploadedEver": 800}
+				]
+			},
+			"result": "success"
+		}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	names := func(torrents []types.TorrentInfo) []string {
+		result := make([]string, 0, len(torrents))
+		for _, t := range torrents {
+			result = append(result, t.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		by       string
+		limit    int
+		expected []string
+	}{
+		{TopBySize, 0, []string{"Large", "Medium", "Small"}},
+		{TopByRatio, 2, []string{"Small", "Medium"}},
+		{TopByUploaded, 1, []string{"Medium"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			torrents, err := service.GetTopTorrents(context.Background(), tt.by, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names(torrents))
+		})
+	}
+
+	t.Run("unknown sort key", func(t *testing.T) {
+		_, err := service.GetTopTorrents(context.Background(), "speed", 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestTorrentService_GetLabelStatistics(t *testing.T) {
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{
+			"arguments": {
+				"torrents": [
+					{"id": 1, "name": "A", "totalSize": 100, "uploadedEver": 200, "downloadedEver": 100, "rateUpload": 10, "labels": ["movies"]},
+					{"id": 2, "name": "B", "totalSize": 300, "uploadedEver": 100, "downloadedEver": 300, "rateDownload": 5, "labels": ["movies", "hd"]},
+					{"id": 3, "name": "C", "totalSize": 50}
+				]
+			},
+			"result": "success"
+		}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	stats, err := service.GetLabelStatistics(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+
+	assert.Equal(t, constants.UnlabeledName, stats[0].Label)
+	assert.Equal(t, 1, stats[0].TorrentCount)
+
+	assert.Equal(t, "hd", stats[1].Label)
+	assert.Equal(t, int64(300), stats[1].TotalSize)
+
+	movies := stats[2]
+	assert.Equal(t, "movies", movies.Label)
+	assert.Equal(t, 2, movies.TorrentCount)
+	assert.Equal(t, int64(400), movies.TotalSize)
+	assert.InDelta(t, 0.75, movies.Ratio, 0.001)
+	assert.Equal(t, 5, movies.DownloadSpeed)
+	assert.Equal(t, 10, movies.UploadSpeed)
+}
+
+func TestTorrentService_GetAddedTimeline(t *testing.T) {
+	jan := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.Local).Unix()
+	jan2 := time.Date(2024, time.January, 20, 12, 0, 0, 0, time.Local).Unix()
+	mar := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.Local).Unix()
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": fmt.Sprintf(`{
+			"arguments": {
+				"torrents": [
+					{"id": 1, "name": "A", "totalSize": 100, "addedDate": %d},
+					{"id": 2, "name": "B", "totalSize": 200, "addedDate": %d},
+					{"id": 3, "name": "C", "totalSize": 300, "addedDate": %d},
+					{"id": 4, "name": "NoDate", "totalSize": 400}
+				]
+			},
+			"result": "success"
+		}`, jan, jan2, mar),
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("by month includes empty months", func(t *testing.T) {
+		buckets, err := service.GetAddedTimeline(context.Background(), TimelineByMonth)
+		require.NoError(t, err)
+		require.Len(t, buckets, 3)
+
+		assert.Equal(t, "2024-01", buckets[0].Label)
+		assert.Equal(t, 2, buckets[0].Count)
+		assert.Equal(t, int64(300), buckets[0].TotalSize)
+		assert.Equal(t, "2024-02", buckets[1].Label)
+		assert.Equal(t, 0, buckets[1].Count)
+		assert.Equal(t, "2024-03", buckets[2].Label)
+		assert.Equal(t, 1, buckets[2].Count)
+	})
+
+	t.Run("by week", func(t *testing.T) {
+		buckets, err := service.GetAddedTimeline(context.Background(), TimelineByWeek)
+		require.NoError(t, err)
+		require.NotEmpty(t, buckets)
+
+		assert.Equal(t, "2024-W03", buckets[0].Label)
+		assert.Equal(t, 2, buckets[0].Count)
+		assert.Equal(t, "2024-W10", buckets[len(buckets)-1].Label)
+		assert.Len(t, buckets, 8)
+	})
+
+	t.Run("unknown period", func(t *testing.T) {
+		_, err := service.GetAddedTimeline(context.Background(), "year")
+		assert.Error(t, err)
+	})
+}
+
+func TestTorrentService_FindStuckVerifying(t *testing.T) {
+	newService := func(samples ...string) *TorrentService {
+		call := 0
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Transmission-Session-Id") == "" {
+					return NewMockResponse(409, "{}", map[string]string{
+						"X-Transmission-Session-Id": "test-session",
+					}), nil
+				}
+				// Listing the IDs precedes each sample and does not advance it
+				body := samples[call]
+				reqBody, _ := io.ReadAll(req.Body)
+				if strings.Contains(string(reqBody), `"ids"`) && call < len(samples)-1 {
+					call++
+				}
+				return NewMockResponse(200, body, nil), nil
+			},
+		}
+		config := types.Config{Host: "localhost", Port: 9091}
+		return NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+	}
+
+	t.Run("stalled verification and frozen queue", func(t *testing.T) {
+		sample := `{"arguments": {"torrents": [
+			{"id": 1, "name": "Verifying", "status": 2, "recheckProgress": 0.4},
+			{"id": 2, "name": "Queued", "status": 1},
+			{"id": 3, "name": "Seeding", "status": 6}
+		]}, "result": "success"}`
+
+		stuck, err := newService(sample, sample).FindStuckVerifying(context.Background(), 0)
+		require.NoError(t, err)
+		require.Len(t, stuck, 2)
+		assert.Equal(t, "Verifying", stuck[0].Torrent.Name)
+		assert.Contains(t, stuck[0].Reason, "40.0%")
+		assert.Equal(t, "Queued", stuck[1].Torrent.Name)
+	})
+
+	t.Run("progressing verification is not stuck", func(t *testing.T) {
+		before := `{"arguments": {"torrents": [
+			{"id": 1, "name": "Verifying", "status": 2, "recheckProgress": 0.4},
+			{"id": 2, "name": "Queued", "status": 1}
+		]}, "result": "success"}`
+		after := `{"arguments": {"torrents": [
+			{"id": 1, "name": "Verifying", "status": 2, "recheckProgress": 0.6},
+			{"id": 2, "name": "Queued", "status": 1}
+		]}, "result": "success"}`
+
+		stuck, err := newService(before, after).FindStuckVerifying(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, stuck)
+	})
+}
+
+func TestTorrentService_StartStopTorrents(t *testing.T) {
+	var methods []string
+	var ids []interface{}
+
+	mockHTTP := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{
+					"X-Transmission-Session-Id": "test-session",
+				}), nil
+			}
+
+			var rpcReq struct {
+				Method    string                 `json:"method"`
+				Arguments map[string]interface{} `json:"arguments"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &rpcReq))
+			methods = append(methods, rpcReq.Method)
+
+			if rpcReq.Method == "torrent-get" {
+				return NewMockResponse(200, `{"arguments": {"torrents": [
+					{"id": 1, "name": "A", "downloadDir": "/downloads/movies", "labels": ["hd"]},
+					{"id": 2, "name": "B", "downloadDir": "/downloads/movies/", "labels": ["sd"]},
+					{"id": 3, "name": "C", "downloadDir": "/downloads/tv", "labels": ["hd"]}
+				]}, "result": "success"}`, nil), nil
+			}
+
+			ids = rpcReq.Arguments["ids"].([]interface{})
+			return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+		},
+	}
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	t.Run("start filtered by directory and label", func(t *testing.T) {
+		count, err := service.StartTorrents(context.Background(),
+			DirectoryFilter("/downloads/movies"), LabelFilter("hd"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "torrent-start", methods[len(methods)-1])
+		assert.Equal(t, []interface{}{float64(1)}, ids)
+	})
+
+	t.Run("stop all", func(t *testing.T) {
+		count, err := service.StopTorrents(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "torrent-stop", methods[len(methods)-1])
+	})
+
+	t.Run("verify by id", func(t *testing.T) {
+		count, err := service.VerifyTorrents(context.Background(), IDFilter(2, 3))
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, "torrent-verify", methods[len(methods)-1])
+		assert.Equal(t, []interface{}{float64(2), float64(3)}, ids)
+	})
+}
+
+func TestTorrentService_GetLocalDownloadDirectories(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_auto_dirs_")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	filePath := filepath.Join(tmpDir, "not-a-dir")
+	require.NoError(t, os.WriteFile(filePath, []byte("x"), 0644))
+
+	mockHTTP := newMethodMockClient(map[string]string{
+		"torrent-get": `{"arguments": {"torrents": [
+			{"id": 1, "name": "A", "downloadDir": "` + tmpDir + `"},
+			{"id": 2, "name": "B", "downloadDir": "/nonexistent/peerless/dir"},
+			{"id": 3, "name": "C", "downloadDir": "` + filePath + `"}
+		]}, "result": "success"}`,
+	})
+
+	config := types.Config{Host: "localhost", Port: 9091}
+	service := NewTorrentService(client.NewTransmissionClientWithHTTPClient(config, mockHTTP))
+
+	dirs, err := service.GetLocalDownloadDirectories(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{tmpDir}, dirs)
+
+	t.Run("path mappings translate remote directories", func(t *testing.T) {
+		mapped := NewTorrentServiceWithPathMappings(
+			client.NewTransmissionClientWithHTTPClient(config, mockHTTP),
+			types.PathMappings{{Remote: "/nonexistent/peerless", Local: tmpDir}},
+		)
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "dir"), 0755))
+
+		dirs, err := mapped.GetLocalDownloadDirectories(context.Background())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{tmpDir, filepath.Join(tmpDir, "dir")}, dirs)
+	})
+}
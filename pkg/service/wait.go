@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+// waitFields are the torrent-get fields polled while waiting for torrents
+var waitFields = []string{"hashString", "name", "downloadDir", "totalSize", "percentDone", "status"}
+
+// WaitForCompletion waits until every torrent matching filters when it is
+// called has finished downloading, polling every interval, and returns them as
+// last seen. onFinish, when set, is called once per torrent as it finishes,
+// right away for torrents already complete. It fails when no torrent matches,
+// when a matched torrent is removed before finishing, or when ctx ends.
+func (s *TorrentService) WaitForCompletion(ctx context.Context, interval time.Duration, onFinish func(types.TorrentInfo), filters ...TorrentFilter) ([]types.TorrentInfo, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid wait interval %s: must be positive", interval)
+	}
+
+	matched, err := s.GetTorrents(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no torrents match")
+	}
+
+	// Torrents are followed by hash, since qBittorrent numbers them per fetch
+	order := make([]string, len(matched))
+	latest := make(map[string]types.TorrentInfo, len(matched))
+	finished := make(map[string]bool, len(matched))
+	for i, t := range matched {
+		order[i] = waitKey(t)
+		latest[order[i]] = t
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, key := range order {
+			if t := latest[key]; !finished[key] && t.IsComplete() {
+				finished[key] = true
+				if onFinish != nil {
+					onFinish(t)
+				}
+			}
+		}
+		if len(finished) == len(order) {
+			done := make([]types.TorrentInfo, len(order))
+			for i, key := range order {
+				done[i] = latest[key]
+			}
+			return done, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting with %d of %d torrents unfinished: %w", len(order)-len(finished), len(order), ctx.Err())
+		case <-ticker.C:
+		}
+
+		torrents, err := s.client.GetTorrentsWithOptions(ctx, client.TorrentFetchOptions{Fields: waitFields})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve torrents: %w", err)
+		}
+		present := make(map[string]bool, len(torrents))
+		for _, t := range torrents {
+			key := waitKey(t)
+			if _, ok := latest[key]; ok {
+				latest[key] = t
+				present[key] = true
+			}
+		}
+		for _, key := range order {
+			if !present[key] && !finished[key] {
+				return nil, fmt.Errorf("torrent %q was removed before it finished", latest[key].Name)
+			}
+		}
+	}
+}
+
+// waitKey identifies a torrent across polls: by hash, or by ID when the
+// client reports no hash
+func waitKey(t types.TorrentInfo) string {
+	if t.HashString != "" {
+		return t.HashString
+	}
+	return strconv.Itoa(t.ID)
+}
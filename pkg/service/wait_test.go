@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/client"
+	"peerless/pkg/types"
+)
+
+// pollingMockClient answers torrent-get with torrents(n) for the nth torrent-get request
+func pollingMockClient(torrents func(n int) []map[string]interface{}) *MockHTTPClient {
+	calls := 0
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Transmission-Session-Id") == "" {
+				return NewMockResponse(409, "{}", map[string]string{"X-Transmission-Session-Id": "test-session"}), nil
+			}
+			body, _ := io.ReadAll(req.Body)
+			if !strings.Contains(string(body), `"torrent-get"`) {
+				return NewMockResponse(200, `{"arguments": {}, "result": "success"}`, nil), nil
+			}
+			calls++
+			data, _ := json.Marshal(map[string]interface{}{
+				"arguments": map[string]interface{}{"torrents": torrents(calls)},
+				"result":    "success",
+			})
+			return NewMockResponse(200, string(data), nil), nil
+		},
+	}
+}
+
+func TestWaitForCompletion(t *testing.T) {
+	ctx := context.Background()
+	newService := func(torrents func(n int) []map[string]interface{}) *TorrentService {
+		return NewTorrentService(client.NewTransmissionClientWithHTTPClient(types.Config{Host: "localhost", Port: 9091}, pollingMockClient(torrents)))
+	}
+	progress := func(n, doneAt int) float64 {
+		if n >= doneAt {
+			return 1.0
+		}
+		return 0.5
+	}
+
+	t.Run("returns once every matched torrent finished", func(t *testing.T) {
+		svc := newService(func(n int) []map[string]interface{} {
+			return []map[string]interface{}{
+				{"id": 1, "hashString": "aaa", "name": "Ubuntu 24.04", "percentDone": 1.0},
+				{"id": 2, "hashString": "bbb", "name": "Ubuntu 24.10", "percentDone": progress(n, 4)},
+				{"id": 3, "hashString": "ccc", "name": "Debian 12", "percentDone": 0.1},
+			}
+		})
+
+		var finished []string
+		done, err := svc.WaitForCompletion(ctx, time.Millisecond, func(t types.TorrentInfo) {
+			finished = append(finished, t.Name)
+		}, NameFilter("ubuntu"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ubuntu 24.04", "Ubuntu 24.10"}, finished)
+		require.Len(t, done, 2)
+		assert.True(t, done[1].IsComplete())
+	})
+
+	t.Run("no matching torrent", func(t *testing.T) {
+		svc := newService(func(n int) []map[string]interface{} {
+			return []map[string]interface{}{{"id": 1, "hashString": "aaa", "name": "Debian 12"}}
+		})
+		_, err := svc.WaitForCompletion(ctx, time.Millisecond, nil, NameFilter("ubuntu"))
+		assert.ErrorContains(t, err, "no torrents match")
+	})
+
+	t.Run("removed before finishing", func(t *testing.T) {
+		svc := newService(func(n int) []map[string]interface{} {
+			if n > 3 {
+				return []map[string]interface{}{}
+			}
+			return []map[string]interface{}{{"id": 1, "hashString": "aaa", "name": "Ubuntu", "percentDone": 0.5}}
+		})
+		_, err := svc.WaitForCompletion(ctx, time.Millisecond, nil)
+		assert.ErrorContains(t, err, `torrent "Ubuntu" was removed`)
+	})
+
+	t.Run("stops when the context ends", func(t *testing.T) {
+		svc := newService(func(n int) []map[string]interface{} {
+			return []map[string]interface{}{{"id": 1, "hashString": "aaa", "name": "Ubuntu", "percentDone": 0.5}}
+		})
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err := svc.WaitForCompletion(ctx, time.Millisecond, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "1 of 1 torrents unfinished")
+	})
+}
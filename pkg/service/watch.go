@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"peerless/pkg/utils"
+)
+
+// freeSpace returns the free space of the filesystem of a path; replaced in tests
+var freeSpace = utils.FreeSpace
+
+// CheckFunc runs one check, e.g. CheckDirectoriesWithOptions bound to its
+// options, of dirs or of every watched directory when dirs is nil
+type CheckFunc func(ctx context.Context, dirs []string) (*DirectoryCheckResult, error)
+
+// WatchRun is the outcome of one periodic check
+type WatchRun struct {
+	// Number counts runs from 1
+	Number int
+	Time   time.Time
+
+	// ChangedDirs lists the directories re-checked because their entries changed;
+	// nil for a run of every directory
+	ChangedDirs []string
+
+	// Result covers every watched directory, also after a partial run
+	Result *DirectoryCheckResult
+	Err    error
+
+	// Added and Removed list missing paths that appeared or disappeared since
+	// the last successful run; the first run reports every missing path as added
+	Added   []string
+	Removed []string
+}
+
+// Changed reports whether the missing paths differ from the previous run
+func (r WatchRun) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// Watch runs check immediately and then every interval until ctx is cancelled,
+// passing each outcome to onRun. Directories received from changes, which may
+// be nil, are re-checked at once and their results replace those of the last
+// run. A failed run is reported and the next one is still attempted, so a
+// daemon restart does not end the watch. Cancellation is a graceful shutdown
+// and returns nil; a run in progress is abandoned.
+func Watch(ctx context.Context, interval time.Duration, changes <-chan []string, check CheckFunc, onRun func(WatchRun)) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval %s: must be positive", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *DirectoryCheckResult
+	var dirs []string
+	for number := 1; ; number++ {
+		run := WatchRun{Number: number, Time: time.Now()}
+		// Without a complete result to update, every directory is checked
+		if last != nil {
+			run.ChangedDirs = dirs
+		}
+		run.Result, run.Err = check(ctx, run.ChangedDirs)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if run.Err == nil {
+			if run.ChangedDirs != nil {
+				run.Result = replaceDirectories(last, run.Result)
+			}
+			var previous []string
+			if last != nil {
+				previous = last.MissingPaths
+			}
+			run.Added, run.Removed = diffPaths(previous, run.Result.MissingPaths)
+			last = run.Result
+		}
+		onRun(run)
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				dirs = nil
+				break wait
+			case changed, ok := <-changes:
+				if !ok {
+					changes = nil
+					continue
+				}
+				dirs = changed
+				break wait
+			}
+		}
+	}
+}
+
+// replaceDirectories returns previous with the directories checked in update
+// replaced, keeping their order
+func replaceDirectories(previous, update *DirectoryCheckResult) *DirectoryCheckResult {
+	updated := make(map[string]DirectoryResult, len(update.Directories))
+	for _, dirResult := range update.Directories {
+		updated[dirResult.Path] = dirResult
+	}
+
+	merged := &DirectoryCheckResult{}
+	for _, dirResult := range previous.Directories {
+		if u, ok := updated[dirResult.Path]; ok {
+			dirResult = u
+			delete(updated, dirResult.Path)
+		}
+		merged.add(dirResult)
+	}
+	for _, dirResult := range update.Directories {
+		if _, ok := updated[dirResult.Path]; ok {
+			merged.add(dirResult)
+		}
+	}
+	return merged
+}
+
+// diffPaths returns the paths only in current and the paths only in previous, sorted
+func diffPaths(previous, current []string) (added, removed []string) {
+	before := make(map[string]bool, len(previous))
+	for _, p := range previous {
+		before[p] = true
+	}
+	now := make(map[string]bool, len(current))
+	for _, p := range current {
+		now[p] = true
+		if !before[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range previous {
+		if !now[p] {
+			removed = append(removed, p)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// LowSpace is a watched directory whose filesystem has less free space than
+// the threshold of a SpaceMonitor
+type LowSpace struct {
+	Dir  string
+	Free int64
+}
+
+// SpaceMonitor tracks the free space of watched directories against a
+// threshold, so watch can alert when a download directory is filling up
+type SpaceMonitor struct {
+	// MinFree is the free space in bytes below which a directory is low
+	MinFree int64
+
+	low map[string]bool
+}
+
+// NewSpaceMonitor returns a monitor alerting below minFree bytes
+func NewSpaceMonitor(minFree int64) *SpaceMonitor {
+	return &SpaceMonitor{MinFree: minFree, low: make(map[string]bool)}
+}
+
+// Check measures the free space of dirs and returns the directories that
+// dropped below the threshold since the last check, so each is alerted once
+// until it has recovered. Directories that could not be measured are skipped
+// and their errors joined.
+func (m *SpaceMonitor) Check(dirs []string) ([]LowSpace, error) {
+	var alerts []LowSpace
+	var errs []error
+	for _, dir := range dirs {
+		free, err := freeSpace(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if free >= m.MinFree {
+			delete(m.low, dir)
+			continue
+		}
+		if !m.low[dir] {
+			m.low[dir] = true
+			alerts = append(alerts, LowSpace{Dir: dir, Free: free})
+		}
+	}
+	return alerts, errors.Join(errs...)
+}
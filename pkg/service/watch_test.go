@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	results := []struct {
+		missing []string
+		err     error
+	}{
+		{missing: []string{"/d/a", "/d/b"}},
+		{err: errors.New("daemon unreachable")},
+		{missing: []string{"/d/b", "/d/c"}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	check := func(ctx context.Context, dirs []string) (*DirectoryCheckResult, error) {
+		r := results[calls]
+		calls++
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &DirectoryCheckResult{MissingPaths: r.missing}, nil
+	}
+
+	var runs []WatchRun
+	err := Watch(ctx, time.Millisecond, nil, check, func(run WatchRun) {
+		runs = append(runs, run)
+		if len(runs) == len(results) {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+
+	assert.Equal(t, 1, runs[0].Number)
+	assert.Equal(t, []string{"/d/a", "/d/b"}, runs[0].Added)
+	assert.True(t, runs[0].Changed())
+
+	assert.EqualError(t, runs[1].Err, "daemon unreachable")
+	assert.False(t, runs[1].Changed())
+
+	// Compared with the last successful run
+	assert.Equal(t, []string{"/d/c"}, runs[2].Added)
+	assert.Equal(t, []string{"/d/a"}, runs[2].Removed)
+}
+
+func TestWatch_ChangedDirectories(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 1)
+	var checked [][]string
+	check := func(ctx context.Context, dirs []string) (*DirectoryCheckResult, error) {
+		checked = append(checked, dirs)
+		result := &DirectoryCheckResult{}
+		if dirs == nil {
+			result.add(DirectoryResult{Path: "/a", TotalItems: 2, MissingPaths: []string{"/a/x"}})
+			result.add(DirectoryResult{Path: "/b", TotalItems: 1, MissingPaths: []string{"/b/y"}})
+			return result, nil
+		}
+		result.add(DirectoryResult{Path: "/b", TotalItems: 2, MissingPaths: []string{"/b/y", "/b/z"}})
+		return result, nil
+	}
+
+	var runs []WatchRun
+	err := Watch(ctx, time.Hour, changes, check, func(run WatchRun) {
+		runs = append(runs, run)
+		if len(runs) == 1 {
+			changes <- []string{"/b"}
+		} else {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, [][]string{nil, {"/b"}}, checked)
+
+	// Only /b was re-checked, /a is kept from the first run
+	second := runs[1]
+	assert.Equal(t, []string{"/b"}, second.ChangedDirs)
+	assert.Equal(t, []string{"/a/x", "/b/y", "/b/z"}, second.Result.MissingPaths)
+	assert.Equal(t, 4, second.Result.TotalItems)
+	assert.Equal(t, []string{"/b/z"}, second.Added)
+	assert.Empty(t, second.Removed)
+}
+
+func TestWatch_CancelDuringRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	check := func(ctx context.Context, dirs []string) (*DirectoryCheckResult, error) {
+		cancel()
+		return nil, ctx.Err()
+	}
+
+	err := Watch(ctx, time.Hour, nil, check, func(WatchRun) {
+		t.Fatal("an abandoned run must not be reported")
+	})
+	assert.NoError(t, err)
+}
+
+func TestWatch_InvalidInterval(t *testing.T) {
+	err := Watch(context.Background(), 0, nil, nil, nil)
+	assert.ErrorContains(t, err, "must be positive")
+}
+
+func TestSpaceMonitor(t *testing.T) {
+	free := map[string]int64{"/a": 100, "/b": 10}
+	original := freeSpace
+	freeSpace = func(path string) (int64, error) {
+		size, ok := free[path]
+		if !ok {
+			return 0, errors.New("no such filesystem")
+		}
+		return size, nil
+	}
+	defer func() { freeSpace = original }()
+
+	monitor := NewSpaceMonitor(50)
+	alerts, err := monitor.Check([]string{"/a", "/b"})
+	require.NoError(t, err)
+	assert.Equal(t, []LowSpace{{Dir: "/b", Free: 10}}, alerts)
+
+	// Still low: alerted once only
+	free["/b"] = 5
+	alerts, err = monitor.Check([]string{"/a", "/b"})
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// Alerted again after recovering
+	free["/b"] = 60
+	alerts, err = monitor.Check([]string{"/a", "/b"})
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+	free["/a"], free["/b"] = 20, 30
+	alerts, err = monitor.Check([]string{"/a", "/b", "/missing"})
+	assert.Error(t, err)
+	assert.Equal(t, []LowSpace{{Dir: "/a", Free: 20}, {Dir: "/b", Free: 30}}, alerts)
+}
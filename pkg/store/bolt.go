@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"peerless/pkg/constants"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore keeps records in a bbolt database, one bbolt bucket per bucket.
+// Writes are transactional and cheap however large the history grows.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates the bbolt database at path. It waits at most
+// constants.StoreLockTimeout for another peerless process holding it.
+func OpenBolt(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: constants.StoreLockTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt database %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Get reads a record
+func (s *BoltStore) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return ErrNotFound
+		}
+		// Values are only valid during the transaction
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+// Put writes a record, creating its bucket when needed
+func (s *BoltStore) Put(bucket, key string, value []byte) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Delete removes a record
+func (s *BoltStore) Delete(bucket, key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Keys lists the keys of a bucket; bbolt keeps them sorted
+func (s *BoltStore) Keys(bucket string) ([]string, error) {
+	keys := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+	}
+	return keys, nil
+}
+
+// Close closes the database, releasing its lock
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
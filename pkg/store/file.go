@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileStore keeps each record in its own file, <bucket>-<key>.json in a
+// directory, replaced atomically on every write. It suits the few small
+// records of a single machine and is the default backend.
+type FileStore struct {
+	dir string
+
+	// pinned maps bucket and key to a file chosen by the user, e.g. by --history
+	pinned map[[2]string]string
+}
+
+// NewFileStore creates a FileStore keeping its files in dir, which is created
+// on the first write
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir, pinned: make(map[[2]string]string)}
+}
+
+// Pin keeps the record in path instead of the store directory
+func (s *FileStore) Pin(bucket, key, path string) {
+	s.pinned[[2]string{bucket, key}] = path
+}
+
+// path returns the file of a record. Keys are escaped so they are valid file
+// names on every OS.
+func (s *FileStore) path(bucket, key string) string {
+	if pinned, ok := s.pinned[[2]string{bucket, key}]; ok {
+		return pinned
+	}
+	return filepath.Join(s.dir, bucket+"-"+url.QueryEscape(key)+".json")
+}
+
+// Get reads the record's file
+func (s *FileStore) Get(bucket, key string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(bucket, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s: %w", bucket, key, err)
+	}
+	return data, nil
+}
+
+// Put writes the record's file through a temporary file, so readers never
+// see a partial record
+func (s *FileStore) Put(bucket, key string, value []byte) error {
+	path := s.path(bucket, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, value, 0600); err != nil {
+		return fmt.Errorf("failed to write %s/%s: %w", bucket, key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Delete removes the record's file
+func (s *FileStore) Delete(bucket, key string) error {
+	if err := os.Remove(s.path(bucket, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Keys lists the records of bucket in the store directory and pinned ones
+func (s *FileStore) Keys(bucket string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+	}
+
+	seen := make(map[string]bool)
+	prefix := bucket + "-"
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		key, err := url.QueryUnescape(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json"))
+		if err != nil {
+			continue
+		}
+		seen[key] = true
+	}
+	for id, path := range s.pinned {
+		if id[0] != bucket {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			seen[id[1]] = true
+		} else {
+			delete(seen, id[1])
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Close does nothing; files are closed after every operation
+func (s *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"peerless/pkg/constants"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps records in one table of an SQLite database, which other
+// tools can query, e.g. to chart history on a long-running server
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens or creates the SQLite database at path
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Wait for another peerless process holding the database instead of failing
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)", path, constants.StoreLockTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	// One connection serializes access, which SQLite requires for writes anyway
+	db.SetMaxOpenConns(1)
+
+	const schema = `CREATE TABLE IF NOT EXISTS records (
+		bucket TEXT NOT NULL,
+		key    TEXT NOT NULL,
+		value  BLOB NOT NULL,
+		PRIMARY KEY (bucket, key)
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Get reads a record
+func (s *SQLiteStore) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM records WHERE bucket = ? AND key = ?`, bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s: %w", bucket, key, err)
+	}
+	return value, nil
+}
+
+// Put writes a record
+func (s *SQLiteStore) Put(bucket, key string, value []byte) error {
+	_, err := s.db.Exec(`INSERT INTO records (bucket, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value`, bucket, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to write %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Delete removes a record
+func (s *SQLiteStore) Delete(bucket, key string) error {
+	if _, err := s.db.Exec(`DELETE FROM records WHERE bucket = ? AND key = ?`, bucket, key); err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Keys lists the keys of a bucket in sorted order
+func (s *SQLiteStore) Keys(bucket string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM records WHERE bucket = ? ORDER BY key`, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+	}
+	return keys, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,70 @@
+// Package store persists state and history, such as quota samples, behind
+// one interface with plain file, bbolt and SQLite backends. Records are
+// opaque values addressed by a bucket and a key.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/paths"
+	"peerless/pkg/types"
+)
+
+// Store reads and writes records. Implementations are safe for use by one
+// process at a time; bbolt and SQLite lock their database file.
+type Store interface {
+	// Get returns the record, or ErrNotFound when there is none
+	Get(bucket, key string) ([]byte, error)
+
+	// Put creates or replaces the record
+	Put(bucket, key string, value []byte) error
+
+	// Delete removes the record; deleting a missing record is not an error
+	Delete(bucket, key string) error
+
+	// Keys returns the keys of the bucket in sorted order
+	Keys(bucket string) ([]string, error)
+
+	Close() error
+}
+
+// ErrNotFound is returned by Get for a record that does not exist
+var ErrNotFound = errors.New("record not found")
+
+// Open opens the backend selected by cfg, which may be nil for the default
+// file backend. Without a path, files are kept in the user state directory.
+func Open(cfg *types.StorageConfig) (Store, error) {
+	var backend, path string
+	if cfg != nil {
+		backend, path = cfg.Backend, cfg.Path
+	}
+
+	if path == "" {
+		dir, err := paths.StateDir()
+		if err != nil {
+			return nil, err
+		}
+		switch backend {
+		case constants.StorageBolt:
+			path = filepath.Join(dir, constants.BoltFileName)
+		case constants.StorageSQLite:
+			path = filepath.Join(dir, constants.SQLiteFileName)
+		default:
+			path = dir
+		}
+	}
+
+	switch backend {
+	case "", constants.StorageFile:
+		return NewFileStore(path), nil
+	case constants.StorageBolt:
+		return OpenBolt(path)
+	case constants.StorageSQLite:
+		return OpenSQLite(path)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", backend)
+	}
+}
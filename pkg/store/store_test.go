@@ -0,0 +1,98 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/constants"
+	"peerless/pkg/types"
+)
+
+func TestBackends(t *testing.T) {
+	backends := map[string]func(t *testing.T, dir string) Store{
+		constants.StorageFile: func(t *testing.T, dir string) Store {
+			return NewFileStore(dir)
+		},
+		constants.StorageBolt: func(t *testing.T, dir string) Store {
+			st, err := OpenBolt(filepath.Join(dir, "state.db"))
+			require.NoError(t, err)
+			return st
+		},
+		constants.StorageSQLite: func(t *testing.T, dir string) Store {
+			st, err := OpenSQLite(filepath.Join(dir, "state.sqlite"))
+			require.NoError(t, err)
+			return st
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "nested")
+			st := open(t, dir)
+
+			_, err := st.Get("quota", "history")
+			assert.ErrorIs(t, err, ErrNotFound)
+			keys, err := st.Keys("quota")
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+
+			require.NoError(t, st.Put("quota", "history", []byte(`{"v":1}`)))
+			require.NoError(t, st.Put("quota", "host:9091/rpc", []byte(`{"v":2}`)))
+			require.NoError(t, st.Put("snapshots", "a", []byte(`{}`)))
+			require.NoError(t, st.Put("quota", "history", []byte(`{"v":3}`)))
+
+			value, err := st.Get("quota", "history")
+			require.NoError(t, err)
+			assert.Equal(t, `{"v":3}`, string(value))
+
+			keys, err = st.Keys("quota")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"history", "host:9091/rpc"}, keys)
+
+			require.NoError(t, st.Delete("quota", "history"))
+			require.NoError(t, st.Delete("quota", "history"), "deleting a missing record")
+			_, err = st.Get("quota", "history")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			// Records survive reopening
+			require.NoError(t, st.Close())
+			st = open(t, dir)
+			defer st.Close()
+			value, err = st.Get("quota", "host:9091/rpc")
+			require.NoError(t, err)
+			assert.Equal(t, `{"v":2}`, string(value))
+		})
+	}
+}
+
+func TestFileStore_Pin(t *testing.T) {
+	dir := t.TempDir()
+	st := NewFileStore(filepath.Join(dir, "state"))
+	pinned := filepath.Join(dir, "custom", "history.json")
+	st.Pin("quota", "history", pinned)
+
+	require.NoError(t, st.Put("quota", "history", []byte("[]")))
+	assert.FileExists(t, pinned)
+
+	keys, err := st.Keys("quota")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"history"}, keys)
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	st, err := Open(&types.StorageConfig{Backend: constants.StorageBolt, Path: filepath.Join(dir, "peerless.db")})
+	require.NoError(t, err)
+	assert.IsType(t, &BoltStore{}, st)
+	require.NoError(t, st.Close())
+
+	st, err = Open(&types.StorageConfig{Path: dir})
+	require.NoError(t, err)
+	assert.IsType(t, &FileStore{}, st)
+
+	_, err = Open(&types.StorageConfig{Backend: "redis", Path: dir})
+	assert.ErrorContains(t, err, "unsupported storage backend")
+}
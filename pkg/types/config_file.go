@@ -0,0 +1,391 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/paths"
+)
+
+// FileConfig is the optional YAML configuration file
+type FileConfig struct {
+	// Defaults are the top-level connection settings, used without --profile
+	Defaults Profile `yaml:",inline"`
+
+	Presets  map[string]Preset  `yaml:"presets"`
+	Profiles map[string]Profile `yaml:"profiles"`
+
+	// DirProfiles maps local directories to the profile of the Transmission
+	// instance that owns them, so one check can span several daemons
+	DirProfiles map[string]string `yaml:"dir_profiles"`
+
+	Notify NotifyConfig `yaml:"notify"`
+
+	Hooks *HooksConfig `yaml:"hooks"`
+
+	Quota *QuotaConfig `yaml:"quota"`
+
+	Watch *WatchConfig `yaml:"watch"`
+
+	DiskQuota *DiskQuotaConfig `yaml:"disk-quota"`
+
+	Storage *StorageConfig `yaml:"storage"`
+
+	Archive *ArchiveConfig `yaml:"archive"`
+
+	SeedGoals *SeedGoalsConfig `yaml:"seed-goals"`
+}
+
+// SeedGoalsConfig sets how long torrents should seed, as ages such as "14d"
+type SeedGoalsConfig struct {
+	// Default is the goal of torrents matching none of Trackers
+	Default string `yaml:"default"`
+
+	// Trackers maps text contained in an announce URL to the goal of the
+	// torrents using that tracker
+	Trackers map[string]string `yaml:"trackers"`
+}
+
+// ArchiveConfig sets how many check results are kept in the state store
+type ArchiveConfig struct {
+	// Keep is the number of runs kept; 0 uses the default of 30
+	Keep int `yaml:"keep"`
+
+	// Disabled turns off archiving check results
+	Disabled bool `yaml:"disabled"`
+}
+
+// HooksConfig holds commands check runs on its result, for handling missing
+// items in ways peerless has no built-in support for. A {} in a command is
+// replaced by its argument, which is appended when there is none.
+type HooksConfig struct {
+	// Missing runs once per missing item with its absolute path
+	Missing string `yaml:"missing"`
+
+	// Done runs once per check with the path of the file listing the missing items
+	Done string `yaml:"done"`
+}
+
+// StorageConfig selects where state and history, such as quota samples, are kept
+type StorageConfig struct {
+	// Backend is file (default), bolt or sqlite
+	Backend string `yaml:"backend"`
+
+	// Path is the directory of the file backend or the database file of the
+	// others; the user state directory is used by default
+	Path string `yaml:"path"`
+}
+
+// DiskQuotaConfig sets the storage quota of a shared seedbox, which status
+// and check report instead of the filesystem's free space
+type DiskQuotaConfig struct {
+	// Limit is the allowed storage, e.g. "2TB"; optional when Command reports it
+	Limit string `yaml:"limit"`
+
+	// Command prints the used space and optionally the limit, e.g. "812GB 2TB"
+	Command string `yaml:"command"`
+
+	// Path is measured for the used space when no Command is set
+	Path string `yaml:"path"`
+}
+
+// QuotaConfig sets the monthly data cap tracked by the quota command
+type QuotaConfig struct {
+	// Cap is the allowed upload plus download per period, e.g. "1TB"
+	Cap string `yaml:"cap"`
+
+	// WarnPercent is the share of Cap at which quota warns
+	WarnPercent int `yaml:"warn-percent"`
+
+	// ResetDay is the day of the month the period starts on (1-28)
+	ResetDay int `yaml:"reset-day"`
+}
+
+// WatchConfig sets the alerts of the watch command
+type WatchConfig struct {
+	// MinFree is the free space, e.g. "50GB", below which watch alerts about
+	// a watched directory
+	MinFree string `yaml:"min-free"`
+}
+
+// NotifyConfig selects where check results are sent after each run
+type NotifyConfig struct {
+	Email   *EmailConfig   `yaml:"email"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+}
+
+// Webhook payload formats
+const (
+	WebhookDiscord = "discord"
+	WebhookSlack   = "slack"
+	WebhookJSON    = "json"
+)
+
+// WebhookConfig holds the endpoint check results are POSTed to
+type WebhookConfig struct {
+	URL string `yaml:"url"`
+
+	// Format is discord, slack or json; empty detects it from the URL
+	Format string `yaml:"format"`
+
+	// Template, when set, is a Go text/template rendered with the run summary.
+	// It replaces the message text for discord and slack and the whole body
+	// for json.
+	Template string `yaml:"template"`
+}
+
+// EmailConfig holds the SMTP settings for emailing check results
+type EmailConfig struct {
+	Host     string     `yaml:"host"`
+	Port     int        `yaml:"port"`
+	User     string     `yaml:"user"`
+	Password string     `yaml:"password"`
+	From     string     `yaml:"from"`
+	To       StringList `yaml:"to"`
+}
+
+// Profile holds the connection settings and local defaults for one Transmission instance
+type Profile struct {
+	// Client selects the backend: transmission (default) or qbittorrent
+	Client string `yaml:"client"`
+
+	// Server is an RPC URL; host, port, user and password override its parts
+	Server string `yaml:"server"`
+
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+
+	// Socket is a Unix domain socket to connect through instead of host and port
+	Socket string `yaml:"socket"`
+
+	// Reverse proxy credentials, sent alongside the Transmission ones
+	ProxyUser       string `yaml:"proxy-user"`
+	ProxyPassword   string `yaml:"proxy-password"`
+	ProxyAuthHeader string `yaml:"proxy-auth-header"`
+
+	// Dirs are checked when no --dir is given
+	Dirs StringList `yaml:"dirs"`
+
+	// PathMappings translate Transmission's paths into local ones
+	PathMappings PathMappings `yaml:"path-mappings"`
+}
+
+// Preset is a named set of filter values applied with --preset
+type Preset struct {
+	Dir       StringList `yaml:"dir"`
+	Label     StringList `yaml:"label"`
+	OlderThan string     `yaml:"older-than"`
+	MinSize   string     `yaml:"min-size"`
+}
+
+// StringList accepts either a single YAML string or a list of strings
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// DefaultConfigPath returns the config file location inside the user config directory
+func DefaultConfigPath() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, constants.ConfigFileName), nil
+}
+
+// LoadFileConfig reads and parses a YAML config file. Unknown keys are rejected
+// so typos surface instead of being silently ignored.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	cfg := &FileConfig{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// validate checks values that YAML decoding alone cannot catch
+func (c *FileConfig) validate() error {
+	var errs []error
+	for i, mapping := range c.Defaults.PathMappings {
+		if mapping.Remote == "" || mapping.Local == "" {
+			errs = append(errs, fmt.Errorf("path-mappings[%d] needs both remote and local", i))
+		}
+	}
+	for name, profile := range c.Profiles {
+		for i, mapping := range profile.PathMappings {
+			if mapping.Remote == "" || mapping.Local == "" {
+				errs = append(errs, fmt.Errorf("profile %q: path-mappings[%d] needs both remote and local", name, i))
+			}
+		}
+	}
+	if email := c.Notify.Email; email != nil {
+		if email.Host == "" {
+			errs = append(errs, fmt.Errorf("notify.email: host is required"))
+		}
+		if email.From == "" {
+			errs = append(errs, fmt.Errorf("notify.email: from is required"))
+		}
+		if len(email.To) == 0 {
+			errs = append(errs, fmt.Errorf("notify.email: at least one recipient in to is required"))
+		}
+	}
+	if hook := c.Notify.Webhook; hook != nil {
+		if err := hook.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("notify.webhook: %w", err))
+		}
+	}
+	if quota := c.Quota; quota != nil {
+		if quota.WarnPercent < 0 || quota.WarnPercent > 100 {
+			errs = append(errs, fmt.Errorf("quota: warn-percent must be between 0 and 100"))
+		}
+		if quota.ResetDay < 0 || quota.ResetDay > 28 {
+			errs = append(errs, fmt.Errorf("quota: reset-day must be between 1 and 28"))
+		}
+	}
+	if storage := c.Storage; storage != nil {
+		switch storage.Backend {
+		case "", constants.StorageFile, constants.StorageBolt, constants.StorageSQLite:
+		default:
+			errs = append(errs, fmt.Errorf("storage: invalid backend %q: must be %s, %s or %s", storage.Backend, constants.StorageFile, constants.StorageBolt, constants.StorageSQLite))
+		}
+	}
+	if dq := c.DiskQuota; dq != nil {
+		if dq.Command == "" && dq.Path == "" {
+			errs = append(errs, fmt.Errorf("disk-quota: command or path is required"))
+		}
+		if dq.Command == "" && dq.Limit == "" {
+			errs = append(errs, fmt.Errorf("disk-quota: limit is required without command"))
+		}
+	}
+	for dir, name := range c.DirProfiles {
+		if _, ok := c.Profiles[name]; !ok {
+			errs = append(errs, fmt.Errorf("dir_profiles: %s refers to unknown profile %q", dir, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ProfileForDir returns the profile owning dir according to DirProfiles. The
+// longest configured directory containing dir wins.
+func (c *FileConfig) ProfileForDir(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = filepath.Clean(dir)
+	}
+
+	best, bestLen := "", -1
+	for owned, name := range c.DirProfiles {
+		owned = filepath.Clean(owned)
+		if abs != owned && !strings.HasPrefix(abs, strings.TrimSuffix(owned, string(filepath.Separator))+string(filepath.Separator)) {
+			continue
+		}
+		if len(owned) > bestLen {
+			best, bestLen = name, len(owned)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// Preset returns the named preset, or an error listing the presets that exist
+func (c *FileConfig) Preset(name string) (Preset, error) {
+	if preset, ok := c.Presets[name]; ok {
+		return preset, nil
+	}
+	return Preset{}, unknownNameError("preset", name, c.Presets)
+}
+
+// Profile returns the named profile, or an error listing the profiles that exist
+func (c *FileConfig) Profile(name string) (Profile, error) {
+	if profile, ok := c.Profiles[name]; ok {
+		return profile, nil
+	}
+	return Profile{}, unknownNameError("profile", name, c.Profiles)
+}
+
+// unknownNameError reports a missing config entry together with the names that are defined
+func unknownNameError[T any](kind, name string, defined map[string]T) error {
+	names := make([]string, 0, len(defined))
+	for n := range defined {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return fmt.Errorf("unknown %s %q: no %ss defined in config", kind, name, kind)
+	}
+	return fmt.Errorf("unknown %s %q (available: %s)", kind, name, strings.Join(names, ", "))
+}
+
+// FlagValues returns the preset as flag names mapped to their values
+func (p Preset) FlagValues() map[string][]string {
+	values := make(map[string][]string)
+	if len(p.Dir) > 0 {
+		values["dir"] = p.Dir
+	}
+	if len(p.Label) > 0 {
+		values["label"] = p.Label
+	}
+	if p.OlderThan != "" {
+		values["older-than"] = []string{p.OlderThan}
+	}
+	if p.MinSize != "" {
+		values["min-size"] = []string{p.MinSize}
+	}
+	return values
+}
+
+// Validate checks the URL, format and template of a webhook
+func (w *WebhookConfig) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL, got %q", w.URL)
+	}
+	switch w.Format {
+	case "", WebhookDiscord, WebhookSlack, WebhookJSON:
+	default:
+		return fmt.Errorf("invalid format %q: must be %s, %s or %s", w.Format, WebhookDiscord, WebhookSlack, WebhookJSON)
+	}
+	if w.Template != "" {
+		if _, err := template.New("webhook").Parse(w.Template); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadFileConfig(t *testing.T) {
+	t.Run("presets", func(t *testing.T) {
+		path := writeConfig(t, `
+presets:
+  movies-cleanup:
+    dir: /media/movies
+    older-than: 60d
+    min-size: 1GB
+  shows:
+    dir: [/media/tv, /media/anime]
+    label: tv
+`)
+
+		cfg, err := LoadFileConfig(path)
+		require.NoError(t, err)
+		require.Len(t, cfg.Presets, 2)
+
+		movies, err := cfg.Preset("movies-cleanup")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"dir":        {"/media/movies"},
+			"older-than": {"60d"},
+			"min-size":   {"1GB"},
+		}, movies.FlagValues())
+
+		shows, err := cfg.Preset("shows")
+		require.NoError(t, err)
+		assert.Equal(t, StringList{"/media/tv", "/media/anime"}, shows.Dir)
+		assert.Equal(t, StringList{"tv"}, shows.Label)
+	})
+
+	t.Run("top-level connection settings", func(t *testing.T) {
+		path := writeConfig(t, `
+host: nas.local
+port: 9092
+user: admin
+password: secret
+dirs: /media/downloads
+profiles:
+  seedbox:
+    host: seedbox.example.com
+`)
+
+		cfg, err := LoadFileConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "nas.local", cfg.Defaults.Host)
+		assert.Equal(t, 9092, cfg.Defaults.Port)
+		assert.Equal(t, "admin", cfg.Defaults.User)
+		assert.Equal(t, StringList{"/media/downloads"}, cfg.Defaults.Dirs)
+		assert.Contains(t, cfg.Profiles, "seedbox")
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		path := writeConfig(t, `
+hooks:
+  missing: "mv {} /mnt/orphans/"
+  done: "wc -l {}"
+`)
+
+		cfg, err := LoadFileConfig(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Hooks)
+		assert.Equal(t, HooksConfig{Missing: "mv {} /mnt/orphans/", Done: "wc -l {}"}, *cfg.Hooks)
+	})
+
+	t.Run("profiles", func(t *testing.T) {
+		path := writeConfig(t, `
+profiles:
+  seedbox:
+    host: seedbox.example.com
+    port: 443
+    user: admin
+    password: secret
+    dirs: [/mnt/seedbox/movies, /mnt/seedbox/tv]
+    path-mappings:
+      - remote: /home/admin/downloads
+        local: /mnt/seedbox
+`)
+
+		cfg, err := LoadFileConfig(path)
+		require.NoError(t, err)
+
+		profile, err := cfg.Profile("seedbox")
+		require.NoError(t, err)
+		assert.Equal(t, "seedbox.example.com", profile.Host)
+		assert.Equal(t, 443, profile.Port)
+		assert.Equal(t, StringList{"/mnt/seedbox/movies", "/mnt/seedbox/tv"}, profile.Dirs)
+		assert.Equal(t, "/mnt/seedbox/movies/Film", profile.PathMappings.ToLocal("/home/admin/downloads/movies/Film"))
+
+		_, err = cfg.Profile("nas")
+		assert.EqualError(t, err, `unknown profile "nas" (available: seedbox)`)
+	})
+
+	t.Run("incomplete path mapping is rejected", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "profiles:\n  x:\n    path-mappings:\n      - remote: /downloads\n"))
+		assert.ErrorContains(t, err, "needs both remote and local")
+	})
+
+	t.Run("notify email", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, `
+notify:
+  email:
+    host: smtp.example.com
+    user: me
+    password: secret
+    from: peerless@nas
+    to: me@example.com
+`))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Notify.Email)
+		assert.Equal(t, "smtp.example.com", cfg.Notify.Email.Host)
+		assert.Equal(t, StringList{"me@example.com"}, cfg.Notify.Email.To)
+	})
+
+	t.Run("notify email needs sender and recipients", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "notify:\n  email:\n    host: smtp.example.com\n"))
+		assert.ErrorContains(t, err, "from is required")
+		assert.ErrorContains(t, err, "at least one recipient")
+	})
+
+	t.Run("notify webhook", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, `
+notify:
+  webhook:
+    url: https://hooks.example.com/peerless
+    format: json
+    template: '{"text": "{{.Missing}} missing on {{.Host}}"}'
+`))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Notify.Webhook)
+		assert.Equal(t, WebhookJSON, cfg.Notify.Webhook.Format)
+	})
+
+	t.Run("notify webhook is validated", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "notify:\n  webhook:\n    url: ftp://example.com\n    format: teams\n"))
+		assert.ErrorContains(t, err, "url must be an http or https URL")
+
+		_, err = LoadFileConfig(writeConfig(t, "notify:\n  webhook:\n    url: https://example.com\n    format: teams\n"))
+		assert.ErrorContains(t, err, `invalid format "teams"`)
+
+		_, err = LoadFileConfig(writeConfig(t, "notify:\n  webhook:\n    url: https://example.com\n    template: '{{.Missing'\n"))
+		assert.ErrorContains(t, err, "invalid template")
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, "quota:\n  cap: 1TB\n  warn-percent: 80\n  reset-day: 15\n"))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Quota)
+		assert.Equal(t, QuotaConfig{Cap: "1TB", WarnPercent: 80, ResetDay: 15}, *cfg.Quota)
+	})
+
+	t.Run("quota reset day is checked", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "quota:\n  cap: 1TB\n  reset-day: 31\n"))
+		assert.ErrorContains(t, err, "reset-day must be between 1 and 28")
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, "watch:\n  min-free: 50GB\n"))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Watch)
+		assert.Equal(t, "50GB", cfg.Watch.MinFree)
+	})
+
+	t.Run("disk quota", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, "disk-quota:\n  limit: 2TB\n  path: /home/seed\n"))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.DiskQuota)
+		assert.Equal(t, DiskQuotaConfig{Limit: "2TB", Path: "/home/seed"}, *cfg.DiskQuota)
+	})
+
+	t.Run("disk quota needs a source", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "disk-quota:\n  limit: 2TB\n"))
+		assert.ErrorContains(t, err, "command or path is required")
+
+		_, err = LoadFileConfig(writeConfig(t, "disk-quota:\n  path: /home/seed\n"))
+		assert.ErrorContains(t, err, "limit is required")
+	})
+
+	t.Run("seed goals", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, "seed-goals:\n  default: 14d\n  trackers:\n    tracker.example.org: 30d\n"))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.SeedGoals)
+		assert.Equal(t, "14d", cfg.SeedGoals.Default)
+		assert.Equal(t, map[string]string{"tracker.example.org": "30d"}, cfg.SeedGoals.Trackers)
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, "storage:\n  backend: sqlite\n  path: /var/lib/peerless/state.sqlite\n"))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Storage)
+		assert.Equal(t, StorageConfig{Backend: "sqlite", Path: "/var/lib/peerless/state.sqlite"}, *cfg.Storage)
+
+		_, err = LoadFileConfig(writeConfig(t, "storage:\n  backend: redis\n"))
+		assert.ErrorContains(t, err, "invalid backend")
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, ""))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Presets)
+	})
+
+	t.Run("unknown key is rejected", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "presets:\n  x:\n    olderthan: 1d\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadFileConfig(filepath.Join(t.TempDir(), "nope.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("unknown preset lists available names", func(t *testing.T) {
+		cfg := &FileConfig{Presets: map[string]Preset{"b": {}, "a": {}}}
+		_, err := cfg.Preset("c")
+		assert.EqualError(t, err, `unknown preset "c" (available: a, b)`)
+	})
+
+	t.Run("dir profiles", func(t *testing.T) {
+		cfg, err := LoadFileConfig(writeConfig(t, `
+profiles:
+  seedbox-a:
+    host: a.example.com
+  seedbox-b:
+    host: b.example.com
+dir_profiles:
+  /media: seedbox-a
+  /media/tv: seedbox-b
+`))
+		require.NoError(t, err)
+
+		name, ok := cfg.ProfileForDir("/media/movies")
+		assert.True(t, ok)
+		assert.Equal(t, "seedbox-a", name)
+
+		name, ok = cfg.ProfileForDir("/media/tv/Show")
+		assert.True(t, ok)
+		assert.Equal(t, "seedbox-b", name)
+
+		_, ok = cfg.ProfileForDir("/mediax")
+		assert.False(t, ok)
+	})
+
+	t.Run("dir profile must name a defined profile", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfig(t, "dir_profiles:\n  /media: missing\n"))
+		assert.ErrorContains(t, err, `unknown profile "missing"`)
+	})
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"peerless/pkg/constants"
+)
+
+// ConfigLayer is one source of connection settings, such as the config file,
+// the environment or the command line
+type ConfigLayer struct {
+	// Source names the layer in error messages
+	Source   string
+	Settings Profile
+}
+
+// LoadConfig builds a Config from layers given in increasing precedence and
+// fills in defaults. Each layer only overrides the settings it contains; a
+// server URL is applied before the individual settings of the same layer.
+func LoadConfig(layers ...ConfigLayer) (Config, error) {
+	var cfg Config
+	for _, layer := range layers {
+		if err := layer.Settings.applyTo(&cfg); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", layer.Source, err)
+		}
+	}
+	cfg.SetDefaults()
+	return cfg, nil
+}
+
+// ConnectionLayers returns the connection settings of the config file, the
+// named profile, if any, and the environment read with getenv, in increasing
+// precedence. Flags given on the command line go on top.
+func (c *FileConfig) ConnectionLayers(profileName string, getenv func(string) string) ([]ConfigLayer, error) {
+	layers := []ConfigLayer{{Source: "config file", Settings: c.Defaults}}
+	if profileName != "" {
+		profile, err := c.Profile(profileName)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, ConfigLayer{Source: fmt.Sprintf("profile %q", profileName), Settings: profile})
+	}
+
+	env, err := EnvProfile(getenv)
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, ConfigLayer{Source: "environment", Settings: env}), nil
+}
+
+// applyTo copies the settings present in p onto cfg
+func (p Profile) applyTo(cfg *Config) error {
+	if p.Client != "" {
+		cfg.Client = p.Client
+	}
+	if p.Server != "" {
+		if err := cfg.ApplyServerURL(p.Server); err != nil {
+			return err
+		}
+	}
+	if p.Host != "" {
+		cfg.Host = strings.TrimSpace(p.Host)
+	}
+	if p.Port != 0 {
+		cfg.Port = p.Port
+	}
+	if p.Socket != "" {
+		cfg.Socket = p.Socket
+	}
+	if p.User != "" {
+		cfg.User = p.User
+	}
+	if p.Password != "" {
+		cfg.Password = p.Password
+	}
+	if p.ProxyUser != "" {
+		cfg.ProxyUser = p.ProxyUser
+	}
+	if p.ProxyPassword != "" {
+		cfg.ProxyPassword = p.ProxyPassword
+	}
+	if p.ProxyAuthHeader != "" {
+		cfg.ProxyAuthHeader = p.ProxyAuthHeader
+	}
+	if len(p.Dirs) > 0 {
+		cfg.Dirs = p.Dirs
+	}
+	if len(p.PathMappings) > 0 {
+		cfg.PathMappings = p.PathMappings
+	}
+	return nil
+}
+
+// EnvProfile reads connection settings from the PEERLESS_* environment
+// variables using getenv, normally os.Getenv
+func EnvProfile(getenv func(string) string) (Profile, error) {
+	p := Profile{
+		Server:        getenv(constants.EnvServer),
+		Host:          getenv(constants.EnvHost),
+		Socket:        getenv(constants.EnvSocket),
+		User:          getenv(constants.EnvUser),
+		Password:      getenv(constants.EnvPassword),
+		ProxyUser:     getenv(constants.EnvProxyUser),
+		ProxyPassword: getenv(constants.EnvProxyPassword),
+	}
+
+	if port := getenv(constants.EnvPort); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return Profile{}, &ValidationError{Field: constants.EnvPort, Message: fmt.Sprintf("invalid port %q", port)}
+		}
+		p.Port = n
+	}
+
+	for _, dir := range filepath.SplitList(getenv(constants.EnvDirs)) {
+		if dir != "" {
+			p.Dirs = append(p.Dirs, dir)
+		}
+	}
+
+	return p, nil
+}
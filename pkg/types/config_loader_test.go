@@ -0,0 +1,129 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/constants"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("defaults without layers", func(t *testing.T) {
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, constants.DefaultPort, cfg.Port)
+		assert.Equal(t, constants.DefaultRPCPath, cfg.RPCPath)
+	})
+
+	t.Run("later layers override earlier ones", func(t *testing.T) {
+		cfg, err := LoadConfig(
+			ConfigLayer{Source: "config file", Settings: Profile{Host: "nas", Port: 9000, User: "file", Password: "filepass", Dirs: StringList{"/media"}}},
+			ConfigLayer{Source: "environment", Settings: Profile{User: "env", Password: "envpass"}},
+			ConfigLayer{Source: "command line", Settings: Profile{Host: "seedbox"}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "seedbox", cfg.Host)
+		assert.Equal(t, 9000, cfg.Port)
+		assert.Equal(t, "env", cfg.User)
+		assert.Equal(t, "envpass", cfg.Password)
+		assert.Equal(t, []string{"/media"}, cfg.Dirs)
+	})
+
+	t.Run("server URL is applied before the settings of its layer", func(t *testing.T) {
+		cfg, err := LoadConfig(
+			ConfigLayer{Source: "config file", Settings: Profile{Host: "nas", User: "file"}},
+			ConfigLayer{Source: "command line", Settings: Profile{Server: "https://box.example.com/rpc", Port: 8443}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "box.example.com", cfg.Host)
+		assert.Equal(t, 8443, cfg.Port)
+		assert.True(t, cfg.UseTLS)
+		assert.Equal(t, "/rpc", cfg.RPCPath)
+		assert.Equal(t, "file", cfg.User)
+	})
+
+	t.Run("errors name the layer", func(t *testing.T) {
+		_, err := LoadConfig(ConfigLayer{Source: "environment", Settings: Profile{Server: "ftp://nas"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "environment:")
+	})
+}
+
+func TestFileConfig_ConnectionLayers(t *testing.T) {
+	fileCfg := &FileConfig{
+		Defaults: Profile{Host: "nas", Port: 9000, User: "file", Dirs: StringList{"/media"}},
+		Profiles: map[string]Profile{
+			"seedbox": {Host: "seedbox.example.com", User: "profile", Password: "profilepass"},
+		},
+	}
+	getenv := func(name string) string {
+		return map[string]string{constants.EnvUser: "env"}[name]
+	}
+
+	// flags > env > file profile > file defaults > built-in defaults
+	layers, err := fileCfg.ConnectionLayers("seedbox", getenv)
+	require.NoError(t, err)
+	flags := ConfigLayer{Source: "command line", Settings: Profile{Password: "flagpass"}}
+	cfg, err := LoadConfig(append(layers, flags)...)
+	require.NoError(t, err)
+	assert.Equal(t, "seedbox.example.com", cfg.Host)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, "env", cfg.User)
+	assert.Equal(t, "flagpass", cfg.Password)
+	assert.Equal(t, []string{"/media"}, cfg.Dirs)
+	assert.Equal(t, constants.DefaultRPCPath, cfg.RPCPath)
+
+	t.Run("without a profile", func(t *testing.T) {
+		layers, err := fileCfg.ConnectionLayers("", getenv)
+		require.NoError(t, err)
+		cfg, err := LoadConfig(layers...)
+		require.NoError(t, err)
+		assert.Equal(t, "nas", cfg.Host)
+		assert.Equal(t, "env", cfg.User)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := fileCfg.ConnectionLayers("missing", getenv)
+		assert.ErrorContains(t, err, `unknown profile "missing"`)
+	})
+}
+
+func TestEnvProfile(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	t.Run("reads connection settings", func(t *testing.T) {
+		p, err := EnvProfile(env(map[string]string{
+			constants.EnvHost:     "nas",
+			constants.EnvPort:     "9092",
+			constants.EnvSocket:   "/run/transmission/rpc.sock",
+			constants.EnvUser:     "admin",
+			constants.EnvPassword: "secret",
+			constants.EnvDirs:     "/media/movies:/media/tv",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, Profile{
+			Host:     "nas",
+			Port:     9092,
+			Socket:   "/run/transmission/rpc.sock",
+			User:     "admin",
+			Password: "secret",
+			Dirs:     StringList{"/media/movies", "/media/tv"},
+		}, p)
+	})
+
+	t.Run("empty environment", func(t *testing.T) {
+		p, err := EnvProfile(env(nil))
+		require.NoError(t, err)
+		assert.Equal(t, Profile{}, p)
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		_, err := EnvProfile(env(map[string]string{constants.EnvPort: "http"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), constants.EnvPort)
+	})
+}
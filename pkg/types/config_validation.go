@@ -3,6 +3,7 @@ package types
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 
 	"peerless/pkg/constants"
@@ -37,6 +38,12 @@ func (e ValidationErrors) Error() string {
 func (c *Config) Validate() error {
 	var errors ValidationErrors
 
+	if err := c.ValidateClient(); err != nil {
+		if ve, ok := err.(*ValidationError); ok {
+			errors = append(errors, *ve)
+		}
+	}
+
 	if err := c.ValidateHost(); err != nil {
 		if ve, ok := err.(*ValidationError); ok {
 			errors = append(errors, *ve)
@@ -49,12 +56,24 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := c.ValidateSocket(); err != nil {
+		if ve, ok := err.(*ValidationError); ok {
+			errors = append(errors, *ve)
+		}
+	}
+
 	if err := c.ValidateAuth(); err != nil {
 		if ve, ok := err.(*ValidationError); ok {
 			errors = append(errors, *ve)
 		}
 	}
 
+	if err := c.ValidateProxyAuth(); err != nil {
+		if ve, ok := err.(*ValidationError); ok {
+			errors = append(errors, *ve)
+		}
+	}
+
 	if err := c.ValidateDirs(); err != nil {
 		if ve, ok := err.(*ValidationError); ok {
 			errors = append(errors, *ve)
@@ -100,6 +119,33 @@ func (c *Config) ValidatePort() error {
 	return nil
 }
 
+// ValidateClient validates the torrent client backend
+func (c *Config) ValidateClient() error {
+	switch c.Client {
+	case "", constants.ClientTransmission, constants.ClientQBittorrent:
+		return nil
+	default:
+		return &ValidationError{
+			Field:   "client",
+			Message: fmt.Sprintf("unsupported client %q (use %s or %s)", c.Client, constants.ClientTransmission, constants.ClientQBittorrent),
+		}
+	}
+}
+
+// ValidateSocket validates the Unix domain socket configuration
+func (c *Config) ValidateSocket() error {
+	if c.Socket == "" {
+		return nil
+	}
+	if c.Client == constants.ClientQBittorrent {
+		return &ValidationError{Field: "socket", Message: "a Unix socket is only supported for Transmission"}
+	}
+	if !filepath.IsAbs(c.Socket) {
+		return &ValidationError{Field: "socket", Message: fmt.Sprintf("socket path must be absolute, got %q", c.Socket)}
+	}
+	return nil
+}
+
 // ValidateAuth validates the authentication configuration
 func (c *Config) ValidateAuth() error {
 	if c.User != "" && c.Password == "" {
@@ -113,7 +159,7 @@ func (c *Config) ValidateAuth() error {
 			if strings.ToLower(c.Password) == weak {
 				return &ValidationError{
 					Field:   "password",
-					Message: fmt.Sprintf("weak password detected: '%s' should not be used", weak),
+					Message: "weak password detected: common default passwords should not be used",
 				}
 			}
 		}
@@ -122,6 +168,28 @@ func (c *Config) ValidateAuth() error {
 	return nil
 }
 
+// ValidateProxyAuth validates the reverse proxy credentials
+func (c *Config) ValidateProxyAuth() error {
+	if c.ProxyUser == "" {
+		if c.ProxyPassword != "" {
+			return &ValidationError{Field: "proxy-user", Message: "proxy user is required when a proxy password is provided"}
+		}
+		return nil
+	}
+
+	if c.ProxyPassword == "" {
+		return &ValidationError{Field: "proxy-password", Message: "proxy password is required when a proxy user is provided"}
+	}
+	// qBittorrent logs in with a cookie, leaving the Authorization header free
+	if c.User != "" && c.Client != constants.ClientQBittorrent && strings.EqualFold(c.ProxyAuthHeader, "Authorization") {
+		return &ValidationError{
+			Field:   "proxy-auth-header",
+			Message: "proxy credentials cannot use the Authorization header when Transmission credentials are set",
+		}
+	}
+	return nil
+}
+
 // ValidateDirs validates the directories configuration
 func (c *Config) ValidateDirs() error {
 	if len(c.Dirs) == 0 {
@@ -171,7 +239,28 @@ func isValidHostname(hostname string) bool {
 
 // SetDefaults sets default values for optional configuration fields
 func (c *Config) SetDefaults() {
-	if c.Port == 0 {
-		c.Port = constants.DefaultPort
+	if c.Client == "" {
+		c.Client = constants.ClientTransmission
+	}
+
+	// The qBittorrent Web UI is served from the root unless a server URL gives a path
+	if c.Client == constants.ClientQBittorrent {
+		if c.Port == 0 {
+			c.Port = constants.DefaultQBittorrentPort
+		}
+	} else {
+		if c.Port == 0 {
+			c.Port = constants.DefaultPort
+		}
+		// Requests through a socket still need a host for their URL
+		if c.Socket != "" && c.Host == "" {
+			c.Host = constants.SocketHost
+		}
+		if c.RPCPath == "" {
+			c.RPCPath = constants.DefaultRPCPath
+		}
+	}
+	if c.ProxyUser != "" && c.ProxyAuthHeader == "" {
+		c.ProxyAuthHeader = constants.DefaultProxyAuthHeader
 	}
 }
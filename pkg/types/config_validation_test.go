@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"peerless/pkg/constants"
 )
 
@@ -249,6 +250,58 @@ func TestConfig_ValidateAuth(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateProxyAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		errorMsg string
+	}{
+		{"no proxy auth", Config{User: "admin", Password: "secret123"}, ""},
+		{"proxy and daemon auth", Config{User: "admin", Password: "secret123", ProxyUser: "seedbox", ProxyPassword: "proxypw", ProxyAuthHeader: "Proxy-Authorization"}, ""},
+		{"proxy auth in Authorization without daemon auth", Config{ProxyUser: "seedbox", ProxyPassword: "proxypw", ProxyAuthHeader: "Authorization"}, ""},
+		{"proxy user without password", Config{ProxyUser: "seedbox"}, "proxy password is required"},
+		{"proxy password without user", Config{ProxyPassword: "proxypw"}, "proxy user is required"},
+		{"both in Authorization", Config{User: "admin", Password: "secret123", ProxyUser: "seedbox", ProxyPassword: "proxypw", ProxyAuthHeader: "authorization"}, "cannot use the Authorization header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidateProxyAuth()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateSocket(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		errorMsg string
+	}{
+		{"no socket", Config{}, ""},
+		{"transmission socket", Config{Socket: "/run/transmission/rpc.sock"}, ""},
+		{"relative path", Config{Socket: "rpc.sock"}, "socket path must be absolute"},
+		{"qbittorrent", Config{Client: constants.ClientQBittorrent, Socket: "/run/qbittorrent.sock"}, "only supported for Transmission"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidateSocket()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
 func TestConfig_ValidateDirs(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -322,6 +375,32 @@ func TestConfig_SetDefaults(t *testing.T) {
 		config.SetDefaults()
 		assert.Equal(t, 8080, config.Port)
 	})
+
+	t.Run("qbittorrent defaults", func(t *testing.T) {
+		config := Config{Host: "localhost", Client: constants.ClientQBittorrent}
+
+		config.SetDefaults()
+		assert.Equal(t, constants.DefaultQBittorrentPort, config.Port)
+		assert.Empty(t, config.RPCPath)
+	})
+
+	t.Run("socket without host", func(t *testing.T) {
+		config := Config{Socket: "/run/transmission/rpc.sock"}
+
+		config.SetDefaults()
+		assert.Equal(t, constants.SocketHost, config.Host)
+		assert.NoError(t, config.Validate())
+	})
+}
+
+func TestConfig_ValidateClient(t *testing.T) {
+	for _, name := range []string{"", constants.ClientTransmission, constants.ClientQBittorrent} {
+		config := Config{Client: name}
+		assert.NoError(t, config.ValidateClient(), name)
+	}
+
+	config := Config{Client: "deluge"}
+	assert.ErrorContains(t, config.ValidateClient(), `unsupported client "deluge"`)
 }
 
 func TestValidationError(t *testing.T) {
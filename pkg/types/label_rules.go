@@ -0,0 +1,120 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LabelRule assigns Label to torrents matching all of its conditions
+type LabelRule struct {
+	Label string `yaml:"label"`
+
+	// DownloadDir matches torrents stored in this directory, as Transmission
+	// reports it, or below it
+	DownloadDir string `yaml:"download-dir"`
+
+	// Tracker matches torrents with an announce URL containing this text, ignoring case
+	Tracker string `yaml:"tracker"`
+
+	// Name is a regular expression matched against the torrent name
+	Name string `yaml:"name"`
+
+	namePattern *regexp.Regexp
+}
+
+// LabelRules is a rules file for the autolabel command
+type LabelRules struct {
+	Rules []LabelRule `yaml:"rules"`
+}
+
+// LoadLabelRules reads and validates a YAML rules file. Unknown keys are rejected.
+func LoadLabelRules(path string) (*LabelRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer f.Close()
+
+	rules := &LabelRules{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// compile validates every rule and prepares its name pattern
+func (r *LabelRules) compile() error {
+	var errs []error
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Label == "" {
+			errs = append(errs, fmt.Errorf("rules[%d]: label is required", i))
+		}
+		if rule.DownloadDir == "" && rule.Tracker == "" && rule.Name == "" {
+			errs = append(errs, fmt.Errorf("rules[%d]: needs at least one of download-dir, tracker or name", i))
+		}
+		if rule.Name != "" {
+			pattern, err := regexp.Compile(rule.Name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rules[%d]: invalid name pattern: %w", i, err))
+			}
+			rule.namePattern = pattern
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Matches reports whether t satisfies every condition of the rule
+func (r *LabelRule) Matches(t TorrentInfo) bool {
+	if r.DownloadDir != "" {
+		dir, want := filepath.Clean(t.DownloadDir), filepath.Clean(r.DownloadDir)
+		if dir != want && !strings.HasPrefix(dir, strings.TrimSuffix(want, string(filepath.Separator))+string(filepath.Separator)) {
+			return false
+		}
+	}
+
+	if r.Tracker != "" {
+		found := false
+		for _, tracker := range t.Trackers {
+			if strings.Contains(strings.ToLower(tracker.Announce), strings.ToLower(r.Tracker)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.namePattern != nil && !r.namePattern.MatchString(t.Name) {
+		return false
+	}
+	return true
+}
+
+// LabelsFor returns the labels of every rule matching t, without duplicates
+func (r *LabelRules) LabelsFor(t TorrentInfo) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if !seen[rule.Label] && rule.Matches(t) {
+			labels = append(labels, rule.Label)
+			seen[rule.Label] = true
+		}
+	}
+	return labels
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLabelRules(t *testing.T) {
+	t.Run("valid rules", func(t *testing.T) {
+		path := writeConfig(t, `
+rules:
+  - label: movies
+    download-dir: /downloads/movies
+  - label: linux
+    tracker: tracker.example.org
+    name: (?i)\.iso$
+  - label: hd
+    name: 1080p
+`)
+
+		rules, err := LoadLabelRules(path)
+		require.NoError(t, err)
+		require.Len(t, rules.Rules, 3)
+
+		movie := TorrentInfo{Name: "Film.1080p.mkv", DownloadDir: "/downloads/movies/new"}
+		assert.Equal(t, []string{"movies", "hd"}, rules.LabelsFor(movie))
+
+		iso := TorrentInfo{
+			Name:     "distro.ISO",
+			Trackers: []Tracker{{Announce: "https://Tracker.Example.org/announce"}},
+		}
+		assert.Equal(t, []string{"linux"}, rules.LabelsFor(iso))
+
+		iso.Trackers = nil
+		assert.Empty(t, rules.LabelsFor(iso))
+
+		sibling := TorrentInfo{Name: "x", DownloadDir: "/downloads/movies-old"}
+		assert.Empty(t, rules.LabelsFor(sibling))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		path := writeConfig(t, `
+rules:
+  - download-dir: /downloads
+  - label: empty
+  - label: bad
+    name: "("
+`)
+
+		_, err := LoadLabelRules(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rules[0]: label is required")
+		assert.Contains(t, err.Error(), "rules[1]: needs at least one")
+		assert.Contains(t, err.Error(), "rules[2]: invalid name pattern")
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := LoadLabelRules(writeConfig(t, "rules:\n  - label: x\n    dir: /downloads\n"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PathMapping translates a path prefix as Transmission sees it into the local equivalent
+type PathMapping struct {
+	Remote string `yaml:"remote"`
+	Local  string `yaml:"local"`
+}
+
+// PathMappings is an ordered set of remote-to-local path prefix translations
+type PathMappings []PathMapping
+
+// ParsePathMapping parses a "remote=local" mapping as given on the command line
+func ParsePathMapping(s string) (PathMapping, error) {
+	remote, local, ok := strings.Cut(s, "=")
+	remote, local = strings.TrimSpace(remote), strings.TrimSpace(local)
+	if !ok || remote == "" || local == "" {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q: expected remote=local", s)
+	}
+	return PathMapping{Remote: remote, Local: local}, nil
+}
+
+// ToLocal rewrites a Transmission path using the longest matching remote prefix.
+// Paths that match no mapping are returned cleaned but otherwise unchanged.
+func (m PathMappings) ToLocal(remote string) string {
+	return m.translate(remote, func(p PathMapping) (string, string) { return p.Remote, p.Local })
+}
+
+// ToRemote is the inverse of ToLocal: it rewrites a local path into the path
+// Transmission sees using the longest matching local prefix
+func (m PathMappings) ToRemote(local string) string {
+	return m.translate(local, func(p PathMapping) (string, string) { return p.Local, p.Remote })
+}
+
+// translate replaces the longest prefix of path given by the from side of a
+// mapping with its to side
+func (m PathMappings) translate(path string, sides func(PathMapping) (from, to string)) string {
+	if path == "" {
+		return path
+	}
+
+	path = filepath.Clean(path)
+	best, bestLen := "", -1
+	for _, mapping := range m {
+		from, to := sides(mapping)
+		prefix := filepath.Clean(from)
+		if from == "" || len(prefix) <= bestLen {
+			continue
+		}
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, string(filepath.Separator))+string(filepath.Separator)) {
+			best = filepath.Join(to, strings.TrimPrefix(path, prefix))
+			bestLen = len(prefix)
+		}
+	}
+
+	if bestLen < 0 {
+		return path
+	}
+	return best
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathMappings_ToLocal(t *testing.T) {
+	mappings := PathMappings{
+		{Remote: "/downloads", Local: "/mnt/seedbox/downloads"},
+		{Remote: "/downloads/tv", Local: "/media/tv"},
+	}
+
+	tests := []struct {
+		name     string
+		remote   string
+		expected string
+	}{
+		{"exact prefix", "/downloads", "/mnt/seedbox/downloads"},
+		{"nested path", "/downloads/movies/Film", "/mnt/seedbox/downloads/movies/Film"},
+		{"longest prefix wins", "/downloads/tv/Show", "/media/tv/Show"},
+		{"partial component is not a match", "/downloads-old/x", "/downloads-old/x"},
+		{"unmapped path", "/data/x/", "/data/x"},
+		{"empty path", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mappings.ToLocal(tt.remote))
+		})
+	}
+
+	t.Run("no mappings", func(t *testing.T) {
+		assert.Equal(t, "/downloads/x", PathMappings(nil).ToLocal("/downloads/x"))
+	})
+}
+
+func TestPathMappings_ToRemote(t *testing.T) {
+	mappings := PathMappings{
+		{Remote: "/downloads", Local: "/mnt/seedbox/downloads"},
+		{Remote: "/downloads/tv", Local: "/media/tv"},
+	}
+
+	assert.Equal(t, "/downloads/movies/Film", mappings.ToRemote("/mnt/seedbox/downloads/movies/Film"))
+	assert.Equal(t, "/downloads/tv/Show", mappings.ToRemote("/media/tv/Show"))
+	assert.Equal(t, "/srv/other", mappings.ToRemote("/srv/other/"))
+	for _, remote := range []string{"/downloads/movies", "/downloads/tv/Show"} {
+		assert.Equal(t, remote, mappings.ToRemote(mappings.ToLocal(remote)))
+	}
+}
+
+func TestParsePathMapping(t *testing.T) {
+	mapping, err := ParsePathMapping("/downloads=/mnt/media/downloads")
+	require.NoError(t, err)
+	assert.Equal(t, PathMapping{Remote: "/downloads", Local: "/mnt/media/downloads"}, mapping)
+
+	for _, invalid := range []string{"/downloads", "=/mnt", "/downloads=", ""} {
+		_, err := ParsePathMapping(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadTorrentDump reads saved torrents from path. The file may hold either a
+// JSON array of torrents or a raw torrent-get RPC response.
+func LoadTorrentDump(path string) ([]TorrentInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read torrents file: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var torrents []TorrentInfo
+		if err := json.Unmarshal(trimmed, &torrents); err != nil {
+			return nil, fmt.Errorf("failed to parse torrents file %s: %w", path, err)
+		}
+		return torrents, nil
+	}
+
+	var response TransmissionResponse
+	if err := json.Unmarshal(trimmed, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse torrents file %s: %w", path, err)
+	}
+	if response.Arguments.Torrents == nil {
+		return nil, fmt.Errorf("torrents file %s contains no torrents array", path)
+	}
+	return response.Arguments.Torrents, nil
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTorrentDump(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "dump.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("array", func(t *testing.T) {
+		torrents, err := LoadTorrentDump(write(t, `[{"id": 1, "name": "A"}, {"id": 2, "name": "B"}]`))
+		require.NoError(t, err)
+		require.Len(t, torrents, 2)
+		assert.Equal(t, "B", torrents[1].Name)
+	})
+
+	t.Run("rpc response", func(t *testing.T) {
+		torrents, err := LoadTorrentDump(write(t, `{"arguments": {"torrents": [{"id": 7, "name": "C"}]}, "result": "success"}`))
+		require.NoError(t, err)
+		require.Len(t, torrents, 1)
+		assert.Equal(t, 7, torrents[0].ID)
+	})
+
+	t.Run("object without torrents", func(t *testing.T) {
+		_, err := LoadTorrentDump(write(t, `{"result": "success"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := LoadTorrentDump(write(t, `[{`))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadTorrentDump(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
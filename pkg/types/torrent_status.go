@@ -0,0 +1,69 @@
+package types
+
+import "fmt"
+
+// TorrentStatus represents the status code Transmission reports for a torrent
+type TorrentStatus int
+
+// Transmission torrent status values as defined by the RPC specification
+const (
+	StatusStopped TorrentStatus = iota
+	StatusQueuedVerify
+	StatusVerifying
+	StatusQueuedDownload
+	StatusDownloading
+	StatusQueuedSeed
+	StatusSeeding
+)
+
+// String returns a human-readable name for the status
+func (s TorrentStatus) String() string {
+	switch s {
+	case StatusStopped:
+		return "stopped"
+	case StatusQueuedVerify:
+		return "queued to verify"
+	case StatusVerifying:
+		return "verifying"
+	case StatusQueuedDownload:
+		return "queued to download"
+	case StatusDownloading:
+		return "downloading"
+	case StatusQueuedSeed:
+		return "queued to seed"
+	case StatusSeeding:
+		return "seeding"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(s))
+	}
+}
+
+// IsActive reports whether the torrent is currently downloading or seeding
+func (s TorrentStatus) IsActive() bool {
+	return s == StatusDownloading || s == StatusSeeding
+}
+
+// IsSeeding reports whether the torrent is seeding or queued to seed
+func (s TorrentStatus) IsSeeding() bool {
+	return s == StatusSeeding || s == StatusQueuedSeed
+}
+
+// IsDownloading reports whether the torrent is downloading or queued to download
+func (s TorrentStatus) IsDownloading() bool {
+	return s == StatusDownloading || s == StatusQueuedDownload
+}
+
+// IsQueued reports whether the torrent is waiting in any queue
+func (s TorrentStatus) IsQueued() bool {
+	return s == StatusQueuedVerify || s == StatusQueuedDownload || s == StatusQueuedSeed
+}
+
+// IsVerifying reports whether the torrent is verifying or queued to verify
+func (s TorrentStatus) IsVerifying() bool {
+	return s == StatusVerifying || s == StatusQueuedVerify
+}
+
+// IsStopped reports whether the torrent is stopped
+func (s TorrentStatus) IsStopped() bool {
+	return s == StatusStopped
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTorrentStatus_String(t *testing.T) {
+	tests := []struct {
+		status   TorrentStatus
+		expected string
+	}{
+		{StatusStopped, "stopped"},
+		{StatusQueuedVerify, "queued to verify"},
+		{StatusVerifying, "verifying"},
+		{StatusQueuedDownload, "queued to download"},
+		{StatusDownloading, "downloading"},
+		{StatusQueuedSeed, "queued to seed"},
+		{StatusSeeding, "seeding"},
+		{TorrentStatus(42), "unknown (42)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.String())
+		})
+	}
+}
+
+func TestTorrentStatus_Predicates(t *testing.T) {
+	assert.True(t, StatusDownloading.IsActive())
+	assert.True(t, StatusSeeding.IsActive())
+	assert.False(t, StatusQueuedSeed.IsActive())
+	assert.False(t, StatusStopped.IsActive())
+
+	assert.True(t, StatusSeeding.IsSeeding())
+	assert.True(t, StatusQueuedSeed.IsSeeding())
+	assert.False(t, StatusDownloading.IsSeeding())
+
+	assert.True(t, StatusDownloading.IsDownloading())
+	assert.True(t, StatusQueuedDownload.IsDownloading())
+	assert.False(t, StatusSeeding.IsDownloading())
+
+	assert.True(t, StatusQueuedVerify.IsQueued())
+	assert.True(t, StatusQueuedDownload.IsQueued())
+	assert.True(t, StatusQueuedSeed.IsQueued())
+	assert.False(t, StatusVerifying.IsQueued())
+
+	assert.True(t, StatusVerifying.IsVerifying())
+	assert.True(t, StatusQueuedVerify.IsVerifying())
+	assert.False(t, StatusStopped.IsVerifying())
+
+	assert.True(t, StatusStopped.IsStopped())
+	assert.False(t, StatusSeeding.IsStopped())
+}
+
+func TestTorrentStatus_UnmarshalJSON(t *testing.T) {
+	var torrent TorrentInfo
+	err := json.Unmarshal([]byte(`{"id": 1, "status": 6}`), &torrent)
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusSeeding, torrent.Status)
+}
@@ -1,27 +1,60 @@
 package types
 
+import (
+	"net"
+	"strconv"
+)
+
 type TransmissionRequest struct {
 	Method    string                 `json:"method"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
 }
 
 type TorrentInfo struct {
-	ID             int     `json:"id"`
-	Name           string  `json:"name"`
-	DownloadDir    string  `json:"downloadDir"`
-	HashString     string  `json:"hashString"`
-	TotalSize      int64   `json:"totalSize"`
-	SizeWhenDone   int64   `json:"sizeWhenDone"`
-	LeftUntilDone  int64   `json:"leftUntilDone"`
-	RateDownload   int     `json:"rateDownload"`
-	RateUpload     int     `json:"rateUpload"`
-	PercentDone    float64 `json:"percentDone"`
-	Status         int     `json:"status"`
-	AddedDate      int64   `json:"addedDate"`
-	DoneDate       int64   `json:"doneDate"`
-	UploadedEver   int64   `json:"uploadedEver"`
-	DownloadedEver int64   `json:"downloadedEver"`
-	Ratio          float64 `json:"uploadRatio"`
+	ID              int           `json:"id"`
+	Name            string        `json:"name"`
+	DownloadDir     string        `json:"downloadDir"`
+	HashString      string        `json:"hashString"`
+	TotalSize       int64         `json:"totalSize"`
+	SizeWhenDone    int64         `json:"sizeWhenDone"`
+	LeftUntilDone   int64         `json:"leftUntilDone"`
+	RateDownload    int           `json:"rateDownload"`
+	RateUpload      int           `json:"rateUpload"`
+	PercentDone     float64       `json:"percentDone"`
+	Status          TorrentStatus `json:"status"`
+	AddedDate       int64         `json:"addedDate"`
+	DoneDate        int64         `json:"doneDate"`
+	UploadedEver    int64         `json:"uploadedEver"`
+	DownloadedEver  int64         `json:"downloadedEver"`
+	Ratio           float64       `json:"uploadRatio"`
+	Labels          []string      `json:"labels"`
+	RecheckProgress float64       `json:"recheckProgress"`
+	SecondsSeeding  int64         `json:"secondsSeeding"`
+	IsPrivate       bool          `json:"isPrivate"`
+	Trackers        []Tracker     `json:"trackers,omitempty"`
+	Files           []TorrentFile `json:"files,omitempty"`
+
+	// Swarm details, only fetched by the availability report
+	DesiredAvailable int64 `json:"desiredAvailable,omitempty"`
+	PeersConnected   int   `json:"peersConnected,omitempty"`
+	PeersSendingToUs int   `json:"peersSendingToUs,omitempty"`
+}
+
+// Tracker is one of a torrent's trackers
+type Tracker struct {
+	Announce string `json:"announce"`
+}
+
+// TorrentFile describes a single file inside a torrent
+type TorrentFile struct {
+	Name           string `json:"name"`
+	Length         int64  `json:"length"`
+	BytesCompleted int64  `json:"bytesCompleted"`
+}
+
+// IsComplete reports whether the torrent has finished downloading
+func (t TorrentInfo) IsComplete() bool {
+	return t.PercentDone >= 1.0
 }
 
 type TransmissionResponse struct {
@@ -43,6 +76,16 @@ type SessionInfo struct {
 	AltSpeedEnabled  bool    `json:"alt-speed-enabled"`
 	AltSpeedUp       int     `json:"alt-speed-up"`
 	AltSpeedDown     int     `json:"alt-speed-down"`
+
+	IncompleteDir        string `json:"incomplete-dir"`
+	IncompleteDirEnabled bool   `json:"incomplete-dir-enabled"`
+	RenamePartialFiles   bool   `json:"rename-partial-files"`
+
+	// Version is the daemon's version string; RPCVersion and RPCVersionMinimum
+	// are only reported by Transmission
+	Version           string `json:"version"`
+	RPCVersion        int    `json:"rpc-version"`
+	RPCVersionMinimum int    `json:"rpc-version-minimum"`
 }
 
 // SessionStats contains Transmission session statistics
@@ -69,10 +112,50 @@ type TransmissionStatsResponse struct {
 	Result string `json:"result"`
 }
 
+// TransmissionFreeSpaceResponse represents free-space response
+type TransmissionFreeSpaceResponse struct {
+	Arguments struct {
+		Path      string `json:"path"`
+		SizeBytes int64  `json:"size-bytes"`
+	} `json:"arguments"`
+	Result string `json:"result"`
+}
+
 type Config struct {
+	// Client selects the torrent client backend, Transmission by default
+	Client string
+
 	Host     string
 	Port     int
 	User     string
 	Password string
 	Dirs     []string
+
+	// UseTLS selects https for the RPC endpoint
+	UseTLS bool
+
+	// RPCPath is the RPC endpoint path, defaulting to /transmission/rpc
+	RPCPath string
+
+	// Socket is a Unix domain socket the RPC is reached through instead of a
+	// TCP connection to Host and Port, which then only name the endpoint
+	Socket string
+
+	// ProxyUser and ProxyPassword authenticate against a reverse proxy in front
+	// of the RPC endpoint, sent in ProxyAuthHeader next to the daemon credentials
+	ProxyUser       string
+	ProxyPassword   string
+	ProxyAuthHeader string
+
+	// PathMappings translate Transmission's paths into local ones
+	PathMappings PathMappings
+}
+
+// Endpoint describes where the RPC is reached, for messages: the socket path
+// or host:port
+func (c Config) Endpoint() string {
+	if c.Socket != "" {
+		return c.Socket
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
 }
@@ -135,6 +135,12 @@ func TestConfig_Fields(t *testing.T) {
 	assert.Equal(t, []string{"/downloads", "/media/torrents"}, config.Dirs)
 }
 
+func TestConfig_Endpoint(t *testing.T) {
+	assert.Equal(t, "nas:9091", Config{Host: "nas", Port: 9091}.Endpoint())
+	assert.Equal(t, "[::1]:9091", Config{Host: "::1", Port: 9091}.Endpoint())
+	assert.Equal(t, "/run/transmission/rpc.sock", Config{Host: "localhost", Port: 9091, Socket: "/run/transmission/rpc.sock"}.Endpoint())
+}
+
 func TestConfig_DefaultValues(t *testing.T) {
 	var config Config
 
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DeletionPlanVersion is the format version of deletion plan files
+const DeletionPlanVersion = 1
+
+// DefaultPlanReason is the reason given for items without a recorded one
+const DefaultPlanReason = "not part of any torrent"
+
+// DeletionPlan lists the items a dry run would delete, so the plan can be
+// reviewed and executed later exactly as it was reviewed
+type DeletionPlan struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+
+	// Dirs are the checked directories; every item must stay inside one
+	Dirs []string `json:"dirs"`
+
+	TotalSize int64             `json:"totalSize"`
+	Items     []PlannedDeletion `json:"items"`
+}
+
+// PlannedDeletion is an item of a DeletionPlan as it was when planned
+type PlannedDeletion struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	IsDir  bool   `json:"isDir"`
+	Reason string `json:"reason"`
+}
+
+// PlanChange is a planned item that no longer matches the filesystem
+type PlanChange struct {
+	Item   PlannedDeletion
+	Reason string
+}
+
+// NewDeletionPlan builds a plan from inspected items. Reasons maps paths to
+// why they are deleted; DefaultPlanReason is used for the others. Dirs are
+// stored as absolute paths, so the plan can be applied from anywhere.
+func NewDeletionPlan(dirs []string, operations []*FileOperation, reasons map[string]string) *DeletionPlan {
+	plan := &DeletionPlan{
+		Version: DeletionPlanVersion,
+		Created: time.Now(),
+		Dirs:    make([]string, 0, len(dirs)),
+		Items:   make([]PlannedDeletion, 0, len(operations)),
+	}
+	plan.Host, _ = os.Hostname()
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		plan.Dirs = append(plan.Dirs, dir)
+	}
+
+	for _, op := range operations {
+		reason := reasons[op.Path]
+		if reason == "" {
+			reason = DefaultPlanReason
+		}
+		plan.Items = append(plan.Items, PlannedDeletion{Path: op.Path, Size: op.Size, IsDir: op.IsDir, Reason: reason})
+		plan.TotalSize += op.Size
+	}
+	return plan
+}
+
+// Paths returns the paths of the planned items
+func (p *DeletionPlan) Paths() []string {
+	paths := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		paths[i] = item.Path
+	}
+	return paths
+}
+
+// Verify inspects every planned item again. Items of the same type and size as
+// planned are returned as unchanged; the others, including those that are gone
+// or cannot be sized, are returned as changes.
+func (p *DeletionPlan) Verify() (unchanged []PlannedDeletion, changes []PlanChange) {
+	for _, item := range p.Items {
+		op, err := FileInfo(item.Path)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, PlanChange{Item: item, Reason: "no longer exists"})
+		case err != nil || op.Error != nil:
+			changes = append(changes, PlanChange{Item: item, Reason: "cannot be inspected"})
+		case op.IsDir != item.IsDir:
+			changes = append(changes, PlanChange{Item: item, Reason: "changed between file and directory"})
+		case op.Size != item.Size:
+			changes = append(changes, PlanChange{Item: item, Reason: fmt.Sprintf("size changed from %s to %s", FormatSize(item.Size), FormatSize(op.Size))})
+		default:
+			unchanged = append(unchanged, item)
+		}
+	}
+	return unchanged, changes
+}
+
+// WriteDeletionPlan saves plan as indented JSON
+func WriteDeletionPlan(path string, plan *DeletionPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deletion plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write deletion plan: %w", err)
+	}
+	return nil
+}
+
+// ReadDeletionPlan loads a plan saved by WriteDeletionPlan. Plans of another
+// version, without checked directories or with relative paths are rejected,
+// since their items cannot be validated safely.
+func ReadDeletionPlan(path string) (*DeletionPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deletion plan: %w", err)
+	}
+
+	plan := &DeletionPlan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("failed to parse deletion plan %s: %w", path, err)
+	}
+	if plan.Version != DeletionPlanVersion {
+		return nil, fmt.Errorf("unsupported deletion plan version %d in %s (expected %d)", plan.Version, path, DeletionPlanVersion)
+	}
+	if len(plan.Items) > 0 && len(plan.Dirs) == 0 {
+		return nil, fmt.Errorf("deletion plan %s lists no checked directories", path)
+	}
+	for i, item := range plan.Items {
+		if !filepath.IsAbs(item.Path) {
+			return nil, fmt.Errorf("deletion plan %s: items[%d] has a relative path %q", path, i, item.Path)
+		}
+	}
+	return plan, nil
+}
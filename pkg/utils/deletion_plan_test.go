@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionPlan(t *testing.T) {
+	base := t.TempDir()
+	movie := filepath.Join(base, "Old Movie")
+	require.NoError(t, os.MkdirAll(movie, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(movie, "movie.mkv"), []byte("12345"), 0644))
+	sample := filepath.Join(base, "sample.mkv")
+	require.NoError(t, os.WriteFile(sample, []byte("123"), 0644))
+	gone := filepath.Join(base, "gone.mkv")
+	require.NoError(t, os.WriteFile(gone, []byte("1"), 0644))
+
+	plan := NewDeletionPlan([]string{base}, BatchFileInfo([]string{movie, sample, gone}), map[string]string{movie: `no torrent named "Old Movie"`})
+	assert.Equal(t, int64(9), plan.TotalSize)
+	assert.Equal(t, []string{movie, sample, gone}, plan.Paths())
+	assert.Equal(t, PlannedDeletion{Path: movie, Size: 5, IsDir: true, Reason: `no torrent named "Old Movie"`}, plan.Items[0])
+	assert.Equal(t, DefaultPlanReason, plan.Items[1].Reason)
+
+	path := filepath.Join(base, "plan.json")
+	require.NoError(t, WriteDeletionPlan(path, plan))
+	loaded, err := ReadDeletionPlan(path)
+	require.NoError(t, err)
+	assert.Equal(t, plan.Items, loaded.Items)
+	assert.Equal(t, []string{base}, loaded.Dirs)
+
+	t.Run("verify skips changed items", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(sample, []byte("123456"), 0644))
+		require.NoError(t, os.Remove(gone))
+
+		unchanged, changes := loaded.Verify()
+		require.Len(t, unchanged, 1)
+		assert.Equal(t, movie, unchanged[0].Path)
+		require.Len(t, changes, 2)
+		assert.Equal(t, sample, changes[0].Item.Path)
+		assert.Contains(t, changes[0].Reason, "size changed")
+		assert.Equal(t, "no longer exists", changes[1].Reason)
+	})
+
+	t.Run("invalid plans are rejected", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"version":       `{"version": 2, "dirs": ["/data"], "items": []}`,
+			"no dirs":       `{"version": 1, "items": [{"path": "/data/a"}]}`,
+			"relative path": `{"version": 1, "dirs": ["/data"], "items": [{"path": "data/a"}]}`,
+			"not json":      `plan`,
+		} {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			_, err := ReadDeletionPlan(path)
+			assert.Error(t, err, name)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDirectories reports directories of dirs whose entries were added,
+// removed or renamed, as given in dirs and sorted. A burst of changes, such as
+// a download being moved in, is reported once debounce after the last change;
+// changes made while the receiver is busy are collected into the next report.
+// Writes to existing files are ignored. When the OS drops events, every
+// directory is reported. The channel is closed when ctx ends.
+func WatchDirectories(ctx context.Context, dirs []string, debounce time.Duration) (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start directory watcher: %w", err)
+	}
+
+	// Events name paths below the absolute directory; report the one given
+	watched := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err == nil {
+			err = watcher.Add(abs)
+		}
+		if err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		watched[abs] = dir
+	}
+
+	changes := make(chan []string)
+	go func() {
+		defer close(changes)
+		defer watcher.Close()
+
+		pending := make(map[string]bool)
+		var quiet <-chan time.Time
+		var ready []string
+		var send chan<- []string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+					continue
+				}
+				if dir, ok := watched[filepath.Dir(event.Name)]; ok {
+					pending[dir] = true
+					quiet = time.After(debounce)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if errors.Is(err, fsnotify.ErrEventOverflow) {
+					for _, dir := range watched {
+						pending[dir] = true
+					}
+					quiet = time.After(debounce)
+				}
+
+			case <-quiet:
+				quiet = nil
+				for _, dir := range ready {
+					pending[dir] = true
+				}
+				ready = make([]string, 0, len(pending))
+				for dir := range pending {
+					ready = append(ready, dir)
+				}
+				sort.Strings(ready)
+				pending = make(map[string]bool)
+				if len(ready) > 0 {
+					send = changes
+				}
+
+			case send <- ready:
+				send = nil
+				ready = nil
+			}
+		}
+	}()
+	return changes, nil
+}
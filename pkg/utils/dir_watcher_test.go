@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchDirectories(t *testing.T) {
+	movies, tv := t.TempDir(), t.TempDir()
+	existing := filepath.Join(tv, "show.mkv")
+	require.NoError(t, os.WriteFile(existing, []byte("a"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := WatchDirectories(ctx, []string{movies, tv}, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	next := func() []string {
+		select {
+		case dirs := <-changes:
+			return dirs
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change reported")
+			return nil
+		}
+	}
+
+	t.Run("a burst is reported once", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(movies, "a.mkv"), nil, 0644))
+		require.NoError(t, os.Mkdir(filepath.Join(movies, "Film"), 0755))
+		require.NoError(t, os.Remove(existing))
+		assert.ElementsMatch(t, []string{movies, tv}, next())
+	})
+
+	t.Run("writes to existing files are ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(movies, "a.mkv"), []byte("more"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tv, "new.mkv"), nil, 0644))
+		assert.Equal(t, []string{tv}, next())
+	})
+
+	t.Run("closed when the context ends", func(t *testing.T) {
+		cancel()
+		for range changes {
+		}
+	})
+}
+
+func TestWatchDirectories_Missing(t *testing.T) {
+	_, err := WatchDirectories(context.Background(), []string{filepath.Join(t.TempDir(), "missing")}, time.Millisecond)
+	assert.ErrorContains(t, err, "failed to watch")
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DiskUsage is the space an account uses against its disk quota
+type DiskUsage struct {
+	Used  int64
+	Limit int64
+}
+
+// Free returns the space left before the quota is reached
+func (u DiskUsage) Free() int64 {
+	return max(u.Limit-u.Used, 0)
+}
+
+// Percent returns Used as a percentage of Limit
+func (u DiskUsage) Percent() float64 {
+	if u.Limit <= 0 {
+		return 0
+	}
+	return float64(u.Used) / float64(u.Limit) * 100
+}
+
+// MeasureDiskQuota determines the disk quota usage. With a command, its output
+// gives the used space and optionally the limit, e.g. "812GB 2TB"; otherwise
+// the size of everything below path is used. A non-zero limit overrides the
+// one reported by the command.
+func MeasureDiskQuota(ctx context.Context, command, path string, limit int64, throttle *Throttle) (*DiskUsage, error) {
+	usage := &DiskUsage{Limit: limit}
+
+	switch {
+	case command != "":
+		out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run disk quota command: %w", err)
+		}
+		used, reported, err := parseDiskQuotaOutput(string(out))
+		if err != nil {
+			return nil, err
+		}
+		usage.Used = used
+		if usage.Limit == 0 {
+			usage.Limit = reported
+		}
+	case path != "":
+		info, err := GetSizeInfoThrottled(path, throttle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure disk quota usage: %w", err)
+		}
+		usage.Used = info.Size
+	default:
+		return nil, fmt.Errorf("disk quota needs a command or a path to measure usage")
+	}
+
+	if usage.Limit <= 0 {
+		return nil, fmt.Errorf("disk quota limit is not configured and was not reported by the command")
+	}
+	return usage, nil
+}
+
+// parseDiskQuotaOutput reads "<used> [<limit>]" from a quota command's output.
+// Both accept the sizes ParseSize does; a missing limit is returned as zero.
+func parseDiskQuotaOutput(out string) (int64, int64, error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, fmt.Errorf("invalid disk quota command output %q: expected \"<used> [<limit>]\"", strings.TrimSpace(out))
+	}
+
+	used, err := ParseSize(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid used space in disk quota command output: %w", err)
+	}
+	if len(fields) == 1 {
+		return used, 0, nil
+	}
+	limit, err := ParseSize(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid limit in disk quota command output: %w", err)
+	}
+	return used, limit, nil
+}
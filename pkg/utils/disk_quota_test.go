@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureDiskQuota(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("command reports used and limit", func(t *testing.T) {
+		usage, err := MeasureDiskQuota(ctx, "echo 1GB 4GB", "", 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<30), usage.Used)
+		assert.Equal(t, int64(4<<30), usage.Limit)
+		assert.Equal(t, int64(3<<30), usage.Free())
+		assert.InDelta(t, 25.0, usage.Percent(), 0.001)
+	})
+
+	t.Run("configured limit wins", func(t *testing.T) {
+		usage, err := MeasureDiskQuota(ctx, "echo 100 400", "", 200, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(200), usage.Limit)
+	})
+
+	t.Run("path usage", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.bin"), make([]byte, 300), 0644))
+
+		usage, err := MeasureDiskQuota(ctx, "", dir, 200, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(300), usage.Used)
+		assert.Equal(t, int64(0), usage.Free())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := MeasureDiskQuota(ctx, "echo 100", "", 0, nil)
+		assert.ErrorContains(t, err, "limit")
+
+		_, err = MeasureDiskQuota(ctx, "echo lots", "", 0, nil)
+		assert.ErrorContains(t, err, "invalid used space")
+
+		_, err = MeasureDiskQuota(ctx, "exit 1", "", 100, nil)
+		assert.Error(t, err)
+
+		_, err = MeasureDiskQuota(ctx, "", "", 100, nil)
+		assert.Error(t, err)
+	})
+}
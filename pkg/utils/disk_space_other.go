@@ -0,0 +1,13 @@
+//go:build !unix
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// FreeSpace is only supported on Unix systems, where statfs is available
+func FreeSpace(path string) (int64, error) {
+	return 0, fmt.Errorf("free space detection is not supported on %s", runtime.GOOS)
+}
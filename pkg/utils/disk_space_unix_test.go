@@ -0,0 +1,20 @@
+//go:build unix
+
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeSpace(t *testing.T) {
+	free, err := FreeSpace(t.TempDir())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, free, int64(0))
+
+	_, err = FreeSpace(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
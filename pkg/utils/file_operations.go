@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 )
 
 // FileOperation represents an operation on a file or directory
@@ -12,15 +18,184 @@ type FileOperation struct {
 	Size  int64
 	IsDir bool
 	Error error
+
+	// Inaccessible lists subpaths that could not be sized; Size is then a lower bound
+	Inaccessible []string
+
+	// Reason classifies Error for failed operations
+	Reason FailureReason
+}
+
+// FailureReason categorizes why a file operation failed
+type FailureReason string
+
+// Known failure categories for file operations
+const (
+	FailureNone             FailureReason = ""
+	FailureNotFound         FailureReason = "not found"
+	FailurePermissionDenied FailureReason = "permission denied"
+	FailureImmutable        FailureReason = "operation not permitted (immutable/append-only flag or sticky directory)"
+	FailureReadOnlyFS       FailureReason = "read-only file system"
+	FailureBusy             FailureReason = "file or mount point busy"
+	FailureInUse            FailureReason = "open by another process"
+	FailureCrossDevice      FailureReason = "source and destination are on different filesystems"
+	FailureOther            FailureReason = "other error"
+)
+
+// Hint returns a short suggestion on how to resolve the failure
+func (r FailureReason) Hint() string {
+	switch r {
+	case FailurePermissionDenied:
+		return "check ownership and write permission on the item and its parent directory, or retry with --force-perms"
+	case FailureImmutable:
+		return "clear the immutable attribute (e.g. chattr -i) or check sticky-bit ownership"
+	case FailureReadOnlyFS:
+		return "remount the file system read-write"
+	case FailureBusy:
+		return "close programs using the file or unmount it first"
+	case FailureNotFound:
+		return "the item was already removed"
+	case FailureInUse:
+		return "wait for the process to finish writing, then retry"
+	case FailureCrossDevice:
+		return "use a --staging-dir or --trash-dir on the item's filesystem, or delete it for good with --no-stage"
+	default:
+		return ""
+	}
+}
+
+// Category returns a stable machine-readable identifier for the reason
+func (r FailureReason) Category() string {
+	switch r {
+	case FailureNone:
+		return ""
+	case FailureNotFound:
+		return "not_found"
+	case FailurePermissionDenied:
+		return "permission_denied"
+	case FailureImmutable:
+		return "immutable"
+	case FailureReadOnlyFS:
+		return "read_only_fs"
+	case FailureBusy:
+		return "busy"
+	case FailureInUse:
+		return "in_use"
+	case FailureCrossDevice:
+		return "cross_device"
+	default:
+		return "other"
+	}
+}
+
+// ClassifyError maps a file operation error to a FailureReason
+func ClassifyError(err error) FailureReason {
+	switch {
+	case err == nil:
+		return FailureNone
+	case errors.Is(err, fs.ErrNotExist):
+		return FailureNotFound
+	case errors.Is(err, syscall.EROFS):
+		return FailureReadOnlyFS
+	case errors.Is(err, syscall.EBUSY):
+		return FailureBusy
+	case errors.Is(err, syscall.EXDEV):
+		return FailureCrossDevice
+	case errors.Is(err, syscall.EPERM):
+		return FailureImmutable
+	case errors.Is(err, fs.ErrPermission):
+		return FailurePermissionDenied
+	default:
+		return FailureOther
+	}
+}
+
+// DeleteOptions controls optional deletion behaviour
+type DeleteOptions struct {
+	// ForcePerms makes permission failures retry after granting the owner write
+	// access to the item, its contents and, for the retry only, its parent
+	// directory
+	ForcePerms bool
+
+	// SkipOpen skips items that have files open by any process
+	SkipOpen bool
+
+	// Throttle, when set, rate limits sizing and removal; directories are then
+	// removed entry by entry instead of in one sweep
+	Throttle *Throttle
+
+	// Trash, when set, receives the items instead of them being removed
+	Trash *Trash
+
+	// Stage, when set, receives the items instead of them being removed, until
+	// the batch is restored or purged
+	Stage *StagedBatch
 }
 
 // FileOperationResult tracks the result of file operations
 type FileOperationResult struct {
 	Success      []FileOperation
 	Failed       []FileOperation
+	Skipped      []FileOperation
 	TotalSize    int64
 	SuccessCount int
 	FailedCount  int
+	SkippedCount int
+}
+
+// fileOperationJSON is the serialized form of a FileOperation
+type fileOperationJSON struct {
+	Path         string   `json:"path"`
+	Size         int64    `json:"size"`
+	IsDir        bool     `json:"is_dir"`
+	Error        string   `json:"error,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
+	Inaccessible []string `json:"inaccessible,omitempty"`
+}
+
+// MarshalJSON encodes the operation with its error as text and a stable failure category
+func (op FileOperation) MarshalJSON() ([]byte, error) {
+	out := fileOperationJSON{
+		Path:         op.Path,
+		Size:         op.Size,
+		IsDir:        op.IsDir,
+		Category:     op.Reason.Category(),
+		Reason:       string(op.Reason),
+		Inaccessible: op.Inaccessible,
+	}
+	if op.Error != nil {
+		out.Error = op.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON encodes the result with empty lists instead of null and TotalSize as bytes_freed
+func (r FileOperationResult) MarshalJSON() ([]byte, error) {
+	nonNil := func(ops []FileOperation) []FileOperation {
+		if ops == nil {
+			return []FileOperation{}
+		}
+		return ops
+	}
+
+	return json.Marshal(struct {
+		Success      []FileOperation `json:"success"`
+		Failed       []FileOperation `json:"failed"`
+		Skipped      []FileOperation `json:"skipped"`
+		BytesFreed   int64           `json:"bytes_freed"`
+		SuccessCount int             `json:"success_count"`
+		FailedCount  int             `json:"failed_count"`
+		SkippedCount int             `json:"skipped_count"`
+	}{
+		Success:      nonNil(r.Success),
+		Failed:       nonNil(r.Failed),
+		Skipped:      nonNil(r.Skipped),
+		BytesFreed:   r.TotalSize,
+		SuccessCount: r.SuccessCount,
+		FailedCount:  r.FailedCount,
+		SkippedCount: r.SkippedCount,
+	})
 }
 
 // DeleteProgressCallback is called for each file during deletion
@@ -28,6 +203,11 @@ type DeleteProgressCallback func(current, total int, path string, size int64)
 
 // FileInfo retrieves detailed information about a file or directory
 func FileInfo(path string) (*FileOperation, error) {
+	return fileInfo(path, nil)
+}
+
+// fileInfo is FileInfo with directory sizing rate limited by throttle
+func fileInfo(path string, throttle *Throttle) (*FileOperation, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return &FileOperation{Path: path, Error: err}, err
@@ -41,11 +221,12 @@ func FileInfo(path string) (*FileOperation, error) {
 	if !info.IsDir() {
 		op.Size = info.Size()
 	} else {
-		size, err := GetSize(path)
+		sizeInfo, err := GetSizeInfoThrottled(path, throttle)
 		if err != nil {
 			op.Error = err
 		} else {
-			op.Size = size
+			op.Size = sizeInfo.Size
+			op.Inaccessible = sizeInfo.Inaccessible
 		}
 	}
 
@@ -66,15 +247,40 @@ func BatchFileInfo(paths []string) []*FileOperation {
 
 // DeleteFiles deletes multiple files/directories with progress tracking
 func DeleteFiles(paths []string, progressCallback DeleteProgressCallback) *FileOperationResult {
+	return DeleteFilesWithOptions(paths, DeleteOptions{}, progressCallback)
+}
+
+// DeleteFilesWithOptions deletes multiple files/directories with progress tracking.
+// Failed operations carry a Reason describing the failure category.
+func DeleteFilesWithOptions(paths []string, opts DeleteOptions, progressCallback DeleteProgressCallback) *FileOperationResult {
 	result := &FileOperationResult{
 		Success: make([]FileOperation, 0),
 		Failed:  make([]FileOperation, 0),
+		Skipped: make([]FileOperation, 0),
 	}
 
 	total := len(paths)
 
+	var openFiles map[string][]int
+	if opts.SkipOpen {
+		var err error
+		openFiles, err = FindOpenFiles(paths)
+		if err != nil {
+			// Refuse to delete anything rather than risk removing files in use
+			for _, path := range paths {
+				result.Failed = append(result.Failed, FileOperation{
+					Path:   path,
+					Error:  fmt.Errorf("cannot check for open files: %w", err),
+					Reason: FailureOther,
+				})
+				result.FailedCount++
+			}
+			return result
+		}
+	}
+
 	for i, path := range paths {
-		op, err := FileInfo(path)
+		op, err := fileInfo(path, opts.Throttle)
 
 		if progressCallback != nil {
 			progressCallback(i+1, total, path, op.Size)
@@ -82,20 +288,33 @@ func DeleteFiles(paths []string, progressCallback DeleteProgressCallback) *FileO
 
 		if err != nil {
 			op.Error = err
+			op.Reason = ClassifyError(err)
 			result.Failed = append(result.Failed, *op)
 			result.FailedCount++
 			continue
 		}
 
-		var deleteErr error
-		if op.IsDir {
-			deleteErr = os.RemoveAll(path)
-		} else {
-			deleteErr = os.Remove(path)
+		if pids := openFiles[path]; len(pids) > 0 {
+			op.Error = fmt.Errorf("files open by process(es) %v", pids)
+			op.Reason = FailureInUse
+			result.Skipped = append(result.Skipped, *op)
+			result.SkippedCount++
+			continue
+		}
+
+		deleteErr := deletePath(*op, opts)
+		if deleteErr != nil && opts.ForcePerms && ClassifyError(deleteErr) == FailurePermissionDenied {
+			if restore, permErr := makeWritable(path); permErr == nil {
+				deleteErr = deletePath(*op, opts)
+				if restoreErr := restore(); restoreErr != nil {
+					deleteErr = errors.Join(deleteErr, fmt.Errorf("failed to restore the permissions of %s: %w", filepath.Dir(path), restoreErr))
+				}
+			}
 		}
 
 		if deleteErr != nil {
 			op.Error = deleteErr
+			op.Reason = ClassifyError(deleteErr)
 			result.Failed = append(result.Failed, *op)
 			result.FailedCount++
 		} else {
@@ -108,45 +327,270 @@ func DeleteFiles(paths []string, progressCallback DeleteProgressCallback) *FileO
 	return result
 }
 
-// ValidateDeletionPaths validates paths before deletion
-func ValidateDeletionPaths(paths []string, allowedDirs []string) error {
+// resolvePath returns an absolute, symlink-resolved form of path when possible
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
+
+// isWithin reports whether target is root or located below it
+func isWithin(target, root string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// PruneEmptyParents removes the directories left empty by deleting paths,
+// climbing from each path's parent up to, but never including, the root that
+// contains it. It returns the removed directories; directories that still have
+// entries end the climb silently.
+func PruneEmptyParents(paths []string, roots []string) ([]string, error) {
+	absRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if abs, err := filepath.Abs(root); err == nil {
+			absRoots = append(absRoots, abs)
+		}
+	}
+
+	var removed []string
+	var errs []error
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			if !insideAny(dir, absRoots) {
+				break
+			}
+			entries, err := os.ReadDir(dir)
+			if errors.Is(err, fs.ErrNotExist) {
+				// Already pruned while handling an earlier path
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to read %s: %w", dir, err))
+				break
+			}
+			if len(entries) > 0 {
+				break
+			}
+			if err := os.Remove(dir); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove empty directory %s: %w", dir, err))
+				break
+			}
+			removed = append(removed, dir)
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// insideAny reports whether path lies strictly below one of roots
+func insideAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if path != root && isWithin(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePath moves the item of op into opts.Trash or opts.Stage when set, and
+// removes it otherwise
+func deletePath(op FileOperation, opts DeleteOptions) error {
+	switch {
+	case opts.Trash != nil:
+		_, err := opts.Trash.Move(op.Path, time.Now())
+		return err
+	case opts.Stage != nil:
+		return opts.Stage.Move(op)
+	}
+	return removePath(op.Path, op.IsDir, opts.Throttle)
+}
+
+// removePath removes a file, or a directory with all its contents
+func removePath(path string, isDir bool, throttle *Throttle) error {
+	if isDir && throttle != nil {
+		return removeThrottled(path, throttle)
+	}
+	if isDir {
+		return os.RemoveAll(path)
+	}
+	throttle.Wait()
+	return os.Remove(path)
+}
+
+// removeThrottled removes the tree at path one entry at a time, deepest first,
+// waiting on throttle before each removal
+func removeThrottled(path string, throttle *Throttle) error {
+	var entries []string
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		entries = append(entries, p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// WalkDir lists parents before their children, so reverse order empties
+	// each directory before removing it
+	for i := len(entries) - 1; i >= 0; i-- {
+		throttle.Wait()
+		if err := os.Remove(entries[i]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeWritable grants the owner write access to path's parent directory, to path
+// itself and, for directories, to every directory below it. The returned
+// function restores the original mode of the parent, which is usually a
+// checked directory, once the item is removed.
+func makeWritable(path string) (func() error, error) {
+	parent := filepath.Dir(path)
+	parentInfo, err := os.Lstat(parent)
+	if err != nil {
+		return nil, err
+	}
+	if err := addMode(parent, 0700); err != nil {
+		return nil, err
+	}
+	restore := func() error {
+		if parentInfo.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return os.Chmod(parent, parentInfo.Mode().Perm())
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, errors.Join(err, restore())
+	}
+	if !info.IsDir() {
+		if err := addMode(path, 0200); err != nil {
+			return nil, errors.Join(err, restore())
+		}
+		return restore, nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if d != nil && d.IsDir() {
+			// Fix the directory before WalkDir reads it, so unreadable dirs can be entered
+			if chmodErr := addMode(p, 0700); chmodErr != nil {
+				return chmodErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Join(err, restore())
+	}
+	return restore, nil
+}
+
+// addMode adds permission bits to a path's current mode
+func addMode(path string, bits os.FileMode) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	return os.Chmod(path, info.Mode().Perm()|bits)
+}
+
+// ValidateDeletionPaths validates paths before deletion. Besides system paths,
+// paths with fewer than minDepth components are refused. When allowedDirs is
+// set, each path must lie strictly inside one of them, judged by real paths so
+// a symlinked parent cannot lead a deletion outside the checked directories.
+func ValidateDeletionPaths(paths []string, allowedDirs []string, minDepth int) error {
+	boundaries := make([]string, 0, len(allowedDirs))
+	for _, allowedDir := range allowedDirs {
+		absAllowedDir, err := filepath.Abs(allowedDir)
+		if err != nil {
+			return fmt.Errorf("invalid allowed directory %s: %w", allowedDir, err)
+		}
+		boundaries = append(boundaries, realPath(absAllowedDir))
+	}
+
 	for _, path := range paths {
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			return fmt.Errorf("invalid path %s: %w", path, err)
 		}
 
-		// Check if path is within allowed directories
-		if len(allowedDirs) > 0 {
+		// The item itself is removed, never followed, so only its parent is resolved
+		resolved := filepath.Join(realPath(filepath.Dir(absPath)), filepath.Base(absPath))
+
+		if len(boundaries) > 0 {
 			allowed := false
-			for _, allowedDir := range allowedDirs {
-				absAllowedDir, err := filepath.Abs(allowedDir)
-				if err != nil {
-					continue
+			for _, boundary := range boundaries {
+				if resolved == boundary {
+					return fmt.Errorf("refusing to delete %s: it is one of the checked directories", path)
 				}
-
-				rel, err := filepath.Rel(absAllowedDir, absPath)
-				if err == nil && !filepath.IsAbs(rel) && len(rel) > 0 && rel[0] != '.' {
+				if isWithin(resolved, boundary) {
 					allowed = true
 					break
 				}
 			}
 
 			if !allowed {
+				if resolved != absPath {
+					return fmt.Errorf("path %s resolves to %s, which is not within allowed directories", path, resolved)
+				}
 				return fmt.Errorf("path %s is not within allowed directories", path)
 			}
 		}
 
 		// Prevent deletion of system directories
-		if isSystemPath(absPath) {
-			return fmt.Errorf("refusing to delete system path: %s", absPath)
+		for _, candidate := range []string{absPath, resolved} {
+			if isSystemPath(candidate) {
+				return fmt.Errorf("refusing to delete system path: %s", candidate)
+			}
+		}
+		if depth := pathDepth(absPath); depth < minDepth {
+			return fmt.Errorf("refusing to delete %s: it has %d path components, the minimum is %d", absPath, depth, minDepth)
 		}
 	}
 
 	return nil
 }
 
-// isSystemPath checks if a path is a critical system directory
+// realPath resolves the symlinks in an absolute path. The longest existing
+// prefix is resolved and the rest is appended unchanged, so paths that are
+// already gone still get a meaningful answer.
+func realPath(absPath string) string {
+	missing := ""
+	for current := absPath; ; {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(resolved, missing)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return absPath
+		}
+		missing = filepath.Join(filepath.Base(current), missing)
+		current = parent
+	}
+}
+
+// isSystemPath checks if a path is a critical system directory, the user's
+// home directory or the root of a mounted filesystem
 func isSystemPath(path string) bool {
 	systemPaths := []string{
 		"/", "/bin", "/boot", "/dev", "/etc", "/lib", "/lib64",
@@ -165,21 +609,44 @@ func isSystemPath(path string) bool {
 		}
 	}
 
+	if home, err := os.UserHomeDir(); err == nil && absPath == filepath.Clean(home) {
+		return true
+	}
+	if mount, err := MountPoint(absPath); err == nil && mount == absPath {
+		return true
+	}
+
 	return false
 }
 
-// CalculateTotalSize calculates total size for a list of paths
+// pathDepth counts the components of an absolute path below its root
+func pathDepth(absPath string) int {
+	rest := strings.TrimPrefix(absPath, filepath.VolumeName(absPath))
+	depth := 0
+	for _, part := range strings.Split(filepath.ToSlash(rest), "/") {
+		if part != "" {
+			depth++
+		}
+	}
+	return depth
+}
+
+// CalculateTotalSize calculates total size for a list of paths. Items that could only
+// be partially sized contribute their partial size and are counted as inaccessible.
 func CalculateTotalSize(paths []string) (int64, int, error) {
 	var totalSize int64
 	var inaccessible int
 
 	for _, path := range paths {
-		size, err := GetSize(path)
+		info, err := GetSizeInfo(path)
 		if err != nil {
 			inaccessible++
 			continue
 		}
-		totalSize += size
+		totalSize += info.Size
+		if info.Incomplete() {
+			inaccessible++
+		}
 	}
 
 	return totalSize, inaccessible, nil
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"peerless/pkg/constants"
 )
 
 func TestFileInfo(t *testing.T) {
@@ -195,6 +199,113 @@ func TestDeleteFiles(t *testing.T) {
 	})
 }
 
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected FailureReason
+	}{
+		{"nil", nil, FailureNone},
+		{"not found", &os.PathError{Op: "remove", Path: "/x", Err: syscall.ENOENT}, FailureNotFound},
+		{"permission denied", &os.PathError{Op: "remove", Path: "/x", Err: syscall.EACCES}, FailurePermissionDenied},
+		{"immutable", &os.PathError{Op: "remove", Path: "/x", Err: syscall.EPERM}, FailureImmutable},
+		{"read-only fs", &os.PathError{Op: "remove", Path: "/x", Err: syscall.EROFS}, FailureReadOnlyFS},
+		{"busy", &os.PathError{Op: "remove", Path: "/x", Err: syscall.EBUSY}, FailureBusy},
+		{"cross device", &os.LinkError{Op: "link", Old: "/x", New: "/y", Err: syscall.EXDEV}, FailureCrossDevice},
+		{"other", errors.New("boom"), FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestFileOperationResult_MarshalJSON(t *testing.T) {
+	result := FileOperationResult{
+		Success: []FileOperation{{Path: "/data/a", Size: 100}},
+		Failed: []FileOperation{{
+			Path:   "/data/b",
+			Size:   50,
+			IsDir:  true,
+			Error:  &os.PathError{Op: "remove", Path: "/data/b", Err: syscall.EACCES},
+			Reason: FailurePermissionDenied,
+		}},
+		TotalSize:    100,
+		SuccessCount: 1,
+		FailedCount:  1,
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, float64(100), decoded["bytes_freed"])
+	assert.Equal(t, float64(1), decoded["success_count"])
+	assert.Equal(t, []interface{}{}, decoded["skipped"])
+
+	failed := decoded["failed"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "/data/b", failed["path"])
+	assert.Equal(t, true, failed["is_dir"])
+	assert.Equal(t, "permission_denied", failed["category"])
+	assert.Equal(t, "remove /data/b: permission denied", failed["error"])
+
+	success := decoded["success"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, success, "error")
+	assert.NotContains(t, success, "category")
+}
+
+func TestDeleteFilesWithOptions(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission checks do not apply to root")
+	}
+
+	setup := func(t *testing.T) (string, string) {
+		tmpDir := t.TempDir()
+		locked := filepath.Join(tmpDir, "locked")
+		require.NoError(t, os.MkdirAll(filepath.Join(locked, "inner"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(locked, "inner", "file.txt"), []byte("x"), 0644))
+		require.NoError(t, os.Chmod(filepath.Join(locked, "inner"), 0500))
+		t.Cleanup(func() { os.Chmod(filepath.Join(locked, "inner"), 0755) })
+		return tmpDir, locked
+	}
+
+	t.Run("permission failure is classified", func(t *testing.T) {
+		_, locked := setup(t)
+
+		result := DeleteFilesWithOptions([]string{locked}, DeleteOptions{}, nil)
+		require.Equal(t, 1, result.FailedCount)
+		assert.Equal(t, FailurePermissionDenied, result.Failed[0].Reason)
+	})
+
+	t.Run("force perms retries after chmod", func(t *testing.T) {
+		_, locked := setup(t)
+
+		result := DeleteFilesWithOptions([]string{locked}, DeleteOptions{ForcePerms: true}, nil)
+		assert.Equal(t, 0, result.FailedCount)
+		assert.Equal(t, 1, result.SuccessCount)
+
+		_, err := os.Stat(locked)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("force perms restores the parent's mode", func(t *testing.T) {
+		tmpDir, locked := setup(t)
+		require.NoError(t, os.Chmod(tmpDir, 0550))
+		t.Cleanup(func() { os.Chmod(tmpDir, 0755) })
+
+		result := DeleteFilesWithOptions([]string{locked}, DeleteOptions{ForcePerms: true}, nil)
+		assert.Equal(t, 1, result.SuccessCount)
+		assert.NoDirExists(t, locked)
+		info, err := os.Stat(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0550), info.Mode().Perm())
+	})
+}
+
 func TestValidateDeletionPaths(t *testing.T) {
 	t.Run("valid paths", func(t *testing.T) {
 		// Create temporary directory
@@ -209,7 +320,7 @@ func TestValidateDeletionPaths(t *testing.T) {
 		paths := []string{file}
 		allowedDirs := []string{tmpDir}
 
-		err = ValidateDeletionPaths(paths, allowedDirs)
+		err = ValidateDeletionPaths(paths, allowedDirs, constants.DefaultMinDeleteDepth)
 		assert.NoError(t, err)
 	})
 
@@ -225,7 +336,7 @@ func TestValidateDeletionPaths(t *testing.T) {
 		paths := []string{file}
 		allowedDirs := []string{"/some/other/dir"}
 
-		err = ValidateDeletionPaths(paths, allowedDirs)
+		err = ValidateDeletionPaths(paths, allowedDirs, constants.DefaultMinDeleteDepth)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not within allowed directories")
 	})
@@ -240,7 +351,7 @@ func TestValidateDeletionPaths(t *testing.T) {
 		for _, path := range systemPaths {
 			t.Run("system path "+path, func(t *testing.T) {
 				paths := []string{path}
-				err := ValidateDeletionPaths(paths, nil)
+				err := ValidateDeletionPaths(paths, nil, constants.DefaultMinDeleteDepth)
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), "refusing to delete system path")
 			})
@@ -254,7 +365,7 @@ func TestValidateDeletionPaths(t *testing.T) {
 		defer os.Remove(tmpFile.Name())
 
 		paths := []string{tmpFile.Name()}
-		err = ValidateDeletionPaths(paths, nil)
+		err = ValidateDeletionPaths(paths, nil, constants.DefaultMinDeleteDepth)
 		assert.NoError(t, err) // Should allow any path when no allowed dirs specified
 	})
 }
@@ -306,6 +417,98 @@ func TestCalculateTotalSize(t *testing.T) {
 	})
 }
 
+func TestValidateDeletionPaths_Boundaries(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "downloads")
+	outside := filepath.Join(root, "elsewhere")
+	require.NoError(t, os.MkdirAll(filepath.Join(allowed, ".hidden"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(outside, "victim"), 0755))
+
+	t.Run("dot-prefixed child", func(t *testing.T) {
+		assert.NoError(t, ValidateDeletionPaths([]string{filepath.Join(allowed, ".hidden")}, []string{allowed}, 0))
+		assert.NoError(t, ValidateDeletionPaths([]string{filepath.Join(allowed, "..movie")}, []string{allowed}, 0))
+	})
+
+	t.Run("allowed directory itself", func(t *testing.T) {
+		err := ValidateDeletionPaths([]string{allowed + string(filepath.Separator)}, []string{allowed}, 0)
+		assert.ErrorContains(t, err, "one of the checked directories")
+	})
+
+	t.Run("sibling sharing a prefix", func(t *testing.T) {
+		err := ValidateDeletionPaths([]string{allowed + "-old"}, []string{allowed}, 0)
+		assert.ErrorContains(t, err, "not within allowed directories")
+	})
+
+	t.Run("parent traversal", func(t *testing.T) {
+		err := ValidateDeletionPaths([]string{filepath.Join(allowed, "..", "elsewhere")}, []string{allowed}, 0)
+		assert.ErrorContains(t, err, "not within allowed directories")
+	})
+
+	t.Run("symlinked parent escapes", func(t *testing.T) {
+		if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+		err := ValidateDeletionPaths([]string{filepath.Join(allowed, "link", "victim")}, []string{allowed}, 0)
+		assert.ErrorContains(t, err, "resolves to "+filepath.Join(outside, "victim"))
+	})
+
+	t.Run("symlink item itself is allowed", func(t *testing.T) {
+		if _, err := os.Lstat(filepath.Join(allowed, "link")); err != nil {
+			t.Skip("symlinks unavailable")
+		}
+		// Deleting a symlink removes the link, not its target
+		assert.NoError(t, ValidateDeletionPaths([]string{filepath.Join(allowed, "link")}, []string{allowed}, 0))
+	})
+
+	t.Run("allowed directory reached through a symlink", func(t *testing.T) {
+		alias := filepath.Join(root, "alias")
+		if err := os.Symlink(allowed, alias); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+		assert.NoError(t, ValidateDeletionPaths([]string{filepath.Join(allowed, ".hidden")}, []string{alias}, 0))
+		assert.NoError(t, ValidateDeletionPaths([]string{filepath.Join(alias, "gone", "file")}, []string{allowed}, 0))
+	})
+}
+
+func TestPruneEmptyParents(t *testing.T) {
+	root := t.TempDir()
+	deleted := filepath.Join(root, "Show", "Season 1", "episode.mkv")
+	sibling := filepath.Join(root, "Other", "Extras", "clip.mkv")
+	kept := filepath.Join(root, "Other", "keep.mkv")
+	for _, dir := range []string{filepath.Dir(deleted), filepath.Dir(sibling)} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	require.NoError(t, os.WriteFile(kept, []byte("x"), 0644))
+
+	removed, err := PruneEmptyParents([]string{deleted, sibling}, []string{root})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "Show", "Season 1"),
+		filepath.Join(root, "Show"),
+		filepath.Join(root, "Other", "Extras"),
+	}, removed)
+
+	assert.DirExists(t, root)
+	assert.FileExists(t, kept)
+	assert.NoDirExists(t, filepath.Join(root, "Show"))
+
+	t.Run("outside roots", func(t *testing.T) {
+		other := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(other, "empty"), 0755))
+		removed, err := PruneEmptyParents([]string{filepath.Join(other, "empty", "gone")}, []string{root})
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+		assert.DirExists(t, filepath.Join(other, "empty"))
+	})
+}
+
+func TestValidateDeletionPaths_MinDepth(t *testing.T) {
+	err := ValidateDeletionPaths([]string{"/mnt/media"}, nil, 3)
+	assert.ErrorContains(t, err, "minimum is 3")
+
+	assert.NoError(t, ValidateDeletionPaths([]string{"/mnt/media/movies"}, nil, 3))
+}
+
 func TestIsSystemPath(t *testing.T) {
 	tests := []struct {
 		path     string
@@ -316,8 +519,9 @@ func TestIsSystemPath(t *testing.T) {
 		{"/usr", true},
 		{"/etc", true},
 		{"/home/user", false},
-		{"/tmp", false},
+		{"/tmp/peerless-missing", false},
 		{"relative/path", false},
+		{"/proc", true},
 	}
 
 	for _, tt := range tests {
@@ -1,91 +1,364 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"peerless/pkg/constants"
 )
 
-func GetSize(path string) (int64, error) {
+// SizeInfo contains the size of a path and any subpaths that could not be read
+type SizeInfo struct {
+	Size         int64
+	Inaccessible []string
+}
+
+// Incomplete reports whether some subpaths were skipped, making Size a lower bound
+func (si *SizeInfo) Incomplete() bool {
+	return len(si.Inaccessible) > 0
+}
+
+// GetSizeInfo calculates the size of a file or directory, continuing past unreadable
+// subpaths and recording each of them in Inaccessible. An error is only returned when
+// the path itself cannot be accessed.
+func GetSizeInfo(path string) (*SizeInfo, error) {
+	return GetSizeInfoThrottled(path, nil)
+}
+
+// GetSizeInfoThrottled is GetSizeInfo with every path visited rate limited by throttle
+func GetSizeInfoThrottled(path string, throttle *Throttle) (*SizeInfo, error) {
+	throttle.Wait()
 	info, err := os.Stat(path)
 	if err != nil {
-		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
 
+	result := &SizeInfo{Inaccessible: make([]string, 0)}
 	if !info.IsDir() {
-		return info.Size(), nil
+		result.Size = info.Size()
+		return result, nil
 	}
 
-	var totalSize int64
-	var walkErr error
-
 	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if p != path {
+			throttle.Wait()
+		}
 		if err != nil {
-			// Log but don't fail entirely - collect the error but continue walking
-			walkErr = fmt.Errorf("error accessing %s: %w", p, err)
+			// Record and keep walking; a failed directory read is reported once here
+			result.Inaccessible = append(result.Inaccessible, p)
 			return nil
 		}
 		if !d.IsDir() {
 			fileInfo, err := d.Info()
-			if err == nil {
-				totalSize += fileInfo.Size()
+			if err != nil {
+				result.Inaccessible = append(result.Inaccessible, p)
+				return nil
 			}
+			result.Size += fileInfo.Size()
 		}
 		return nil
 	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
 
+// GetSize calculates the size of a file or directory. When some subpaths cannot be
+// read the partial size is returned together with an error listing all of them.
+func GetSize(path string) (int64, error) {
+	info, err := GetSizeInfo(path)
 	if err != nil {
-		return totalSize, err
+		return 0, err
 	}
 
-	// Return any walk errors that occurred but don't fail if we have some size data
-	if walkErr != nil {
-		return totalSize, walkErr
+	if info.Incomplete() {
+		return info.Size, fmt.Errorf("incomplete size for %s: %d inaccessible paths: %s",
+			path, len(info.Inaccessible), strings.Join(info.Inaccessible, ", "))
 	}
 
-	return totalSize, nil
+	return info.Size, nil
 }
 
-func FormatSize(bytes int64) string {
-	if bytes < constants.BytesPerKB {
-		return fmt.Sprintf("%d B", bytes)
+// ParseSize parses a human-readable size such as "500MB" or "1.5 GB" into bytes
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	if str == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	multipliers := []struct {
+		suffix string
+		value  int64
+	}{
+		{"PB", constants.BytesPerPB},
+		{"TB", constants.BytesPerTB},
+		{"GB", constants.BytesPerGB},
+		{"MB", constants.BytesPerMB},
+		{"KB", constants.BytesPerKB},
+		{"B", 1},
 	}
 
-	div, exp := int64(constants.BytesPerKB), 0
-	for n := bytes / constants.BytesPerKB; n >= constants.BytesPerKB; n /= constants.BytesPerKB {
-		div *= constants.BytesPerKB
-		exp++
+	multiplier := int64(1)
+	for _, m := range multipliers {
+		if strings.HasSuffix(str, m.suffix) {
+			multiplier = m.value
+			str = strings.TrimSpace(strings.TrimSuffix(str, m.suffix))
+			break
+		}
 	}
 
-	units := []string{"KB", "MB", "GB", "TB", "PB"}
-	return fmt.Sprintf("%.2f %s", float64(bytes)/float64(div), units[exp])
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(value * float64(multiplier)), nil
 }
 
+// ParseAge parses an age such as "60d", "2w" or "12h" into a duration
+func ParseAge(s string) (time.Duration, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(str, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(str, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	if unit == 0 {
+		d, err := time.ParseDuration(str)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return d, nil
+	}
+
+	n, err := strconv.Atoi(str[:len(str)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ParseIDList parses a comma-separated list of IDs and ranges such as "1,3-5"
+func ParseIDList(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(last)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid ID range %q", part)
+			}
+		}
+
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Page returns the window of items starting at offset and holding at most
+// limit items; a limit of zero means no limit
+func Page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// Output modes for missing path files
+const (
+	OutputOverwrite   = "overwrite"
+	OutputAppend      = "append"
+	OutputTimestamped = "timestamped"
+)
+
+// ValidateOutputMode checks that mode is a supported output mode
+func ValidateOutputMode(mode string) error {
+	switch mode {
+	case OutputOverwrite, OutputAppend, OutputTimestamped:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output mode %q (use %s, %s or %s)", mode, OutputOverwrite, OutputAppend, OutputTimestamped)
+	}
+}
+
+// TimestampedName inserts the time before the extension of filename, so
+// missing.txt becomes missing-20260301-043000.txt
+func TimestampedName(filename string, t time.Time) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "-" + t.Format(constants.OutputTimestampFormat) + ext
+}
+
+// RelativePaths returns each path relative to the deepest of dirs containing
+// it. Paths outside all dirs are returned unchanged.
+func RelativePaths(paths []string, dirs []string) []string {
+	relative := make([]string, len(paths))
+	for i, path := range paths {
+		relative[i] = path
+		if _, rel, ok := containingDir(path, dirs); ok {
+			relative[i] = rel
+		}
+	}
+	return relative
+}
+
+// containingDir returns the deepest of dirs containing path and path relative to it
+func containingDir(path string, dirs []string) (string, string, bool) {
+	best, bestRel := "", ""
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(dir) > len(best) {
+			best, bestRel = dir, rel
+		}
+	}
+	return best, bestRel, best != ""
+}
+
+// WriteMissingPaths writes paths to filename, one per line, replacing any existing content
 func WriteMissingPaths(filename string, paths []string) error {
-	file, err := os.Create(filename)
+	_, err := WriteMissingPathsMode(filename, paths, OutputOverwrite, time.Now())
+	return err
+}
+
+// WriteMissingPathsMode writes paths to filename according to mode: overwrite
+// truncates the file, append adds to its end and timestamped writes a new file
+// named by TimestampedName with now. The path actually written is returned.
+func WriteMissingPathsMode(filename string, paths []string, mode string, now time.Time) (string, error) {
+	file, filename, err := openOutputFile(filename, mode, now)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filename, err)
+		return "", err
 	}
 	defer file.Close()
 
 	for _, path := range paths {
 		cleanPath := SanitizeString(path)
 		if _, err := file.WriteString(cleanPath + "\n"); err != nil {
-			return fmt.Errorf("failed to write path %s to file %s: %w", path, filename, err)
+			return "", fmt.Errorf("failed to write path %s to file %s: %w", path, filename, err)
 		}
 	}
 
 	// Ensure all data is flushed to disk
 	if err := file.Sync(); err != nil {
-		return fmt.Errorf("failed to sync file %s: %w", filename, err)
+		return "", fmt.Errorf("failed to sync file %s: %w", filename, err)
 	}
 
-	return nil
+	return filename, nil
+}
+
+// openOutputFile opens filename for writing according to mode and returns the
+// file together with the name actually opened
+func openOutputFile(filename string, mode string, now time.Time) (*os.File, string, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	switch mode {
+	case OutputOverwrite:
+		flags |= os.O_TRUNC
+	case OutputAppend:
+		flags |= os.O_APPEND
+	case OutputTimestamped:
+		filename = TimestampedName(filename, now)
+		flags |= os.O_EXCL
+	default:
+		return nil, "", ValidateOutputMode(mode)
+	}
+
+	file, err := os.OpenFile(filename, flags, 0644)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file %s: %w", filename, err)
+	}
+	return file, filename, nil
+}
+
+// ValidateDirectories checks that every directory exists, is a directory and is readable.
+// All problems are collected and returned together rather than stopping at the first one.
+func ValidateDirectories(dirs []string) error {
+	var problems []error
+
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				problems = append(problems, fmt.Errorf("%s: directory does not exist", dir))
+			} else {
+				problems = append(problems, fmt.Errorf("%s: %w", dir, err))
+			}
+			continue
+		}
+
+		if !info.IsDir() {
+			problems = append(problems, fmt.Errorf("%s: not a directory", dir))
+			continue
+		}
+
+		f, err := os.Open(dir)
+		if err == nil {
+			_, err = f.Readdirnames(1)
+			f.Close()
+		}
+		if err != nil && err != io.EOF {
+			problems = append(problems, fmt.Errorf("%s: directory is not readable: %w", dir, err))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// ForEachDirEntry calls fn with the entries of dir in batches of at most
+// constants.DirScanBatchSize, each sorted by name, so that huge directories
+// are never held in memory at once. Directories smaller than a batch are
+// therefore listed in order, like os.ReadDir.
+func ForEachDirEntry(dir string, fn func([]os.DirEntry) error) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		entries, err := f.ReadDir(constants.DirScanBatchSize)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		if err := fn(entries); err != nil {
+			return err
+		}
+	}
 }
 
 // NormalizeName normalizes a name for comparison based on OS case sensitivity
@@ -125,6 +398,9 @@ func SanitizeString(s string) string {
 type DirectoryInfo struct {
 	Path  string
 	Count int
+
+	// TotalSize is the combined totalSize of the directory's torrents
+	TotalSize int64
 }
 
 // WriteDirectoryList writes a list of directories to a file
@@ -1,13 +1,18 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/constants"
 )
 
 func TestGetSize(t *testing.T) {
@@ -66,30 +71,6 @@ func TestGetSize(t *testing.T) {
 	})
 }
 
-func TestFormatSize(t *testing.T) {
-	tests := []struct {
-		name     string
-		bytes    int64
-		expected string
-	}{
-		{"bytes", 512, "512 B"},
-		{"kilobytes", 1024, "1.00 KB"},
-		{"megabytes", 1024 * 1024, "1.00 MB"},
-		{"gigabytes", 1024 * 1024 * 1024, "1.00 GB"},
-		{"terabytes", 1024 * 1024 * 1024 * 1024, "1.00 TB"},
-		{"petabytes", 1024 * 1024 * 1024 * 1024 * 1024, "1.00 PB"},
-		{"fractional", 1536, "1.50 KB"},
-		{"zero", 0, "0 B"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := FormatSize(tt.bytes)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestPortValidation(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -242,3 +223,301 @@ func TestWriteMissingPaths(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestWriteMissingPathsMode(t *testing.T) {
+	now := time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC)
+
+	t.Run("overwrite replaces content", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "missing.txt")
+		require.NoError(t, os.WriteFile(outputFile, []byte("/old\n"), 0644))
+
+		written, err := WriteMissingPathsMode(outputFile, []string{"/new"}, OutputOverwrite, now)
+		require.NoError(t, err)
+		assert.Equal(t, outputFile, written)
+
+		content, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		assert.Equal(t, "/new\n", string(content))
+	})
+
+	t.Run("append keeps earlier runs", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "missing.txt")
+
+		_, err := WriteMissingPathsMode(outputFile, []string{"/first"}, OutputAppend, now)
+		require.NoError(t, err)
+		_, err = WriteMissingPathsMode(outputFile, []string{"/second"}, OutputAppend, now)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		assert.Equal(t, "/first\n/second\n", string(content))
+	})
+
+	t.Run("timestamped writes a new file per run", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		outputFile := filepath.Join(tmpDir, "missing.txt")
+
+		written, err := WriteMissingPathsMode(outputFile, []string{"/a"}, OutputTimestamped, now)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, "missing-20260301-043000.txt"), written)
+		assert.NoFileExists(t, outputFile)
+
+		// An archive from the same second is never overwritten
+		_, err = WriteMissingPathsMode(outputFile, []string{"/b"}, OutputTimestamped, now)
+		assert.ErrorIs(t, err, os.ErrExist)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := WriteMissingPathsMode(filepath.Join(t.TempDir(), "missing.txt"), nil, "rotate", now)
+		assert.ErrorContains(t, err, "unsupported output mode")
+	})
+}
+
+func TestTimestampedName(t *testing.T) {
+	now := time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC)
+	assert.Equal(t, "/tmp/missing-20260301-043000.txt", TimestampedName("/tmp/missing.txt", now))
+	assert.Equal(t, "report-20260301-043000", TimestampedName("report", now))
+}
+
+func TestRelativePaths(t *testing.T) {
+	movies := filepath.Join("/media", "movies")
+	extras := filepath.Join(movies, "extras")
+	paths := []string{
+		filepath.Join(movies, "A.mkv"),
+		filepath.Join(extras, "B", "b.mkv"),
+		filepath.Join("/other", "C.mkv"),
+		filepath.Join("/media", "moviesX", "D.mkv"),
+	}
+
+	assert.Equal(t, []string{
+		"A.mkv",
+		filepath.Join("B", "b.mkv"),
+		filepath.Join("/other", "C.mkv"),
+		filepath.Join("/media", "moviesX", "D.mkv"),
+	}, RelativePaths(paths, []string{movies + string(filepath.Separator), extras}))
+}
+
+func TestValidateDirectories(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_validate_dirs_")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	filePath := filepath.Join(tmpDir, "file.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("x"), 0644))
+
+	t.Run("valid directories", func(t *testing.T) {
+		assert.NoError(t, ValidateDirectories([]string{tmpDir}))
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		assert.NoError(t, ValidateDirectories(nil))
+	})
+
+	t.Run("aggregates all problems", func(t *testing.T) {
+		missing := filepath.Join(tmpDir, "missing")
+
+		err := ValidateDirectories([]string{missing, tmpDir, filePath})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), missing+": directory does not exist")
+		assert.Contains(t, err.Error(), filePath+": not a directory")
+	})
+
+	t.Run("unreadable directory", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("permission checks do not apply to root")
+		}
+
+		locked := filepath.Join(tmpDir, "locked")
+		require.NoError(t, os.Mkdir(locked, 0000))
+		defer os.Chmod(locked, 0755)
+
+		err := ValidateDirectories([]string{locked})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not readable")
+	})
+}
+
+func TestForEachDirEntry(t *testing.T) {
+	dir := t.TempDir()
+	total := constants.DirScanBatchSize + 2
+	for i := 0; i < total; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("item-%05d", i)), nil, 0644))
+	}
+
+	var batches []int
+	seen := make(map[string]bool)
+	err := ForEachDirEntry(dir, func(entries []os.DirEntry) error {
+		batches = append(batches, len(entries))
+		assert.True(t, sort.SliceIsSorted(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() }))
+		for _, entry := range entries {
+			seen[entry.Name()] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{constants.DirScanBatchSize, 2}, batches)
+	assert.Len(t, seen, total)
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		calls := 0
+		err := ForEachDirEntry(dir, func([]os.DirEntry) error {
+			calls++
+			return errors.New("stop")
+		})
+		assert.EqualError(t, err, "stop")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		assert.Error(t, ForEachDirEntry(filepath.Join(dir, "missing"), func([]os.DirEntry) error { return nil }))
+	})
+}
+
+func TestGetSizeInfo(t *testing.T) {
+	t.Run("complete directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("Hello"), 0644))
+
+		info, err := GetSizeInfo(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), info.Size)
+		assert.False(t, info.Incomplete())
+	})
+
+	t.Run("collects every inaccessible subpath", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("permission checks do not apply to root")
+		}
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("Hello"), 0644))
+
+		locked1 := filepath.Join(tmpDir, "locked1")
+		locked2 := filepath.Join(tmpDir, "locked2")
+		for _, dir := range []string{locked1, locked2} {
+			require.NoError(t, os.Mkdir(dir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("World"), 0644))
+			require.NoError(t, os.Chmod(dir, 0000))
+			defer os.Chmod(dir, 0755)
+		}
+
+		info, err := GetSizeInfo(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), info.Size)
+		assert.True(t, info.Incomplete())
+		assert.ElementsMatch(t, []string{locked1, locked2}, info.Inaccessible)
+
+		size, err := GetSize(tmpDir)
+		assert.Equal(t, int64(5), size)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), locked1)
+		assert.Contains(t, err.Error(), locked2)
+	})
+
+	t.Run("non-existent path", func(t *testing.T) {
+		info, err := GetSizeInfo("/non/existent/path")
+		assert.Error(t, err)
+		assert.Nil(t, info)
+	})
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"10B", 10},
+		{"1KB", 1024},
+		{"500MB", 500 * 1024 * 1024},
+		{"1.5 GB", 1536 * 1024 * 1024},
+		{"2tb", 2 * 1024 * 1024 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := ParseSize(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+
+	for _, invalid := range []string{"", "GB", "-1MB", "ten"} {
+		_, err := ParseSize(invalid)
+		assert.Error(t, err, "input %q", invalid)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"60d", 60 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			age, err := ParseAge(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, age)
+		})
+	}
+
+	for _, invalid := range []string{"", "d", "-3d", "soon"} {
+		_, err := ParseAge(invalid)
+		assert.Error(t, err, "input %q", invalid)
+	}
+}
+
+func TestParseIDList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"5", []int{5}},
+		{"1,3", []int{1, 3}},
+		{"2-4", []int{2, 3, 4}},
+		{"1, 3-4 ,9", []int{1, 3, 4, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ids, err := ParseIDList(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	for _, invalid := range []string{"", "0", "a", "4-2", "1,", "1-x"} {
+		_, err := ParseIDList(invalid)
+		assert.Error(t, err, "input %q", invalid)
+	}
+}
+
+func TestPage(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		expected      []string
+	}{
+		{"no paging", 0, 0, items},
+		{"limit", 0, 2, []string{"a", "b"}},
+		{"offset", 3, 0, []string{"d", "e"}},
+		{"offset and limit", 1, 2, []string{"b", "c"}},
+		{"limit past end", 4, 10, []string{"e"}},
+		{"offset past end", 9, 2, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Page(items, tt.offset, tt.limit))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"peerless/pkg/constants"
+)
+
+// rawSizes makes FormatSize and FormatSpeed print exact byte counts instead of scaled units
+var rawSizes bool
+
+// SetRawSizes selects exact byte counts (true) or scaled units for FormatSize and FormatSpeed
+func SetRawSizes(raw bool) {
+	rawSizes = raw
+}
+
+// FormatSize formats bytes with the largest fitting unit, or as an exact
+// byte count when SetRawSizes(true) was called
+func FormatSize(bytes int64) string {
+	if rawSizes || bytes < constants.BytesPerKB {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value, unit := scale(bytes)
+	return fmt.Sprintf("%.2f %s", value, unit)
+}
+
+// FormatSpeed formats a transfer rate like FormatSize, with one decimal
+func FormatSpeed(bytesPerSecond int64) string {
+	if rawSizes || bytesPerSecond < constants.BytesPerKB {
+		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	}
+
+	value, unit := scale(bytesPerSecond)
+	return fmt.Sprintf("%.1f %s/s", value, unit)
+}
+
+// scale divides bytes by the largest fitting binary unit, at least KB
+func scale(bytes int64) (float64, string) {
+	div, exp := int64(constants.BytesPerKB), 0
+	for n := bytes / constants.BytesPerKB; n >= constants.BytesPerKB && exp < len(constants.SizeUnits)/2-1; n /= constants.BytesPerKB {
+		div *= constants.BytesPerKB
+		exp++
+	}
+	return float64(bytes) / float64(div), constants.SizeUnits[exp*2 : exp*2+2]
+}
+
+// FormatDuration formats d as its two or three largest units, e.g. "3d 4h 12m",
+// "2h 5m 0s" or "42s"
+func FormatDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	if seconds <= 0 {
+		return "0s"
+	}
+
+	days := seconds / 86400
+	hours := (seconds % 86400) / 3600
+	minutes := (seconds % 3600) / 60
+	secs := seconds % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm %ds", hours, minutes, secs)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, secs)
+	default:
+		return fmt.Sprintf("%ds", secs)
+	}
+}
+
+// FormatRelativeTime describes t relative to now in its largest unit, e.g.
+// "3d ago", "5h ago" or "in 2m"
+func FormatRelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix, prefix := " ago", ""
+	if d < 0 {
+		d, suffix, prefix = -d, "", "in "
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int64(d/time.Minute))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int64(d/time.Hour))
+	default:
+		amount = fmt.Sprintf("%dd", int64(d/(24*time.Hour)))
+	}
+	return prefix + amount + suffix
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    int64
+		expected string
+	}{
+		{"bytes", 512, "512 B"},
+		{"kilobytes", 1024, "1.00 KB"},
+		{"megabytes", 1024 * 1024, "1.00 MB"},
+		{"gigabytes", 1024 * 1024 * 1024, "1.00 GB"},
+		{"terabytes", 1024 * 1024 * 1024 * 1024, "1.00 TB"},
+		{"petabytes", 1024 * 1024 * 1024 * 1024 * 1024, "1.00 PB"},
+		{"fractional", 1536, "1.50 KB"},
+		{"zero", 0, "0 B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatSize(tt.bytes)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("raw bytes", func(t *testing.T) {
+		SetRawSizes(true)
+		defer SetRawSizes(false)
+		assert.Equal(t, "1610612736 B", FormatSize(1610612736))
+		assert.Equal(t, "512 B", FormatSize(512))
+	})
+}
+
+func TestFormatSpeed(t *testing.T) {
+	assert.Equal(t, "0 B/s", FormatSpeed(0))
+	assert.Equal(t, "512 B/s", FormatSpeed(512))
+	assert.Equal(t, "1.5 KB/s", FormatSpeed(1536))
+	assert.Equal(t, "2.0 MB/s", FormatSpeed(2*1024*1024))
+
+	SetRawSizes(true)
+	defer SetRawSizes(false)
+	assert.Equal(t, "2097152 B/s", FormatSpeed(2*1024*1024))
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{42 * time.Second, "42s"},
+		{5*time.Minute + 3*time.Second, "5m 3s"},
+		{2*time.Hour + 5*time.Minute, "2h 5m 0s"},
+		{3*24*time.Hour + 4*time.Hour + 12*time.Minute + 9*time.Second, "3d 4h 12m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatDuration(tt.d))
+		})
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "just now", FormatRelativeTime(now.Add(-30*time.Second), now))
+	assert.Equal(t, "5m ago", FormatRelativeTime(now.Add(-5*time.Minute), now))
+	assert.Equal(t, "3h ago", FormatRelativeTime(now.Add(-3*time.Hour-20*time.Minute), now))
+	assert.Equal(t, "12d ago", FormatRelativeTime(now.Add(-12*24*time.Hour), now))
+	assert.Equal(t, "in 2h", FormatRelativeTime(now.Add(2*time.Hour), now))
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// LinkResult summarises the files handled by LinkTree
+type LinkResult struct {
+	Linked   int // files newly hardlinked
+	Copied   int // files copied because src and dst are on different filesystems
+	Existing int // files already linked at the destination
+
+	// Conflicts lists destination paths holding a different file; they are left untouched
+	Conflicts []string
+}
+
+// Add accumulates the counts of other into r
+func (r *LinkResult) Add(other *LinkResult) {
+	r.Linked += other.Linked
+	r.Copied += other.Copied
+	r.Existing += other.Existing
+	r.Conflicts = append(r.Conflicts, other.Conflicts...)
+}
+
+// LinkTree hardlinks the file or directory tree at src to dst, creating
+// directories as needed. Existing destination files are never overwritten:
+// hardlinks to the same file count as Existing, anything else as a conflict.
+// When linking fails because dst is on another filesystem, files are copied if
+// copyFallback is set and the error, classified as FailureCrossDevice, is
+// returned otherwise.
+func LinkTree(src, dst string, copyFallback bool) (*LinkResult, error) {
+	result := &LinkResult{}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		return linkFile(path, target, copyFallback, result)
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to link %s to %s: %w", src, dst, err)
+	}
+	return result, nil
+}
+
+// linkFile hardlinks src to dst, recording the outcome in result
+func linkFile(src, dst string, copyFallback bool, result *LinkResult) error {
+	err := os.Link(src, dst)
+	switch {
+	case err == nil:
+		result.Linked++
+		return nil
+
+	case errors.Is(err, fs.ErrExist):
+		srcInfo, srcErr := os.Stat(src)
+		dstInfo, dstErr := os.Stat(dst)
+		if srcErr == nil && dstErr == nil && os.SameFile(srcInfo, dstInfo) {
+			result.Existing++
+		} else {
+			result.Conflicts = append(result.Conflicts, dst)
+		}
+		return nil
+
+	case errors.Is(err, syscall.EXDEV):
+		if !copyFallback {
+			return err
+		}
+		if err := copyFile(src, dst); err != nil {
+			return err
+		}
+		result.Copied++
+		return nil
+
+	default:
+		return err
+	}
+}
+
+// copyFile copies src to a new file dst, keeping its permissions
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
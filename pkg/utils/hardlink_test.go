@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkTree(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "downloads", "Show")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "Season 1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "info.nfo"), []byte("nfo"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Season 1", "e01.mkv"), []byte("video"), 0644))
+
+	dst := filepath.Join(root, "library", "Show")
+
+	t.Run("links every file", func(t *testing.T) {
+		result, err := LinkTree(src, dst, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Linked)
+
+		srcInfo, err := os.Stat(filepath.Join(src, "Season 1", "e01.mkv"))
+		require.NoError(t, err)
+		dstInfo, err := os.Stat(filepath.Join(dst, "Season 1", "e01.mkv"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(srcInfo, dstInfo))
+	})
+
+	t.Run("rerun finds existing links and conflicts", func(t *testing.T) {
+		conflict := filepath.Join(dst, "info.nfo")
+		require.NoError(t, os.Remove(conflict))
+		require.NoError(t, os.WriteFile(conflict, []byte("other"), 0644))
+
+		result, err := LinkTree(src, dst, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Linked)
+		assert.Equal(t, 1, result.Existing)
+		assert.Equal(t, []string{conflict}, result.Conflicts)
+
+		content, err := os.ReadFile(conflict)
+		require.NoError(t, err)
+		assert.Equal(t, "other", string(content))
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		file := filepath.Join(root, "downloads", "movie.mkv")
+		require.NoError(t, os.WriteFile(file, []byte("movie"), 0644))
+
+		result, err := LinkTree(file, filepath.Join(root, "library", "Movies", "movie.mkv"), false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Linked)
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		_, err := LinkTree(filepath.Join(root, "missing"), filepath.Join(root, "library", "missing"), false)
+		assert.Error(t, err)
+	})
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("data"), 0640))
+
+	require.NoError(t, copyFile(src, dst))
+	content, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	assert.Error(t, copyFile(src, dst), "existing destinations must not be overwritten")
+}
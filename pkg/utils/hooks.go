@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// HookPlaceholder in a hook command is replaced by the hook's argument
+const HookPlaceholder = "{}"
+
+// HookCommand returns command with every {} replaced by arg quoted for the
+// shell. Without a placeholder the argument is appended.
+func HookCommand(command, arg string) string {
+	quoted := ShellQuote(arg)
+	if !strings.Contains(command, HookPlaceholder) {
+		return command + " " + quoted
+	}
+	return strings.ReplaceAll(command, HookPlaceholder, quoted)
+}
+
+// RunHook runs command through sh with arg substituted as by HookCommand,
+// passing its output on to stdout and stderr
+func RunHook(ctx context.Context, command, arg string, stdout, stderr io.Writer) error {
+	hook := exec.CommandContext(ctx, "sh", "-c", HookCommand(command, arg))
+	hook.Stdout, hook.Stderr = stdout, stderr
+	if err := hook.Run(); err != nil {
+		return fmt.Errorf("failed to run hook for %s: %w", arg, err)
+	}
+	return nil
+}
+
+// ShellQuote quotes s as a single sh word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookCommand(t *testing.T) {
+	assert.Equal(t, "mv '/data/a b' /trash", HookCommand("mv {} /trash", "/data/a b"))
+	assert.Equal(t, "cp '/x' '/x'.bak", HookCommand("cp {} {}.bak", "/x"))
+	assert.Equal(t, "notify-send '/out.txt'", HookCommand("notify-send", "/out.txt"))
+	assert.Equal(t, `echo '/it'\''s'`, HookCommand("echo {}", "/it's"))
+}
+
+func TestRunHook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("passes the argument as one word", func(t *testing.T) {
+		var stdout bytes.Buffer
+		require.NoError(t, RunHook(ctx, `printf '%s|' {}`, "a b; echo injected", &stdout, nil))
+		assert.Equal(t, "a b; echo injected|", stdout.String())
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		var stderr bytes.Buffer
+		err := RunHook(ctx, "echo oops >&2; test -z {}", "/x", nil, &stderr)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to run hook for /x")
+		assert.Equal(t, "oops\n", stderr.String())
+	})
+}
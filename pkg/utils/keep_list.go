@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeepList holds the patterns of a keep file: local-only content that is never
+// reported missing or deleted, whatever Transmission has. A nil KeepList keeps
+// nothing.
+type KeepList struct {
+	patterns []string
+}
+
+// LoadKeepList reads a keep file, one path or glob per line. Blank lines and
+// lines starting with # are ignored.
+func LoadKeepList(path string) (*KeepList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keep file: %w", err)
+	}
+	defer f.Close()
+
+	keep, err := ParseKeepList(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keep file %s: %w", path, err)
+	}
+	return keep, nil
+}
+
+// ParseKeepList parses keep file patterns from r. Absolute patterns match full
+// paths, patterns containing a separator match paths relative to the checked
+// directory, and bare patterns match the name of any path component.
+func ParseKeepList(r io.Reader) (*KeepList, error) {
+	keep := &KeepList{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		pattern = filepath.Clean(pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("line %d: bad pattern %q: %w", line, pattern, err)
+		}
+		keep.patterns = append(keep.patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keep file: %w", err)
+	}
+	return keep, nil
+}
+
+// Len returns the number of patterns
+func (k *KeepList) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.patterns)
+}
+
+// Keeps reports whether absPath, found in the checked directory root, or any
+// of its parents below root matches a pattern. Keeping a directory therefore
+// keeps everything inside it.
+func (k *KeepList) Keeps(absPath, root string) bool {
+	if k.Len() == 0 {
+		return false
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+
+	for current := filepath.Clean(absPath); current != absRoot; {
+		rel, err := filepath.Rel(absRoot, current)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			// Outside root: only absolute patterns can apply
+			rel = ""
+		}
+		for _, pattern := range k.patterns {
+			if keepMatch(pattern, current, rel) {
+				return true
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	return false
+}
+
+// keepMatch matches one pattern against a path given absolutely and relative
+// to the checked directory
+func keepMatch(pattern, absPath, rel string) bool {
+	var target string
+	switch {
+	case filepath.IsAbs(pattern):
+		target = absPath
+	case strings.ContainsRune(pattern, filepath.Separator):
+		target = rel
+	default:
+		target = filepath.Base(absPath)
+	}
+	if target == "" {
+		return false
+	}
+	matched, _ := filepath.Match(pattern, target)
+	return matched
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepList_Keeps(t *testing.T) {
+	root := filepath.FromSlash("/data/downloads")
+	keep, err := ParseKeepList(strings.NewReader(strings.Join([]string{
+		"# family videos are local only",
+		"",
+		filepath.FromSlash("/data/downloads/Family Videos"),
+		filepath.FromSlash("Personal/*"),
+		"*.keep",
+	}, "\n")))
+	require.NoError(t, err)
+	assert.Equal(t, 3, keep.Len())
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/data/downloads/Family Videos", true},
+		{"/data/downloads/Family Videos/2019/beach.mp4", true},
+		{"/data/downloads/Personal/notes", true},
+		{"/data/downloads/Personal", false},
+		{"/data/downloads/Old.Movie.2019", false},
+		{"/data/downloads/Show/notes.keep", true},
+		{"/data/downloads/backup.keep/file", true},
+		{"/data/other/Personal/notes", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, keep.Keeps(filepath.FromSlash(tt.path), root))
+		})
+	}
+
+	t.Run("nil keeps nothing", func(t *testing.T) {
+		var empty *KeepList
+		assert.False(t, empty.Keeps(filepath.FromSlash("/data/downloads/x"), root))
+	})
+}
+
+func TestLoadKeepList(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid", func(t *testing.T) {
+		path := filepath.Join(dir, "keep.txt")
+		require.NoError(t, os.WriteFile(path, []byte("Family Videos\n*.iso\n"), 0644))
+		keep, err := LoadKeepList(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2, keep.Len())
+	})
+
+	t.Run("bad pattern", func(t *testing.T) {
+		path := filepath.Join(dir, "bad.txt")
+		require.NoError(t, os.WriteFile(path, []byte("ok\n[unclosed\n"), 0644))
+		_, err := LoadKeepList(path)
+		assert.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadKeepList(filepath.Join(dir, "absent.txt"))
+		assert.ErrorContains(t, err, "failed to open keep file")
+	})
+}
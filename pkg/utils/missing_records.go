@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats for missing path files
+const (
+	OutputFormatText = "text"
+	OutputFormatTSV  = "tsv"
+)
+
+// ValidateOutputFormat checks that format is a supported output file format
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputFormatText, OutputFormatTSV:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (use %s or %s)", format, OutputFormatText, OutputFormatTSV)
+	}
+}
+
+// tsvHeader names the columns written by WriteMissingRecords
+const tsvHeader = "path\tsize\ttype\tmtime\tsource_dir"
+
+// MissingRecord holds a missing path together with the metadata needed to triage it
+type MissingRecord struct {
+	Path      string
+	Size      int64
+	IsDir     bool
+	ModTime   time.Time
+	SourceDir string
+
+	// Err is set when the path could not be inspected; the other fields are then empty
+	Err error
+}
+
+// Type returns "dir", "file" or "unknown" when the path could not be inspected
+func (r MissingRecord) Type() string {
+	switch {
+	case r.Err != nil:
+		return "unknown"
+	case r.IsDir:
+		return "dir"
+	default:
+		return "file"
+	}
+}
+
+// MissingRecords inspects each path, sizing directories recursively, and
+// assigns it the deepest of dirs containing it as its source directory
+func MissingRecords(paths []string, dirs []string, throttle *Throttle) []MissingRecord {
+	records := make([]MissingRecord, len(paths))
+	for i, path := range paths {
+		record := MissingRecord{Path: path}
+		record.SourceDir, _, _ = containingDir(path, dirs)
+
+		throttle.Wait()
+		info, err := os.Lstat(path)
+		if err != nil {
+			record.Err = err
+			records[i] = record
+			continue
+		}
+		record.IsDir = info.IsDir()
+		record.ModTime = info.ModTime()
+		record.Size = info.Size()
+		if record.IsDir {
+			if sizeInfo, err := GetSizeInfoThrottled(path, throttle); err == nil {
+				record.Size = sizeInfo.Size
+			}
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// WriteMissingRecords writes records to filename as tab-separated values
+// according to mode (see WriteMissingPathsMode). A header row is written when
+// the file starts out empty. The path actually written is returned.
+func WriteMissingRecords(filename string, records []MissingRecord, mode string, now time.Time) (string, error) {
+	file, filename, err := openOutputFile(filename, mode, now)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var b strings.Builder
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		b.WriteString(tsvHeader + "\n")
+	}
+	for _, r := range records {
+		mtime := ""
+		if r.Err == nil {
+			mtime = r.ModTime.Format(time.RFC3339)
+		}
+		fields := []string{
+			tsvField(r.Path),
+			strconv.FormatInt(r.Size, 10),
+			r.Type(),
+			mtime,
+			tsvField(r.SourceDir),
+		}
+		b.WriteString(strings.Join(fields, "\t") + "\n")
+	}
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		return "", fmt.Errorf("failed to write records to file %s: %w", filename, err)
+	}
+	if err := file.Sync(); err != nil {
+		return "", fmt.Errorf("failed to sync file %s: %w", filename, err)
+	}
+	return filename, nil
+}
+
+// tsvField sanitizes s and escapes the characters that would break a TSV row
+func tsvField(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(SanitizeString(s))
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingRecords(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.mkv")
+	sub := filepath.Join(dir, "show")
+	require.NoError(t, os.WriteFile(file, make([]byte, 10), 0644))
+	require.NoError(t, os.Mkdir(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "e1.mkv"), make([]byte, 5), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "e2.mkv"), make([]byte, 7), 0644))
+	gone := filepath.Join(dir, "gone")
+
+	records := MissingRecords([]string{file, sub, gone}, []string{dir}, nil)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, int64(10), records[0].Size)
+	assert.Equal(t, "file", records[0].Type())
+	assert.Equal(t, dir, records[0].SourceDir)
+	assert.False(t, records[0].ModTime.IsZero())
+
+	assert.Equal(t, int64(12), records[1].Size)
+	assert.Equal(t, "dir", records[1].Type())
+
+	assert.Error(t, records[2].Err)
+	assert.Equal(t, "unknown", records[2].Type())
+}
+
+func TestWriteMissingRecords(t *testing.T) {
+	now := time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC)
+	records := []MissingRecord{
+		{Path: "/m/a\tb.mkv", Size: 10, ModTime: now, SourceDir: "/m"},
+		{Path: "/m/show", Size: 12, IsDir: true, ModTime: now, SourceDir: "/m"},
+		{Path: "/m/gone", Err: os.ErrNotExist, SourceDir: "/m"},
+	}
+
+	t.Run("header and escaped rows", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "missing.tsv")
+		written, err := WriteMissingRecords(filename, records, OutputOverwrite, now)
+		require.NoError(t, err)
+		assert.Equal(t, filename, written)
+
+		content, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.Equal(t, "path\tsize\ttype\tmtime\tsource_dir\n"+
+			"/m/a\\tb.mkv\t10\tfile\t2026-03-01T04:30:00Z\t/m\n"+
+			"/m/show\t12\tdir\t2026-03-01T04:30:00Z\t/m\n"+
+			"/m/gone\t0\tunknown\t\t/m\n", string(content))
+	})
+
+	t.Run("append writes the header once", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "missing.tsv")
+		_, err := WriteMissingRecords(filename, records[:1], OutputAppend, now)
+		require.NoError(t, err)
+		_, err = WriteMissingRecords(filename, records[1:2], OutputAppend, now)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(string(content), "path\tsize"))
+		assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 3)
+	})
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, ValidateOutputFormat(OutputFormatText))
+	assert.NoError(t, ValidateOutputFormat(OutputFormatTSV))
+	assert.ErrorContains(t, ValidateOutputFormat("csv"), "unsupported output format")
+}
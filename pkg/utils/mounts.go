@@ -0,0 +1,53 @@
+package utils
+
+import "sort"
+
+// MountGroup subtotals the directories stored on one filesystem
+type MountGroup struct {
+	// MountPoint is empty for directories whose filesystem could not be determined
+	MountPoint string
+	Dirs       []DirectoryInfo
+	Count      int
+	TotalSize  int64
+
+	// Free is the space available on the filesystem, or -1 when unknown
+	Free int64
+}
+
+// GroupByMount groups dirs by the mount point of their filesystem. localPath
+// translates a directory into the local path to inspect. Groups are sorted by
+// mount point, with the undetermined group last.
+func GroupByMount(dirs []DirectoryInfo, localPath func(string) string) []MountGroup {
+	index := make(map[string]int)
+	groups := make([]MountGroup, 0)
+	for _, d := range dirs {
+		mount, err := MountPoint(localPath(d.Path))
+		if err != nil {
+			mount = ""
+		}
+
+		i, ok := index[mount]
+		if !ok {
+			i = len(groups)
+			index[mount] = i
+			groups = append(groups, MountGroup{MountPoint: mount, Free: -1})
+			if mount != "" {
+				if free, err := FreeSpace(mount); err == nil {
+					groups[i].Free = free
+				}
+			}
+		}
+
+		groups[i].Dirs = append(groups[i].Dirs, d)
+		groups[i].Count += d.Count
+		groups[i].TotalSize += d.TotalSize
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].MountPoint == "") != (groups[j].MountPoint == "") {
+			return groups[j].MountPoint == ""
+		}
+		return groups[i].MountPoint < groups[j].MountPoint
+	})
+	return groups
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// MountPoint is only supported on Unix systems, where device IDs are available
+func MountPoint(path string) (string, error) {
+	return "", fmt.Errorf("mount point detection is not supported on %s", runtime.GOOS)
+}
+
+// SameFilesystem is only supported on Unix systems, where device IDs are available
+func SameFilesystem(a, b string) (bool, error) {
+	return false, fmt.Errorf("filesystem detection is not supported on %s", runtime.GOOS)
+}
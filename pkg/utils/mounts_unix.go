@@ -0,0 +1,58 @@
+//go:build unix
+
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+)
+
+// MountPoint returns the mount point of the filesystem holding path: the
+// topmost ancestor of the resolved path still on the same device
+func MountPoint(path string) (string, error) {
+	resolved, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if resolved, err = filepath.EvalSymlinks(resolved); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	dev, err := deviceOf(resolved)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(resolved)
+		if parent == resolved {
+			return resolved, nil
+		}
+		if parentDev, err := deviceOf(parent); err != nil || parentDev != dev {
+			return resolved, nil
+		}
+		resolved = parent
+	}
+}
+
+// SameFilesystem reports whether the existing paths a and b are on the same filesystem
+func SameFilesystem(a, b string) (bool, error) {
+	devA, err := deviceOf(a)
+	if err != nil {
+		return false, err
+	}
+	devB, err := deviceOf(b)
+	if err != nil {
+		return false, err
+	}
+	return devA == devB, nil
+}
+
+// deviceOf returns the ID of the device holding path
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return uint64(st.Dev), nil
+}
@@ -0,0 +1,68 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountPoint(t *testing.T) {
+	dir := t.TempDir()
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	mount, err := MountPoint(dir)
+	require.NoError(t, err)
+	assert.True(t, mount == "/" || resolved == mount || strings.HasPrefix(resolved, mount+"/"),
+		"%s should contain %s", mount, resolved)
+
+	root, err := MountPoint("/")
+	require.NoError(t, err)
+	assert.Equal(t, "/", root)
+
+	_, err = MountPoint(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestGroupByMount(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(base, "movies"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(base, "tv"), 0755))
+
+	dirs := []DirectoryInfo{
+		{Path: "/remote/movies", Count: 2, TotalSize: 100},
+		{Path: "/remote/gone", Count: 1, TotalSize: 5},
+		{Path: "/remote/tv", Count: 3, TotalSize: 50},
+	}
+	local := func(p string) string { return strings.Replace(p, "/remote", base, 1) }
+
+	groups := GroupByMount(dirs, local)
+	require.Len(t, groups, 2)
+
+	assert.NotEmpty(t, groups[0].MountPoint)
+	assert.Equal(t, 5, groups[0].Count)
+	assert.Equal(t, int64(150), groups[0].TotalSize)
+	assert.Len(t, groups[0].Dirs, 2)
+	assert.GreaterOrEqual(t, groups[0].Free, int64(0))
+
+	// Directories missing locally cannot be placed on a filesystem
+	assert.Equal(t, "", groups[1].MountPoint)
+	assert.Equal(t, int64(-1), groups[1].Free)
+	assert.Equal(t, []DirectoryInfo{dirs[1]}, groups[1].Dirs)
+}
+
+func TestIsSystemPath_HomeAndMounts(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	assert.True(t, isSystemPath(home))
+
+	mount, err := MountPoint(t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, isSystemPath(mount))
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"peerless/pkg/constants"
+)
+
+// Filesystem operations of moves; replaced in tests to simulate other filesystems
+var (
+	rename         = os.Rename
+	sameFilesystem = SameFilesystem
+	mountPoint     = MountPoint
+	freeSpace      = FreeSpace
+)
+
+// IsDeletedItemsDir reports whether name is a directory in which staging or a
+// volume trash keeps deleted items at the root of a filesystem, which listings
+// of directories leave out
+func IsDeletedItemsDir(name string) bool {
+	return name == constants.StagingDirName || name == ".Trash" || strings.HasPrefix(name, ".Trash-")
+}
+
+// moveItem moves src to dst, which must not exist. Within a filesystem it is a
+// rename. Across filesystems the item is copied, keeping permissions and
+// modification times, and src is removed once the copy is complete; a failed
+// copy is removed again, and none is started without the space for it. When
+// only removing src fails, the complete copy is kept at dst and the error
+// returned, so callers can tell that case apart by dst existing.
+func moveItem(src, dst string) error {
+	err := rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return &os.LinkError{Op: "move", Old: src, New: dst, Err: fs.ErrExist}
+	}
+
+	size, err := GetSize(src)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s across filesystems: %w", src, err)
+	}
+	free, err := freeSpace(filepath.Dir(dst))
+	if err != nil {
+		return fmt.Errorf("failed to copy %s across filesystems: %w", src, err)
+	}
+	if size > free {
+		return fmt.Errorf("cannot copy %s across filesystems: it needs %s but %s has only %s free",
+			src, FormatSize(size), filepath.Dir(dst), FormatSize(free))
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to copy %s across filesystems: %w", src, err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("copied %s to %s but failed to remove it: %w", src, dst, err)
+	}
+	return nil
+}
+
+// copyTree copies the file, directory or symlink src to dst. Directories get
+// their permissions and times once their contents are copied, so read-only
+// directories can be filled first.
+func copyTree(src, dst string) error {
+	type dirAttrs struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []dirAttrs
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			if err := os.Mkdir(target, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirAttrs{target, info})
+		case mode&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case mode.IsRegular():
+			if err := copyFile(path, target); err != nil {
+				return err
+			}
+			return os.Chtimes(target, info.ModTime(), info.ModTime())
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type %s", path, mode.Type())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Innermost first, so a directory's time is not changed by filling it
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].info.ModTime(), dirs[i].info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
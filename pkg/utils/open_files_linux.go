@@ -0,0 +1,59 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// procRoot is the procfs mount point scanned for open file descriptors
+var procRoot = "/proc"
+
+// FindOpenFiles reports, for each path, the PIDs of processes holding a file open at
+// or below it. It scans file descriptors under /proc; processes owned by other users
+// are only visible when running with sufficient privileges.
+func FindOpenFiles(paths []string) (map[string][]int, error) {
+	targets := make(map[string]string, len(paths))
+	for _, p := range paths {
+		targets[p] = resolvePath(p)
+	}
+
+	procs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]int)
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join(procRoot, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// Process exited or belongs to another user
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+
+			for original, resolved := range targets {
+				if !seen[original] && isWithin(link, resolved) {
+					result[original] = append(result[original], pid)
+					seen[original] = true
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOpenFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	openDir := filepath.Join(tmpDir, "open")
+	closedDir := filepath.Join(tmpDir, "closed")
+	require.NoError(t, os.MkdirAll(openDir, 0755))
+	require.NoError(t, os.MkdirAll(closedDir, 0755))
+
+	f, err := os.Create(filepath.Join(openDir, "writing.part"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	openFiles, err := FindOpenFiles([]string{openDir, closedDir})
+	require.NoError(t, err)
+
+	assert.Contains(t, openFiles[openDir], os.Getpid())
+	assert.Empty(t, openFiles[closedDir])
+}
+
+func TestDeleteFilesSkipOpen(t *testing.T) {
+	tmpDir := t.TempDir()
+	openDir := filepath.Join(tmpDir, "open")
+	closedFile := filepath.Join(tmpDir, "closed.txt")
+	require.NoError(t, os.MkdirAll(openDir, 0755))
+	require.NoError(t, os.WriteFile(closedFile, []byte("x"), 0644))
+
+	f, err := os.Create(filepath.Join(openDir, "writing.part"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	result := DeleteFilesWithOptions([]string{openDir, closedFile}, DeleteOptions{SkipOpen: true}, nil)
+
+	assert.Equal(t, 1, result.SuccessCount)
+	assert.Equal(t, 1, result.SkippedCount)
+	assert.Equal(t, openDir, result.Skipped[0].Path)
+	assert.Equal(t, FailureInUse, result.Skipped[0].Reason)
+
+	_, err = os.Stat(openDir)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// FindOpenFiles is only supported on Linux, where open descriptors are listed in /proc
+func FindOpenFiles(paths []string) (map[string][]int, error) {
+	return nil, fmt.Errorf("open file detection is not supported on %s", runtime.GOOS)
+}
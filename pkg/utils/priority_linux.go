@@ -0,0 +1,44 @@
+//go:build linux
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+
+	"peerless/pkg/constants"
+)
+
+// ioprio_set arguments selecting the idle I/O class for a single thread
+const (
+	ioprioWhoProcess = 1
+	ioprioClassIdle  = 3
+	ioprioClassShift = 13
+)
+
+// LowerPriority gives the process the lowest CPU scheduling priority and the
+// idle I/O class. Linux applies both per thread, so every existing thread is
+// changed; threads started later inherit the setting from their creator.
+func LowerPriority() error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, constants.BackgroundNice); err != nil {
+			return fmt.Errorf("failed to set scheduling priority: %w", err)
+		}
+		ioprio := uintptr(ioprioClassIdle << ioprioClassShift)
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprio); errno != 0 {
+			return fmt.Errorf("failed to set I/O priority: %w", errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"peerless/pkg/constants"
+)
+
+func TestLowerPriority(t *testing.T) {
+	require.NoError(t, LowerPriority())
+
+	tasks, err := os.ReadDir("/proc/self/task")
+	require.NoError(t, err)
+	for _, task := range tasks {
+		stat, err := os.ReadFile("/proc/self/task/" + task.Name() + "/stat")
+		require.NoError(t, err)
+
+		// The nice value is the 17th field after the parenthesised command name
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		nice, err := strconv.Atoi(fields[16])
+		require.NoError(t, err)
+		assert.Equal(t, constants.BackgroundNice, nice, "thread %s", task.Name())
+	}
+}
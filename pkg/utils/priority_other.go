@@ -0,0 +1,13 @@
+//go:build !unix
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// LowerPriority is only supported on Unix systems
+func LowerPriority() error {
+	return fmt.Errorf("lowering process priority is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,19 @@
+//go:build unix && !linux
+
+package utils
+
+import (
+	"fmt"
+	"syscall"
+
+	"peerless/pkg/constants"
+)
+
+// LowerPriority gives the process the lowest CPU scheduling priority. I/O
+// priority cannot be changed on this platform.
+func LowerPriority() error {
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, constants.BackgroundNice); err != nil {
+		return fmt.Errorf("failed to set scheduling priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/base64"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces secrets in redacted text
+const RedactedPlaceholder = "[REDACTED]"
+
+var (
+	urlUserinfoPattern   = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s]*:)[^@\s]*@`)
+	authorizationPattern = regexp.MustCompile(`(?i)((?:proxy-)?authorization["']?\s*[:=]\s*["']?(?:basic|bearer|digest)\s+)[^\s"',]+`)
+)
+
+// Redactor removes credentials from text before it is logged or shown
+type Redactor struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// NewRedactor creates an empty Redactor
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// minSecretLength is the shortest literal secret worth redacting; shorter
+// values would mangle unrelated text such as paths and log keys
+const minSecretLength = 4
+
+// AddSecret registers a literal value, such as a password, to be redacted.
+// Its URL-escaped form and the matching basic-auth token are covered as well.
+// Secrets shorter than minSecretLength are ignored.
+func (r *Redactor) AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range []string{secret, url.QueryEscape(secret), url.PathEscape(secret)} {
+		if !containsString(r.secrets, s) {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+}
+
+// AddBasicAuth registers the password and the encoded Authorization token for user
+func (r *Redactor) AddBasicAuth(user, password string) {
+	if password == "" {
+		return
+	}
+	r.AddSecret(password)
+	r.AddSecret(base64.StdEncoding.EncodeToString([]byte(user + ":" + password)))
+}
+
+// Redact returns s with URL passwords, Authorization values and registered secrets removed
+func (r *Redactor) Redact(s string) string {
+	s = urlUserinfoPattern.ReplaceAllString(s, "${1}"+RedactedPlaceholder+"@")
+	s = authorizationPattern.ReplaceAllString(s, "${1}"+RedactedPlaceholder)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, RedactedPlaceholder)
+	}
+	return s
+}
+
+// Writer wraps w so everything written through it is redacted
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &redactingWriter{redactor: r, w: w}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	w        io.Writer
+}
+
+// Write redacts p before passing it on. It reports len(p) on success so
+// callers are not confused by the changed length.
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultRedactor collects secrets registered anywhere in the process
+var defaultRedactor = NewRedactor()
+
+// RegisterSecret adds a secret to the process-wide redactor
+func RegisterSecret(secret string) {
+	defaultRedactor.AddSecret(secret)
+}
+
+// RegisterBasicAuth adds basic-auth credentials to the process-wide redactor
+func RegisterBasicAuth(user, password string) {
+	defaultRedactor.AddBasicAuth(user, password)
+}
+
+// Redact removes credentials known to the process-wide redactor from s
+func Redact(s string) string {
+	return defaultRedactor.Redact(s)
+}
+
+// RedactingWriter wraps w with the process-wide redactor
+func RedactingWriter(w io.Writer) io.Writer {
+	return defaultRedactor.Writer(w)
+}
@@ -0,0 +1,372 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/paths"
+)
+
+// stagingManifest is the file of a batch directory recording its items
+const stagingManifest = "batch.json"
+
+// stagingIDLayout formats the creation time of a batch into its ID
+const stagingIDLayout = "20060102-150405"
+
+// Staging is a directory holding deletions in two phases: items are first
+// moved into a batch and only removed for good when the batch is purged, so
+// until then a whole batch can be restored. Each batch is a directory with the
+// items in files/ and their original locations in batch.json. Items on another
+// filesystem are kept in a batch directory below .peerless-staging at the root
+// of their filesystem instead, so staging never copies data.
+type Staging struct {
+	Dir string
+}
+
+// StagedBatch is a group of items staged by one deletion
+type StagedBatch struct {
+	ID      string       `json:"id"`
+	Created time.Time    `json:"created"`
+	Items   []StagedItem `json:"items"`
+
+	dir string
+	mu  sync.Mutex
+}
+
+// StagedItem is an item of a StagedBatch
+type StagedItem struct {
+	// Name identifies the item within the batch
+	Name         string `json:"name"`
+	OriginalPath string `json:"originalPath"`
+	Size         int64  `json:"size"`
+	IsDir        bool   `json:"isDir"`
+
+	// Store is the directory holding the item when it is not the batch's own
+	// files/, i.e. the batch's directory on the item's filesystem
+	Store string `json:"store,omitempty"`
+}
+
+// DefaultStagingDir returns $XDG_DATA_HOME/peerless/staging
+func DefaultStagingDir() (string, error) {
+	dataHome, err := paths.DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, constants.ConfigDirName, "staging"), nil
+}
+
+// NewStaging opens the staging directory dir, or the default one when dir is
+// empty, creating it when needed
+func NewStaging(dir string) (*Staging, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultStagingDir(); err != nil {
+			return nil, err
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staging directory %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(abs, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return &Staging{Dir: abs}, nil
+}
+
+// NewBatch creates an empty batch whose ID is derived from now
+func (s *Staging) NewBatch(now time.Time) (*StagedBatch, error) {
+	base := now.Format(stagingIDLayout)
+	for i := 1; ; i++ {
+		id := base
+		if i > 1 {
+			id += "-" + strconv.Itoa(i)
+		}
+		dir := filepath.Join(s.Dir, id)
+		err := os.Mkdir(dir, 0700)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create staging batch: %w", err)
+		}
+
+		b := &StagedBatch{ID: id, Created: now, Items: make([]StagedItem, 0), dir: dir}
+		if err := os.Mkdir(b.filesDir(), 0700); err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to create staging batch: %w", err)
+		}
+		if err := b.save(); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		return b, nil
+	}
+}
+
+// Batches returns the batches in the staging directory, most recent first.
+// Directories without a readable manifest are skipped.
+func (s *Staging) Batches() ([]*StagedBatch, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging directory: %w", err)
+	}
+
+	batches := make([]*StagedBatch, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		b, err := s.Batch(entry.Name())
+		if err != nil {
+			continue
+		}
+		batches = append(batches, b)
+	}
+
+	sort.SliceStable(batches, func(i, j int) bool { return batches[i].Created.After(batches[j].Created) })
+	return batches, nil
+}
+
+// Batch opens the batch with the given ID
+func (s *Staging) Batch(id string) (*StagedBatch, error) {
+	if id == "" || id != filepath.Base(id) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid staging batch ID %q", id)
+	}
+	dir := filepath.Join(s.Dir, id)
+	data, err := os.ReadFile(filepath.Join(dir, stagingManifest))
+	if err != nil {
+		return nil, fmt.Errorf("unknown staging batch %q: %w", id, err)
+	}
+
+	b := &StagedBatch{dir: dir}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse staging batch %q: %w", id, err)
+	}
+	b.ID = id
+	for _, item := range b.Items {
+		if item.Name != filepath.Base(item.Name) || !filepath.IsAbs(item.OriginalPath) || !validStore(item.Store, id) {
+			return nil, fmt.Errorf("staging batch %q has an invalid item %q", id, item.Name)
+		}
+	}
+	return b, nil
+}
+
+// PurgeBefore removes the batches created before cutoff for good and returns them
+func (s *Staging) PurgeBefore(cutoff time.Time) ([]*StagedBatch, error) {
+	batches, err := s.Batches()
+	if err != nil {
+		return nil, err
+	}
+
+	purged := make([]*StagedBatch, 0)
+	var errs []error
+	for _, b := range batches {
+		if !b.Created.Before(cutoff) {
+			continue
+		}
+		if err := b.Purge(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged = append(purged, b)
+	}
+	return purged, errors.Join(errs...)
+}
+
+func (b *StagedBatch) filesDir() string { return filepath.Join(b.dir, "files") }
+
+// storedPath returns where item is kept
+func (b *StagedBatch) storedPath(item StagedItem) string {
+	if item.Store != "" {
+		return filepath.Join(item.Store, item.Name)
+	}
+	return filepath.Join(b.filesDir(), item.Name)
+}
+
+// storeFor returns the directory to keep the item at path in, and "" for the
+// batch's own files/. Items on another filesystem than the staging directory
+// go to the batch's directory at the root of their filesystem. When that
+// cannot be created the item is not staged, since copying it elsewhere would
+// free nothing and could fill the other filesystem.
+func (b *StagedBatch) storeFor(path string) (string, error) {
+	parent := filepath.Dir(path)
+	if same, err := sameFilesystem(parent, b.filesDir()); err != nil || same {
+		// Undetermined filesystems are tried with a rename, which fails
+		// rather than copies when they differ
+		return "", nil
+	}
+	mount, err := mountPoint(parent)
+	if err != nil {
+		return "", fmt.Errorf("cannot stage %s on its filesystem (%w): %w", path, syscall.EXDEV, err)
+	}
+	store := filepath.Join(mount, constants.StagingDirName, b.ID, "files")
+	if err := os.MkdirAll(store, 0700); err != nil {
+		return "", fmt.Errorf("cannot stage %s on its filesystem (%w): %w", path, syscall.EXDEV, err)
+	}
+	return store, nil
+}
+
+// validStore reports whether store is empty or the files/ of the batch id in
+// a .peerless-staging directory, so a manifest cannot point purges elsewhere
+func validStore(store, id string) bool {
+	if store == "" {
+		return true
+	}
+	batchDir := filepath.Dir(store)
+	return filepath.IsAbs(store) && filepath.Clean(store) == store && filepath.Base(store) == "files" &&
+		filepath.Base(batchDir) == id && filepath.Base(filepath.Dir(batchDir)) == constants.StagingDirName
+}
+
+// Size returns the total size of the items in the batch
+func (b *StagedBatch) Size() int64 {
+	var total int64
+	for _, item := range b.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// Move moves the item op into the batch on its own filesystem. Items are
+// never copied: an item that cannot be staged on its filesystem fails with an
+// error classified as FailureCrossDevice and is left in place.
+func (b *StagedBatch) Move(op FileOperation) error {
+	abs, err := filepath.Abs(op.Path)
+	if err != nil {
+		return fmt.Errorf("invalid path %s: %w", op.Path, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	store, err := b.storeFor(abs)
+	if err != nil {
+		return err
+	}
+	item := StagedItem{OriginalPath: abs, Size: op.Size, IsDir: op.IsDir, Store: store}
+	base := filepath.Base(abs)
+	item.Name = base
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(b.storedPath(item)); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		item.Name = base + "." + strconv.Itoa(i)
+	}
+
+	if err := rename(abs, b.storedPath(item)); err != nil {
+		return err
+	}
+	b.Items = append(b.Items, item)
+	return b.save()
+}
+
+// Restore moves the items of the batch back to their original locations,
+// which must not exist. Restored items leave the batch; once it is empty the
+// batch is removed. It returns the restored paths.
+func (b *StagedBatch) Restore() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	restored := make([]string, 0, len(b.Items))
+	remaining := make([]StagedItem, 0)
+	var errs []error
+	for _, item := range b.Items {
+		if _, err := os.Lstat(item.OriginalPath); err == nil {
+			errs = append(errs, fmt.Errorf("cannot restore %s: it already exists", item.OriginalPath))
+			remaining = append(remaining, item)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to recreate parent directory of %s: %w", item.OriginalPath, err))
+			remaining = append(remaining, item)
+			continue
+		}
+		if err := moveItem(b.storedPath(item), item.OriginalPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", item.OriginalPath, err))
+			remaining = append(remaining, item)
+			continue
+		}
+		restored = append(restored, item.OriginalPath)
+	}
+
+	stores := b.stores()
+	b.Items = remaining
+	if len(remaining) == 0 {
+		if err := b.removeAll(stores); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove staging batch %s: %w", b.ID, err))
+		}
+	} else if err := b.save(); err != nil {
+		errs = append(errs, err)
+	}
+	return restored, errors.Join(errs...)
+}
+
+// Purge removes the batch and its items for good
+func (b *StagedBatch) Purge() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.removeAll(b.stores()); err != nil {
+		return fmt.Errorf("failed to purge staging batch %s: %w", b.ID, err)
+	}
+	return nil
+}
+
+// stores returns the distinct stores of the items on other filesystems
+func (b *StagedBatch) stores() []string {
+	seen := make(map[string]bool)
+	stores := make([]string, 0)
+	for _, item := range b.Items {
+		if item.Store != "" && !seen[item.Store] {
+			seen[item.Store] = true
+			stores = append(stores, item.Store)
+		}
+	}
+	return stores
+}
+
+// removeAll removes the batch directory and the batch's directories on other
+// filesystems, and their .peerless-staging directories once they are empty
+func (b *StagedBatch) removeAll(stores []string) error {
+	var errs []error
+	for _, store := range stores {
+		batchDir := filepath.Dir(store)
+		if err := os.RemoveAll(batchDir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// Fails while other batches are staged there
+		os.Remove(filepath.Dir(batchDir))
+	}
+	if err := os.RemoveAll(b.dir); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// save writes the manifest of the batch; the caller holds b.mu or owns b
+func (b *StagedBatch) save() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode staging batch: %w", err)
+	}
+	path := filepath.Join(b.dir, stagingManifest)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write staging batch: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write staging batch: %w", err)
+	}
+	return nil
+}
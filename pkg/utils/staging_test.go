@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"peerless/pkg/constants"
+)
+
+func TestStaging(t *testing.T) {
+	base := t.TempDir()
+	staging, err := NewStaging(filepath.Join(base, "staging"))
+	require.NoError(t, err)
+
+	movie := filepath.Join(base, "downloads", "Old Movie")
+	require.NoError(t, os.MkdirAll(movie, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(movie, "movie.mkv"), []byte("xyz"), 0644))
+	other := filepath.Join(base, "other", "Old Movie")
+	require.NoError(t, os.MkdirAll(other, 0755))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	batch, err := staging.NewBatch(now)
+	require.NoError(t, err)
+	assert.Equal(t, "20260301-120000", batch.ID)
+
+	result := DeleteFilesWithOptions([]string{movie, other}, DeleteOptions{Stage: batch}, nil)
+	require.Equal(t, 2, result.SuccessCount)
+	assert.NoDirExists(t, movie)
+	assert.NoDirExists(t, other)
+	assert.FileExists(t, filepath.Join(staging.Dir, batch.ID, "files", "Old Movie", "movie.mkv"))
+	assert.DirExists(t, filepath.Join(staging.Dir, batch.ID, "files", "Old Movie.2"))
+	assert.Equal(t, int64(3), batch.Size())
+
+	t.Run("batches in the same second get distinct IDs", func(t *testing.T) {
+		second, err := staging.NewBatch(now)
+		require.NoError(t, err)
+		assert.Equal(t, "20260301-120000-2", second.ID)
+		require.NoError(t, second.Purge())
+	})
+
+	t.Run("batches are read back from their manifest", func(t *testing.T) {
+		batches, err := staging.Batches()
+		require.NoError(t, err)
+		require.Len(t, batches, 1)
+		assert.Equal(t, batch.ID, batches[0].ID)
+		assert.True(t, batches[0].Created.Equal(now))
+		require.Len(t, batches[0].Items, 2)
+		assert.Equal(t, movie, batches[0].Items[0].OriginalPath)
+		assert.True(t, batches[0].Items[0].IsDir)
+	})
+
+	t.Run("invalid IDs are rejected", func(t *testing.T) {
+		_, err := staging.Batch("../staging")
+		assert.Error(t, err)
+		_, err = staging.Batch("missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("restore keeps items whose original path exists", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(other, 0755))
+		loaded, err := staging.Batch(batch.ID)
+		require.NoError(t, err)
+
+		restored, err := loaded.Restore()
+		assert.Error(t, err)
+		assert.Equal(t, []string{movie}, restored)
+		assert.FileExists(t, filepath.Join(movie, "movie.mkv"))
+
+		loaded, err = staging.Batch(batch.ID)
+		require.NoError(t, err)
+		require.Len(t, loaded.Items, 1)
+		assert.Equal(t, other, loaded.Items[0].OriginalPath)
+
+		require.NoError(t, os.Remove(other))
+		restored, err = loaded.Restore()
+		require.NoError(t, err)
+		assert.Equal(t, []string{other}, restored)
+		assert.NoDirExists(t, filepath.Join(staging.Dir, batch.ID))
+	})
+}
+
+func TestStaging_PurgeBefore(t *testing.T) {
+	staging, err := NewStaging(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Now()
+	old, err := staging.NewBatch(now.Add(-8 * 24 * time.Hour))
+	require.NoError(t, err)
+	recent, err := staging.NewBatch(now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	purged, err := staging.PurgeBefore(now.Add(-7 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	assert.Equal(t, old.ID, purged[0].ID)
+	assert.NoDirExists(t, filepath.Join(staging.Dir, old.ID))
+	assert.DirExists(t, filepath.Join(staging.Dir, recent.ID))
+}
+
+func TestStaging_OtherFilesystem(t *testing.T) {
+	base := t.TempDir()
+	staging, err := NewStaging(filepath.Join(base, "home", "staging"))
+	require.NoError(t, err)
+
+	// Everything below base/mnt is on another filesystem mounted at base/mnt
+	mount := filepath.Join(base, "mnt")
+	originalSame, originalMount := sameFilesystem, mountPoint
+	sameFilesystem = func(a, b string) (bool, error) { return !strings.HasPrefix(a, mount), nil }
+	mountPoint = func(string) (string, error) { return mount, nil }
+	t.Cleanup(func() { sameFilesystem, mountPoint = originalSame, originalMount })
+
+	movie := filepath.Join(mount, "downloads", "Old Movie")
+	require.NoError(t, os.MkdirAll(movie, 0755))
+	local := filepath.Join(base, "home", "Old Movie")
+	require.NoError(t, os.MkdirAll(local, 0755))
+
+	batch, err := staging.NewBatch(time.Now())
+	require.NoError(t, err)
+	result := DeleteFilesWithOptions([]string{movie, local}, DeleteOptions{Stage: batch}, nil)
+	require.Equal(t, 2, result.SuccessCount)
+
+	store := filepath.Join(mount, constants.StagingDirName, batch.ID, "files")
+	assert.DirExists(t, filepath.Join(store, "Old Movie"))
+	assert.DirExists(t, filepath.Join(staging.Dir, batch.ID, "files", "Old Movie"))
+
+	loaded, err := staging.Batch(batch.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Items, 2)
+	assert.Equal(t, store, loaded.Items[0].Store)
+	assert.Empty(t, loaded.Items[1].Store)
+
+	t.Run("restore", func(t *testing.T) {
+		restored, err := loaded.Restore()
+		require.NoError(t, err)
+		assert.Equal(t, []string{movie, local}, restored)
+		assert.DirExists(t, movie)
+		assert.NoDirExists(t, filepath.Join(mount, constants.StagingDirName))
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		batch, err := staging.NewBatch(time.Now())
+		require.NoError(t, err)
+		require.NoError(t, batch.Move(FileOperation{Path: movie, IsDir: true}))
+		require.NoError(t, batch.Purge())
+		assert.NoDirExists(t, movie)
+		assert.NoDirExists(t, filepath.Join(mount, constants.StagingDirName))
+		assert.NoDirExists(t, filepath.Join(staging.Dir, batch.ID))
+	})
+
+	t.Run("fails when the filesystem root is not writable", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(movie, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(mount, constants.StagingDirName), nil, 0644))
+		t.Cleanup(func() { os.Remove(filepath.Join(mount, constants.StagingDirName)) })
+
+		batch, err := staging.NewBatch(time.Now())
+		require.NoError(t, err)
+		result := DeleteFilesWithOptions([]string{movie}, DeleteOptions{Stage: batch}, nil)
+		require.Equal(t, 1, result.FailedCount)
+		assert.Equal(t, FailureCrossDevice, result.Failed[0].Reason)
+		assert.DirExists(t, movie)
+		assert.Empty(t, batch.Items)
+	})
+
+	t.Run("never copies across filesystems", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(movie, 0755))
+		sameFilesystem = func(a, b string) (bool, error) { return false, errors.New("unknown") }
+		crossDevice(t)
+
+		batch, err := staging.NewBatch(time.Now())
+		require.NoError(t, err)
+		err = batch.Move(FileOperation{Path: movie, IsDir: true})
+		assert.Equal(t, FailureCrossDevice, ClassifyError(err))
+		assert.DirExists(t, movie)
+		assert.NoDirExists(t, filepath.Join(staging.Dir, batch.ID, "files", "Old Movie"))
+	})
+}
+
+func TestStaging_BatchRejectsForeignStore(t *testing.T) {
+	staging, err := NewStaging(t.TempDir())
+	require.NoError(t, err)
+	batch, err := staging.NewBatch(time.Now())
+	require.NoError(t, err)
+
+	batch.Items = append(batch.Items, StagedItem{Name: "x", OriginalPath: "/data/x", Store: "/data/important/files"})
+	require.NoError(t, batch.save())
+	_, err = staging.Batch(batch.ID)
+	assert.ErrorContains(t, err, "invalid item")
+}
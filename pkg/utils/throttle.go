@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle spaces out filesystem operations to at most a fixed number per
+// second. A nil Throttle never waits, so callers can pass it unconditionally.
+type Throttle struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewThrottle returns a Throttle allowing opsPerSecond operations per second,
+// or nil when opsPerSecond is not positive
+func NewThrottle(opsPerSecond int) *Throttle {
+	if opsPerSecond <= 0 {
+		return nil
+	}
+	return &Throttle{interval: time.Second / time.Duration(opsPerSecond)}
+}
+
+// Wait blocks until the next operation may run
+func (t *Throttle) Wait() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
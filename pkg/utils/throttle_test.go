@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	t.Run("non-positive rate disables throttling", func(t *testing.T) {
+		assert.Nil(t, NewThrottle(0))
+		assert.Nil(t, NewThrottle(-5))
+
+		var throttle *Throttle
+		start := time.Now()
+		for i := 0; i < 1000; i++ {
+			throttle.Wait()
+		}
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("spaces out operations", func(t *testing.T) {
+		throttle := NewThrottle(100)
+
+		start := time.Now()
+		for i := 0; i < 6; i++ {
+			throttle.Wait()
+		}
+		// The first call runs immediately, the other five wait 10ms each
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
+
+func TestDeleteFilesThrottled(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "release")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.mkv"), []byte("abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.nfo"), []byte("de"), 0644))
+	file := filepath.Join(tmpDir, "single.txt")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+
+	opts := DeleteOptions{Throttle: NewThrottle(1000)}
+	result := DeleteFilesWithOptions([]string{dir, file}, opts, nil)
+
+	assert.Equal(t, 2, result.SuccessCount)
+	assert.Equal(t, 0, result.FailedCount)
+	assert.Equal(t, int64(6), result.TotalSize)
+	assert.NoDirExists(t, dir)
+	assert.NoFileExists(t, file)
+}
@@ -0,0 +1,408 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"peerless/pkg/constants"
+	"peerless/pkg/paths"
+)
+
+// trashInfoSuffix is the extension of the metadata files in a trash's info directory
+const trashInfoSuffix = ".trashinfo"
+
+// trashTimeLayout is the DeletionDate format of the freedesktop.org trash specification
+const trashTimeLayout = "2006-01-02T15:04:05"
+
+// Trash is a directory laid out like the freedesktop.org trash: items are kept
+// in files/ and the original location of each is recorded in info/, so desktop
+// file managers can show and restore them too. The user's XDG trash also spans
+// the trashes of other volumes, as the specification has it: items on another
+// filesystem go to the trash at the root of theirs, so trashing never copies
+// data, and the volume trashes used are recorded in the state directory.
+type Trash struct {
+	Dir string
+
+	// volumes is the file listing the volume trashes in use; it is only set
+	// for the XDG trash
+	volumes string
+}
+
+// TrashItem is an item kept in a trash
+type TrashItem struct {
+	// Name identifies the item within the trash
+	Name         string
+	OriginalPath string
+	DeletedAt    time.Time
+
+	// Trash is the directory of the trash holding the item
+	Trash string
+}
+
+// DefaultTrashDir returns the user's XDG trash, $XDG_DATA_HOME/Trash
+func DefaultTrashDir() (string, error) {
+	dataHome, err := paths.DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// NewTrash opens the trash at dir, or the XDG trash with its volume trashes
+// when dir is empty, creating its directories when needed
+func NewTrash(dir string) (*Trash, error) {
+	var volumes string
+	if dir == "" {
+		var err error
+		if dir, err = DefaultTrashDir(); err != nil {
+			return nil, err
+		}
+		stateDir, err := paths.StateDir()
+		if err != nil {
+			return nil, err
+		}
+		volumes = filepath.Join(stateDir, constants.TrashVolumesFileName)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trash directory %s: %w", dir, err)
+	}
+
+	t := &Trash{Dir: abs, volumes: volumes}
+	for _, sub := range []string{t.filesDir(), t.infoDir()} {
+		if err := os.MkdirAll(sub, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Trash) filesDir() string { return filepath.Join(t.Dir, "files") }
+func (t *Trash) infoDir() string  { return filepath.Join(t.Dir, "info") }
+
+// Move moves path into the trash and returns the name it is kept under. The
+// XDG trash keeps an item on another filesystem in the trash of its volume,
+// and fails with an error classified as FailureCrossDevice when that cannot
+// be created. Other trashes copy such an item in and then remove it.
+func (t *Trash) Move(path string, now time.Time) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	if t.volumes == "" {
+		return t.move(abs, now, moveItem)
+	}
+	target, err := t.trashFor(abs)
+	if err != nil {
+		return "", err
+	}
+	return target.move(abs, now, rename)
+}
+
+// move moves the item at abs into the trash with the given move function
+func (t *Trash) move(abs string, now time.Time, move func(src, dst string) error) (string, error) {
+	var err error
+	// Reserve a unique name by creating its info file exclusively
+	base := filepath.Base(abs)
+	var name string
+	var info *os.File
+	for i := 1; ; i++ {
+		name = trashName(base, i)
+		info, err = os.OpenFile(t.infoPath(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to record trash info: %w", err)
+		}
+		if _, statErr := os.Lstat(filepath.Join(t.filesDir(), name)); statErr == nil {
+			// A leftover item without info; keep it and try the next name
+			info.Close()
+			os.Remove(t.infoPath(name))
+			continue
+		}
+		break
+	}
+
+	_, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n", escapeTrashPath(abs), now.Format(trashTimeLayout))
+	if closeErr := info.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(t.infoPath(name))
+		return "", err
+	}
+	stored := filepath.Join(t.filesDir(), name)
+	if err := move(abs, stored); err != nil {
+		// A complete copy stays restorable even though the original remains
+		if _, statErr := os.Lstat(stored); statErr == nil {
+			return name, err
+		}
+		os.Remove(t.infoPath(name))
+		return "", err
+	}
+	return name, nil
+}
+
+// trashFor returns the trash to keep the item at path in: this one, or the
+// trash of the item's volume when that is another filesystem
+func (t *Trash) trashFor(path string) (*Trash, error) {
+	parent := filepath.Dir(path)
+	if same, err := sameFilesystem(parent, t.Dir); err != nil || same {
+		// Undetermined filesystems are tried with a rename, which fails
+		// rather than copies when they differ
+		return t, nil
+	}
+	mount, err := mountPoint(parent)
+	if err != nil {
+		return nil, fmt.Errorf("cannot trash %s on its filesystem (%w): %w", path, syscall.EXDEV, err)
+	}
+	volume, err := NewTrash(volumeTrashDir(mount))
+	if err != nil {
+		return nil, fmt.Errorf("cannot trash %s on its filesystem (%w): %w", path, syscall.EXDEV, err)
+	}
+	if err := t.addVolume(volume.Dir); err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// volumeTrashDir returns the user's trash on the volume mounted at topdir:
+// their directory in a $topdir/.Trash with the sticky bit set up by an
+// administrator, or else $topdir/.Trash-$uid
+func volumeTrashDir(topdir string) string {
+	uid := strconv.Itoa(os.Getuid())
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		return filepath.Join(shared, uid)
+	}
+	return filepath.Join(topdir, ".Trash-"+uid)
+}
+
+// volumeTrashes returns the recorded volume trashes of the XDG trash
+func (t *Trash) volumeTrashes() ([]*Trash, error) {
+	if t.volumes == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(t.volumes)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the list of volume trashes: %w", err)
+	}
+
+	trashes := make([]*Trash, 0)
+	for _, dir := range strings.Split(string(data), "\n") {
+		if filepath.IsAbs(dir) {
+			trashes = append(trashes, &Trash{Dir: dir})
+		}
+	}
+	return trashes, nil
+}
+
+// addVolume records the volume trash dir unless it is already known
+func (t *Trash) addVolume(dir string) error {
+	trashes, err := t.volumeTrashes()
+	if err != nil {
+		return err
+	}
+	for _, volume := range trashes {
+		if volume.Dir == dir {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.volumes), 0700); err != nil {
+		return fmt.Errorf("failed to record volume trash %s: %w", dir, err)
+	}
+	f, err := os.OpenFile(t.volumes, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to record volume trash %s: %w", dir, err)
+	}
+	_, err = fmt.Fprintln(f, dir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record volume trash %s: %w", dir, err)
+	}
+	return nil
+}
+
+// trashes returns this trash followed by its volume trashes
+func (t *Trash) trashes() ([]*Trash, error) {
+	volumes, err := t.volumeTrashes()
+	if err != nil {
+		return nil, err
+	}
+	return append([]*Trash{t}, volumes...), nil
+}
+
+// List returns the items in the trash and its volume trashes, most recently
+// deleted first. Info files that cannot be parsed or whose item is gone are
+// skipped, as are volume trashes that cannot be read, e.g. while unmounted.
+func (t *Trash) List() ([]TrashItem, error) {
+	trashes, err := t.trashes()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItem, 0)
+	for i, trash := range trashes {
+		found, err := trash.list()
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		items = append(items, found...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// list returns the items of this trash alone
+func (t *Trash) list() ([]TrashItem, error) {
+	entries, err := os.ReadDir(t.infoDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	items := make([]TrashItem, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), trashInfoSuffix)
+		if !ok {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(t.filesDir(), name)); err != nil {
+			continue
+		}
+		item, err := t.readInfo(name)
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Restore moves the named item back to its original path, which must not
+// exist. A name found in several of the trashes is ambiguous; it can then be
+// restored by opening the trash holding it with NewTrash.
+func (t *Trash) Restore(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) {
+		return "", fmt.Errorf("invalid trash item name %q", name)
+	}
+	trashes, err := t.trashes()
+	if err != nil {
+		return "", err
+	}
+
+	holding := make([]*Trash, 0, 1)
+	dirs := make([]string, 0, 1)
+	for _, trash := range trashes {
+		if _, err := os.Lstat(trash.infoPath(name)); err == nil {
+			holding = append(holding, trash)
+			dirs = append(dirs, trash.Dir)
+		}
+	}
+	switch len(holding) {
+	case 0:
+		return t.restore(name)
+	case 1:
+		return holding[0].restore(name)
+	default:
+		return "", fmt.Errorf("trash item %q is in several trashes (%s); restore it from one of them with --trash-dir", name, strings.Join(dirs, ", "))
+	}
+}
+
+// restore restores the named item of this trash alone
+func (t *Trash) restore(name string) (string, error) {
+	item, err := t.readInfo(name)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return "", fmt.Errorf("cannot restore %s: %s already exists", name, item.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to recreate parent directory of %s: %w", item.OriginalPath, err)
+	}
+	if err := moveItem(filepath.Join(t.filesDir(), name), item.OriginalPath); err != nil {
+		return "", fmt.Errorf("failed to restore %s: %w", name, err)
+	}
+	if err := os.Remove(t.infoPath(name)); err != nil {
+		return item.OriginalPath, fmt.Errorf("restored %s but failed to remove its trash info: %w", name, err)
+	}
+	return item.OriginalPath, nil
+}
+
+func (t *Trash) infoPath(name string) string {
+	return filepath.Join(t.infoDir(), name+trashInfoSuffix)
+}
+
+// readInfo parses the info file of the named item
+func (t *Trash) readInfo(name string) (TrashItem, error) {
+	f, err := os.Open(t.infoPath(name))
+	if err != nil {
+		return TrashItem{}, fmt.Errorf("unknown trash item %q: %w", name, err)
+	}
+	defer f.Close()
+
+	item := TrashItem{Name: name, Trash: t.Dir}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			if item.OriginalPath, err = url.PathUnescape(value); err != nil {
+				return TrashItem{}, fmt.Errorf("invalid path in trash info of %q: %w", name, err)
+			}
+		case "DeletionDate":
+			if item.DeletedAt, err = time.ParseInLocation(trashTimeLayout, value, time.Local); err != nil {
+				return TrashItem{}, fmt.Errorf("invalid deletion date in trash info of %q: %w", name, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashItem{}, fmt.Errorf("failed to read trash info of %q: %w", name, err)
+	}
+	if !filepath.IsAbs(item.OriginalPath) {
+		return TrashItem{}, fmt.Errorf("trash info of %q has no absolute path", name)
+	}
+	return item, nil
+}
+
+// escapeTrashPath percent-encodes a path for an info file, keeping the slashes
+func escapeTrashPath(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// trashName returns the name of the n-th item named base in the trash
+func trashName(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "." + strconv.Itoa(n)
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	base := t.TempDir()
+	trash, err := NewTrash(filepath.Join(base, "quarantine"))
+	require.NoError(t, err)
+
+	src := filepath.Join(base, "downloads", "Old Movie")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "movie.mkv"), []byte("x"), 0644))
+	other := filepath.Join(base, "other", "Old Movie")
+	require.NoError(t, os.MkdirAll(other, 0755))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	name, err := trash.Move(src, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Old Movie", name)
+	assert.NoDirExists(t, src)
+	assert.FileExists(t, filepath.Join(trash.Dir, "files", "Old Movie", "movie.mkv"))
+
+	info, err := os.ReadFile(filepath.Join(trash.Dir, "info", "Old Movie.trashinfo"))
+	require.NoError(t, err)
+	assert.Contains(t, string(info), "Path="+filepath.ToSlash(filepath.Join(base, "downloads", "Old%20Movie")))
+	assert.Contains(t, string(info), "DeletionDate=2026-03-01T12:00:00")
+
+	t.Run("same name gets a suffix", func(t *testing.T) {
+		name, err := trash.Move(other, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "Old Movie.2", name)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		items, err := trash.List()
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, "Old Movie.2", items[0].Name)
+		assert.Equal(t, other, items[0].OriginalPath)
+		assert.Equal(t, src, items[1].OriginalPath)
+		assert.True(t, items[1].DeletedAt.Equal(now))
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		restored, err := trash.Restore("Old Movie")
+		require.NoError(t, err)
+		assert.Equal(t, src, restored)
+		assert.FileExists(t, filepath.Join(src, "movie.mkv"))
+		assert.NoFileExists(t, filepath.Join(trash.Dir, "info", "Old Movie.trashinfo"))
+	})
+
+	t.Run("restore refuses to overwrite", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(other, 0755))
+		_, err := trash.Restore("Old Movie.2")
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("restore rejects unknown and nested names", func(t *testing.T) {
+		_, err := trash.Restore("missing")
+		assert.Error(t, err)
+		_, err = trash.Restore("../info/x")
+		assert.ErrorContains(t, err, "invalid trash item name")
+	})
+}
+
+func TestDeleteFilesWithOptions_Trash(t *testing.T) {
+	base := t.TempDir()
+	trash, err := NewTrash(filepath.Join(base, "trash"))
+	require.NoError(t, err)
+
+	path := filepath.Join(base, "file.bin")
+	require.NoError(t, os.WriteFile(path, make([]byte, 10), 0644))
+
+	result := DeleteFilesWithOptions([]string{path}, DeleteOptions{Trash: trash}, nil)
+	assert.Equal(t, 1, result.SuccessCount)
+	assert.Equal(t, int64(10), result.TotalSize)
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, filepath.Join(trash.Dir, "files", "file.bin"))
+}
+
+// crossDevice makes renames fail as between filesystems for the rest of t
+func crossDevice(t *testing.T) {
+	original := rename
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = original })
+}
+
+// volumeTrash returns an XDG trash below base whose items below base/mnt are
+// on another filesystem mounted there
+func volumeTrash(t *testing.T, base string) (*Trash, string) {
+	trash, err := NewTrash(filepath.Join(base, "home", "Trash"))
+	require.NoError(t, err)
+	trash.volumes = filepath.Join(base, "state", "trash-volumes")
+
+	mount := filepath.Join(base, "mnt")
+	originalSame, originalMount := sameFilesystem, mountPoint
+	sameFilesystem = func(a, b string) (bool, error) { return !strings.HasPrefix(a, mount), nil }
+	mountPoint = func(string) (string, error) { return mount, nil }
+	t.Cleanup(func() { sameFilesystem, mountPoint = originalSame, originalMount })
+	return trash, mount
+}
+
+func TestTrash_VolumeTrash(t *testing.T) {
+	base := t.TempDir()
+	trash, mount := volumeTrash(t, base)
+
+	movie := filepath.Join(mount, "downloads", "Old Movie")
+	require.NoError(t, os.MkdirAll(movie, 0755))
+	local := filepath.Join(base, "home", "Old Movie")
+	require.NoError(t, os.MkdirAll(local, 0755))
+
+	now := time.Now()
+	result := DeleteFilesWithOptions([]string{movie, local}, DeleteOptions{Trash: trash}, nil)
+	require.Equal(t, 2, result.SuccessCount)
+
+	volume := filepath.Join(mount, ".Trash-"+strconv.Itoa(os.Getuid()))
+	assert.DirExists(t, filepath.Join(volume, "files", "Old Movie"))
+	assert.FileExists(t, filepath.Join(volume, "info", "Old Movie.trashinfo"))
+	assert.DirExists(t, filepath.Join(trash.Dir, "files", "Old Movie"))
+	recorded, err := os.ReadFile(trash.volumes)
+	require.NoError(t, err)
+	assert.Equal(t, volume+"\n", string(recorded))
+
+	t.Run("list spans the volume trashes", func(t *testing.T) {
+		items, err := trash.List()
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		trashes := map[string]string{items[0].OriginalPath: items[0].Trash, items[1].OriginalPath: items[1].Trash}
+		assert.Equal(t, volume, trashes[movie])
+		assert.Equal(t, trash.Dir, trashes[local])
+	})
+
+	t.Run("names in several trashes are ambiguous", func(t *testing.T) {
+		_, err := trash.Restore("Old Movie")
+		assert.ErrorContains(t, err, "several trashes")
+	})
+
+	t.Run("restore from the volume trash", func(t *testing.T) {
+		other, err := NewTrash(volume)
+		require.NoError(t, err)
+		restored, err := other.Restore("Old Movie")
+		require.NoError(t, err)
+		assert.Equal(t, movie, restored)
+
+		restored, err = trash.Restore("Old Movie")
+		require.NoError(t, err)
+		assert.Equal(t, local, restored)
+	})
+
+	t.Run("fails when the volume trash cannot be created", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(volume))
+		require.NoError(t, os.WriteFile(volume, nil, 0644))
+		crossDevice(t)
+
+		_, err := trash.Move(movie, now)
+		assert.Equal(t, FailureCrossDevice, ClassifyError(err))
+		assert.DirExists(t, movie)
+		assert.NoDirExists(t, filepath.Join(trash.Dir, "files", "Old Movie"))
+	})
+}
+
+func TestTrash_CrossDeviceNeedsSpace(t *testing.T) {
+	base := t.TempDir()
+	trash, err := NewTrash(filepath.Join(base, "quarantine"))
+	require.NoError(t, err)
+	src := filepath.Join(base, "movie.mkv")
+	require.NoError(t, os.WriteFile(src, []byte("movie"), 0644))
+
+	crossDevice(t)
+	original := freeSpace
+	freeSpace = func(string) (int64, error) { return 4, nil }
+	t.Cleanup(func() { freeSpace = original })
+
+	_, err = trash.Move(src, time.Now())
+	assert.ErrorContains(t, err, "has only 4 B free")
+	assert.FileExists(t, src)
+	assert.NoFileExists(t, filepath.Join(trash.Dir, "files", "movie.mkv"))
+	assert.NoFileExists(t, filepath.Join(trash.Dir, "info", "movie.mkv.trashinfo"))
+}
@@ -0,0 +1,72 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash_CrossDeviceCopyFails(t *testing.T) {
+	base := t.TempDir()
+	trash, err := NewTrash(filepath.Join(base, "trash"))
+	require.NoError(t, err)
+
+	src := filepath.Join(base, "fifo")
+	require.NoError(t, syscall.Mkfifo(src, 0644))
+
+	crossDevice(t)
+	_, err = trash.Move(src, time.Now())
+	assert.ErrorContains(t, err, "unsupported file type")
+	assert.NoFileExists(t, filepath.Join(trash.Dir, "files", "fifo"))
+	assert.NoFileExists(t, filepath.Join(trash.Dir, "info", "fifo.trashinfo"))
+	_, err = os.Lstat(src)
+	assert.NoError(t, err)
+}
+
+// Copies need the free space of the destination, which is only known on Unix
+func TestTrash_CrossDevice(t *testing.T) {
+	base := t.TempDir()
+	trash, err := NewTrash(filepath.Join(base, "trash"))
+	require.NoError(t, err)
+
+	src := filepath.Join(base, "downloads", "Old Show")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "Season 1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Season 1", "e01.mkv"), []byte("episode"), 0640))
+	require.NoError(t, os.Symlink("Season 1/e01.mkv", filepath.Join(src, "latest")))
+	mtime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(src, "Season 1", "e01.mkv"), mtime, mtime))
+	require.NoError(t, os.Chmod(filepath.Join(src, "Season 1"), 0750))
+
+	crossDevice(t)
+	name, err := trash.Move(src, time.Now())
+	require.NoError(t, err)
+	assert.NoDirExists(t, src)
+
+	stored := filepath.Join(trash.Dir, "files", name)
+	data, err := os.ReadFile(filepath.Join(stored, "Season 1", "e01.mkv"))
+	require.NoError(t, err)
+	assert.Equal(t, "episode", string(data))
+	info, err := os.Stat(filepath.Join(stored, "Season 1", "e01.mkv"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(mtime))
+	info, err = os.Stat(filepath.Join(stored, "Season 1"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(stored, "latest"))
+	require.NoError(t, err)
+	assert.Equal(t, "Season 1/e01.mkv", link)
+
+	restored, err := trash.Restore(name)
+	require.NoError(t, err)
+	assert.Equal(t, src, restored)
+	assert.FileExists(t, filepath.Join(src, "Season 1", "e01.mkv"))
+	assert.NoDirExists(t, stored)
+}
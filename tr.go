@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"peerless/pkg/output"
+	"peerless/pkg/service"
+	"peerless/pkg/utils"
+
+	"github.com/urfave/cli/v3"
+)
+
+// trActions are the mutually exclusive operations of the tr command
+var trActions = []string{"list", "info", "start", "stop", "verify", "session-info", "session-stats"}
+
+// trCommand maps a subset of transmission-remote flags onto peerless operations
+func trCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tr",
+		Usage: "transmission-remote compatible flags (-l, -t ID -i, -t ID -s/-S, -si, -st)",
+		Description: "Accepts a subset of transmission-remote's flags so existing scripts keep working.\n" +
+			"-v is taken by --verbose, so use --verify instead.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "List all torrents",
+			},
+			&cli.StringFlag{
+				Name:    "torrent",
+				Aliases: []string{"t"},
+				Usage:   "Select torrents by ID list (e.g. 1,3-5) or \"all\"",
+			},
+			&cli.BoolFlag{
+				Name:    "info",
+				Aliases: []string{"i"},
+				Usage:   "Show details of the selected torrents",
+			},
+			&cli.BoolFlag{
+				Name:    "start",
+				Aliases: []string{"s"},
+				Usage:   "Start the selected torrents",
+			},
+			&cli.BoolFlag{
+				Name:    "stop",
+				Aliases: []string{"S"},
+				Usage:   "Stop the selected torrents",
+			},
+			&cli.BoolFlag{
+				Name:  "verify",
+				Usage: "Verify the selected torrents",
+			},
+			&cli.BoolFlag{
+				Name:    "session-info",
+				Aliases: []string{"si"},
+				Usage:   "Show session information",
+			},
+			&cli.BoolFlag{
+				Name:    "session-stats",
+				Aliases: []string{"st"},
+				Usage:   "Show session statistics",
+			},
+		},
+		Action: runTransmissionRemote,
+	}
+}
+
+func runTransmissionRemote(ctx context.Context, cmd *cli.Command) error {
+	var action string
+	for _, name := range trActions {
+		if !cmd.Bool(name) {
+			continue
+		}
+		if action != "" {
+			return invalidf("conflicting options: --%s and --%s cannot be used together", action, name)
+		}
+		action = name
+	}
+	if action == "" {
+		return invalidf("no action given (use one of --%s)", strings.Join(trActions, ", --"))
+	}
+
+	var filters []service.TorrentFilter
+	switch spec := cmd.String("torrent"); {
+	case action == "list" || action == "session-info" || action == "session-stats":
+	case spec == "":
+		return invalidf("--%s requires --torrent", action)
+	case spec != "all":
+		ids, err := utils.ParseIDList(spec)
+		if err != nil {
+			return invalidf("invalid --torrent: %w", err)
+		}
+		filters = append(filters, service.IDFilter(ids...))
+	}
+
+	output.Logger.Info("Starting tr command", "action", action)
+
+	svc, err := createService(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list", "info":
+		torrents, err := svc.GetTorrents(ctx, filters...)
+		if err != nil {
+			return fmt.Errorf("error getting torrents: %w", err)
+		}
+		if action == "list" {
+			output.PrintTorrentList(torrents)
+			return nil
+		}
+		if len(torrents) == 0 {
+			return fmt.Errorf("no torrents match --torrent %s", cmd.String("torrent"))
+		}
+		for i, t := range torrents {
+			if i > 0 {
+				fmt.Println()
+			}
+			output.PrintTorrentDetails(t)
+		}
+
+	case "start":
+		count, err := svc.StartTorrents(ctx, filters...)
+		if err != nil {
+			return err
+		}
+		output.PrintSuccess(fmt.Sprintf("▶️  Started %d torrents", count))
+
+	case "stop":
+		count, err := svc.StopTorrents(ctx, filters...)
+		if err != nil {
+			return err
+		}
+		output.PrintSuccess(fmt.Sprintf("⏹️  Stopped %d torrents", count))
+
+	case "verify":
+		count, err := svc.VerifyTorrents(ctx, filters...)
+		if err != nil {
+			return err
+		}
+		output.PrintSuccess(fmt.Sprintf("🔍 Verification started for %d torrents", count))
+
+	case "session-info", "session-stats":
+		status, err := svc.GetDetailedStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting status: %w", err)
+		}
+		if action == "session-stats" {
+			output.PrintSessionStats(status.CurrentSessionStats, status.CumulativeStats)
+			return nil
+		}
+		output.PrintStatusSummary(status)
+		fmt.Printf("Directory: %s • Port: %d\n", output.PathStyle.Render(status.DownloadDir), status.PeerPort)
+	}
+
+	return nil
+}